@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with the 23kectl config file",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the config file",
+	Long: `This command will check the config file against the schema of the install package
+which is used by the configured 23KE version.
+
+All violations are printed with the YAML path of the offending value.
+The install package is looked up in the 23KE bucket, unless it's given by --install-pkg-version.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		installPkgVersion, err := cmd.Flags().GetString("install-pkg-version")
+		if err != nil {
+			return err
+		}
+
+		if installPkgVersion == "" {
+			// everything needed to find the install package has to be in the config file already
+			common.SetNonInteractive(true)
+			installPkgVersion, err = install.InstallPkgVersion()
+			if err != nil {
+				return err
+			}
+		}
+
+		violations, err := install.ValidateConfig(installPkgVersion)
+		if err != nil {
+			return err
+		}
+
+		for _, violation := range violations {
+			common.PrintErr(violation.Error())
+		}
+
+		if len(violations) > 0 {
+			return fmt.Errorf("%s has %d violation(s) of the %s schema", viper.ConfigFileUsed(), len(violations), installPkgVersion)
+		}
+
+		fmt.Printf("%s is valid for install package %s\n", viper.ConfigFileUsed(), installPkgVersion)
+		return nil
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the config file to the schema of another install package",
+	Long: `This command will convert the config file from the schema of one install package
+to the one of a newer install package, e.g. from v3 to v4.
+
+Added and dropped keys are reported. The original config file is kept as a backup next to it.
+The install package the config file was written for is looked up in the 23KE bucket,
+unless it's given by --from.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+
+		if from == "" {
+			common.SetNonInteractive(true)
+			from, err = install.InstallPkgVersion()
+			if err != nil {
+				return err
+			}
+		}
+
+		report, err := install.MigrateConfig(from, to)
+		if err != nil {
+			return err
+		}
+
+		report.Print()
+		common.PrintWarn("Make sure 'version' points to a 23KE version using install package " + to + " before installing.")
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+
+	configValidateCmd.Flags().String("install-pkg-version", "", "The install package to validate against, e.g. v4")
+
+	configMigrateCmd.Flags().String("from", "", "The install package the config file was written for, e.g. v3")
+	configMigrateCmd.Flags().String("to", "", "The install package to migrate to, e.g. v4")
+	_ = configMigrateCmd.MarkFlagRequired("to")
+}
@@ -5,12 +5,34 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
 	"github.com/23technologies/23kectl/pkg/check"
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install"
+	"github.com/23technologies/23kectl/pkg/runner"
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/fluxcd/helm-controller/api/v2beta1"
 	"github.com/fluxcd/kustomize-controller/api/v1beta2"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
 	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// exit codes of the doctor command
+const (
+	doctorExitReady       = 0
+	doctorExitProgressing = 2
+	doctorExitError       = 3
 )
 
 // installCmd represents the install command
@@ -21,50 +43,283 @@ var doctorCmd = &cobra.Command{
 
 If e.g. a HelmRelease failed, the error message message including a hint
 will be printed.
+
+With --watch, a table of all flux sources, HelmCharts, HelmReleases and
+Kustomizations is redrawn whenever one of them changes. The command exits
+as soon as everything is Ready or fails once --timeout is reached.
+
+With --fix, remediations for known failures are offered one by one and
+executed once confirmed, e.g. resetting the retries of a HelmRelease.
+
+The exit code is 0 if all resources are ready, 2 if some of them are still
+progressing and 3 if at least one of them failed definitively.
 `,
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+		kubecontext, _ := cmd.Flags().GetString("context")
+
+		kubeconfigArgs := genericclioptions.NewConfigFlags(false)
+		kubeconfigArgs.KubeConfig = &kubeconfig
+		kubeconfigArgs.Context = &kubecontext
+
+		env, err := check.NewEnv(context.Background(), kubeconfigArgs)
+		if err != nil {
+			return err
+		}
+		env.BlockUntilKeyCanRead = install.BlockUntilKeyCanRead
+
+		watchFlag, _ := cmd.Flags().GetBool("watch")
+		if watchFlag {
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			return doctorWatch(env, timeout)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output != "" && output != "json" && output != "yaml" {
+			return fmt.Errorf("unknown output format %q, use json or yaml", output)
+		}
+
+		fixFlag, _ := cmd.Flags().GetBool("fix")
+		if fixFlag && output != "" {
+			return fmt.Errorf("--fix can't be combined with --output")
+		}
+
+		r, err := doctor(env)
+		if err != nil {
+			return err
+		}
+
+		results := r.RunAllOnce()
 
-		doctor()
-		return nil
+		err = printResults(os.Stdout, results, output)
+		if err != nil {
+			return err
+		}
+
+		if fixFlag && offerFixes(env, r.Checks(), results) {
+			fmt.Println("\nRun '23kectl doctor --watch' to follow the reconciliation.")
+		}
+
+		return exitCode(doctorExitCode(results))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(doctorCmd)
 	doctorCmd.PersistentFlags().String("kubeconfig", "", "The KUBECONFIG of your base cluster")
+	doctorCmd.PersistentFlags().String("context", "", "The kubeconfig context to use")
+	doctorCmd.Flags().BoolP("watch", "w", false, "Watch the flux resources until all of them are ready")
+	doctorCmd.Flags().Bool("fix", false, "Offer remediations for known failures")
+	doctorCmd.Flags().StringP("output", "o", "", "Output format, one of json, yaml")
+	doctorCmd.Flags().Duration("timeout", 30*time.Minute, "How long to watch before giving up")
 }
 
-func doctor() {
-	var checks []check.Check
+func doctor(env *check.Env) (*runner.Runner, error) {
+	r := runner.New(env)
 
-	hrList := &v2beta1.HelmReleaseList{}
-	_ = check.KubeClient.List(context.TODO(), hrList, &client.ListOptions{Namespace: "flux-system"})
+	// the runner orders the checks by dependencies, sources will come first
+	lists := []struct {
+		list     client.ObjectList
+		newCheck func(name string, namespace string) check.Check
+	}{
+		{&sourcev1.GitRepositoryList{}, func(name, namespace string) check.Check {
+			return &check.GitRepositoryCheck{Name: name, Namespace: namespace}
+		}},
+		{&sourcev1.BucketList{}, func(name, namespace string) check.Check {
+			return &check.BucketCheck{Name: name, Namespace: namespace}
+		}},
+		{&sourcev1.HelmRepositoryList{}, func(name, namespace string) check.Check {
+			return &check.HelmRepositoryCheck{Name: name, Namespace: namespace}
+		}},
+		{&sourcev1.HelmChartList{}, func(name, namespace string) check.Check {
+			return &check.HelmChartsCheck{Name: name, Namespace: namespace}
+		}},
+		{&v1beta2.KustomizationList{}, func(name, namespace string) check.Check {
+			return &check.KustomizationCheck{Name: name, Namespace: namespace}
+		}},
+		{&v2beta1.HelmReleaseList{}, func(name, namespace string) check.Check {
+			return &check.HelmReleaseCheck{Name: name, Namespace: namespace}
+		}},
+	}
+
+	for _, l := range lists {
+		err := env.Client.List(env.Context, l.list, &client.ListOptions{Namespace: "flux-system"})
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := apimeta.ExtractList(l.list)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, hr := range hrList.Items {
-		checks = append(checks, &check.HelmReleaseCheck{Name: hr.Name, Namespace: hr.Namespace})
+		for _, item := range items {
+			obj := item.(client.Object)
+			r.AddCheck(l.newCheck(obj.GetName(), obj.GetNamespace()))
+		}
 	}
 
-	ksList := &v1beta2.KustomizationList{}
-	_ = check.KubeClient.List(context.TODO(), ksList, &client.ListOptions{Namespace: "flux-system"})
+	return r, nil
+}
+
+// offerFixes asks for every fix of every failed check whether it should be applied.
+// It returns true if at least one of them was applied.
+func offerFixes(env *check.Env, checks []check.Check, results []*check.Result) bool {
+	applied := false
+
+	for i, c := range checks {
+		// fixing the root cause is enough
+		withFix, ok := c.(check.WithFix)
+		if !ok || results[i].IsOkay || results[i].RootCause != "" {
+			continue
+		}
+
+		for _, fix := range withFix.Fixes(results[i]) {
+			isConfirmed := false
+			prompt := &survey.Confirm{
+				Message: fix.Description + "?",
+			}
+			err := common.AskOne(prompt, &isConfirmed, "")
+			common.ExitOnCtrlC(err)
+			if !isConfirmed {
+				continue
+			}
 
-	for _, ks := range ksList.Items {
-		checks = append(checks, &check.KustomizationCheck{Name: ks.Name, Namespace: ks.Namespace})
+			err = fix.Apply(env)
+			if err != nil {
+				common.PrintErr(fmt.Sprintf("%s failed: %s", fix.Description, err))
+				continue
+			}
+
+			applied = true
+		}
 	}
 
-	fmt.Print("\033[H\033[2J")
+	if !applied {
+		fmt.Println("\nNo fixes were applied.")
+	}
 
-	for _, c := range checks {
-		result := c.Run()
+	return applied
+}
 
-		emoji := "⌛"
+func printResults(w io.Writer, results []*check.Result, output string) error {
+	switch output {
+	case "json":
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(out))
+	case "yaml":
+		out, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, string(out))
+	default:
+		fmt.Fprint(w, "\033[H\033[2J")
 
+		for _, result := range results {
+			emoji := "⌛"
+
+			if result.IsError {
+				emoji = "❌"
+			} else if result.IsOkay {
+				emoji = "✔️"
+			}
+
+			fmt.Fprintf(w, "%s %s/%s status: %s\n", emoji, result.Kind, result.Name, result.Status)
+			if result.Revision != "" {
+				revision := result.Revision
+				if result.LastUpdateTime != nil {
+					revision += fmt.Sprintf(" (%s ago)", time.Since(result.LastUpdateTime.Time).Truncate(time.Second))
+				}
+				fmt.Fprintf(w, "   revision: %s\n", revision)
+			}
+			if result.RootCause != "" {
+				fmt.Fprintf(w, "   caused by: %s\n", result.RootCause)
+			} else if result.Hint != "" {
+				fmt.Fprintf(w, "   hint: %s\n", result.Hint)
+			}
+		}
+	}
+
+	return nil
+}
+
+func doctorExitCode(results []*check.Result) int {
+	// nothing has been reconciled yet
+	if len(results) == 0 {
+		return doctorExitProgressing
+	}
+
+	code := doctorExitReady
+
+	for _, result := range results {
 		if result.IsError {
-			emoji = "❌"
-		} else if result.IsOkay {
-			emoji = "✔️"
+			return doctorExitError
+		} else if !result.IsOkay {
+			code = doctorExitProgressing
+		}
+	}
+
+	return code
+}
+
+func doctorWatch(env *check.Env, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(env.Context, timeout)
+	defer cancel()
+
+	watchEnv := *env
+	watchEnv.Context = ctx
+	updates := check.Watch(&watchEnv, "flux-system")
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var statuses []check.ObjectStatus
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not all flux resources became ready within %s", timeout)
+		case <-ticker.C:
+			// keep the elapsed times ticking
+		case update, ok := <-updates:
+			if !ok {
+				return fmt.Errorf("not all flux resources became ready within %s", timeout)
+			}
+			statuses = update
+		}
+
+		printStatusTable(statuses)
+
+		if check.AllReady(statuses) {
+			fmt.Println("\nAll flux resources are ready.")
+			return nil
+		}
+	}
+}
+
+func printStatusTable(statuses []check.ObjectStatus) {
+	fmt.Print("\033[H\033[2J")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tREADY\tSINCE\tMESSAGE")
+
+	for _, status := range statuses {
+		since := "-"
+		if !status.LastTransitionTime.IsZero() {
+			since = time.Since(status.LastTransitionTime).Truncate(time.Second).String()
+		}
+
+		// keep the table intact, long messages are cut
+		message := strings.ReplaceAll(status.Message, "\n", " ")
+		if runes := []rune(message); len(runes) > 80 {
+			message = string(runes[:77]) + "..."
 		}
 
-		fmt.Printf("%s %s status: %s\n", emoji, c.GetName(), result.Status)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", status.Kind, status.Name, status.Ready, since, message)
 	}
 
+	w.Flush()
 }
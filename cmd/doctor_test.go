@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/23technologies/23kectl/pkg/check"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("doctor", func() {
+	ready := &check.Result{Kind: "Kustomization", Name: "23ke-base", IsOkay: true, Status: "Applied revision"}
+	progressing := &check.Result{Kind: "HelmRelease", Name: "gardener", Status: "Reconciliation in progress"}
+	failed := &check.Result{Kind: "HelmRelease", Name: "dashboard", IsError: true, Status: "install retries exhausted", Hint: "reset the retries"}
+
+	DescribeTable("maps the results to an exit code",
+		func(results []*check.Result, code int) {
+			Expect(doctorExitCode(results)).To(Equal(code))
+		},
+		Entry("all ready", []*check.Result{ready}, doctorExitReady),
+		Entry("some progressing", []*check.Result{ready, progressing}, doctorExitProgressing),
+		Entry("one failed", []*check.Result{progressing, failed, ready}, doctorExitError),
+		Entry("nothing reconciled yet", []*check.Result{}, doctorExitProgressing),
+	)
+
+	It("returns an error only for other exit codes than 0", func() {
+		Expect(exitCode(doctorExitReady)).To(Succeed())
+		Expect(exitCode(doctorExitError)).To(MatchError(&exitCodeError{code: doctorExitError}))
+	})
+
+	It("prints the results as json", func() {
+		var out bytes.Buffer
+		Expect(printResults(&out, []*check.Result{ready, failed}, "json")).To(Succeed())
+
+		var printed []check.Result
+		Expect(json.Unmarshal(out.Bytes(), &printed)).To(Succeed())
+		Expect(printed).To(HaveLen(2))
+		Expect(printed[1].Name).To(Equal("dashboard"))
+		Expect(printed[1].IsError).To(BeTrue())
+	})
+
+	It("prints a line per result with its hint", func() {
+		var out bytes.Buffer
+		Expect(printResults(&out, []*check.Result{ready, failed}, "")).To(Succeed())
+
+		Expect(out.String()).To(ContainSubstring("✔️ Kustomization/23ke-base status: Applied revision\n"))
+		Expect(out.String()).To(ContainSubstring("❌ HelmRelease/dashboard status: install retries exhausted\n   hint: reset the retries\n"))
+	})
+})
@@ -33,7 +33,7 @@ Other than that you need:
 -  A Kubernetes cluster (also called base cluster) running in the cloud
 -  A DNS provider e.g. azure-dns, aws-route53, openstack-designate
 -  A domain delegated to the DNS provider of choice
--  A remote git repository which is accessible (read and write) via ssh
+-  A remote git repository which is accessible (read and write) via ssh or https
 -  Knowledge about Flux, Helm and Kustomize
 for the installation.
 
@@ -41,28 +41,77 @@ Dependent on your relationship with 23T you will be charged for using 23KE.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// todo check required flags
-		viper.SetEnvPrefix("23KECTL_")
-		err := viper.ReadInConfig()
+		isNonInteractive, err := cmd.Flags().GetBool("non-interactive")
+		if err != nil {
+			return err
+		}
+		common.SetNonInteractive(isNonInteractive)
+
+		err = common.ReadConfig()
 		if err != nil && !errors.Is(err, fs.ErrNotExist) {
 			fmt.Print(err)
 			return err
 		}
 
+		knownHostsFile, err := cmd.Flags().GetString("known-hosts")
+		if err != nil {
+			return err
+		}
+		if knownHostsFile != "" {
+			knownHosts, err := os.ReadFile(knownHostsFile)
+			if err != nil {
+				return err
+			}
+			viper.Set("admin.knownHosts", string(knownHosts))
+		}
+
+		isSopsEnabled, err := cmd.Flags().GetBool("sops")
+		if err != nil {
+			return err
+		}
+		if isSopsEnabled {
+			viper.Set("sops.enabled", true)
+		}
+
 		kubeConfig, err := cmd.Flags().GetString("kubeconfig")
 		if err != nil {
 			return err
 		}
 
+		isPreflightOnly, err := cmd.Flags().GetBool("preflight-only")
+		if err != nil {
+			return err
+		}
+		if isPreflightOnly {
+			code, err := runPreflight(kubeConfig, "", "")
+			if err != nil {
+				return err
+			}
+			return exitCode(code)
+		}
+
 		isDryRun, err := cmd.Flags().GetBool("dry-run")
 		if err != nil {
 			return err
 		}
 
+		keyWaitTimeout, err := cmd.Flags().GetDuration("wait-for-key")
+		if err != nil {
+			return err
+		}
+		common.SetKeyWaitTimeout(keyWaitTimeout)
+
+		publicKeyFile, err := cmd.Flags().GetString("public-key-file")
+		if err != nil {
+			return err
+		}
+		common.SetPublicKeyFile(publicKeyFile)
+
 		err = install.Install(kubeConfig, isDryRun)
 
 		if err != nil {
 			logger.Get().Error(err, "An unexpected error occurred.")
-			os.Exit(1)
+			return exitCode(1)
 		}
 
 		return nil
@@ -91,4 +140,10 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	installCmd.Flags().Bool("dry-run", false, "Don't apply anything, just output")
+	installCmd.Flags().Bool("non-interactive", false, "Never prompt. Fail with a list of all missing config keys instead")
+	installCmd.Flags().Duration("wait-for-key", 0, "Poll until the deploy key can read the config repo instead of asking for confirmation, for at most this long (default 10m with --non-interactive)")
+	installCmd.Flags().String("known-hosts", "", "A known_hosts file pinning the host keys of the config repo's ssh remote, stored as admin.knownHosts")
+	installCmd.Flags().Bool("sops", false, "Encrypt the secrets in the config repo with SOPS and a generated age key, stored as sops.enabled")
+	installCmd.Flags().Bool("preflight-only", false, "Only check whether the base cluster is able to run 23KE, see 23kectl preflight")
+	installCmd.Flags().String("public-key-file", "", "Write the public deploy key in authorized_keys format to this file, - for stdout on a line prefixed with 23kectl-deploy-key:")
 }
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install"
+	"github.com/23technologies/23kectl/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the deploy key of the config repository",
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Replace the deploy key of the config repository",
+	Long: `This command will replace the deploy key of the config repository without an outage.
+
+A new ed25519 key is generated and registered, either via the API of your git
+provider or by hand. Once it can read the config repository, it is swapped into
+the '23ke-config-key' secret. The old key is only removed after flux fetched the
+config repository with the new key. If flux can't, the old key is restored.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		kubeConfig, err := cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+
+		keyWaitTimeout, err := cmd.Flags().GetDuration("wait-for-key")
+		if err != nil {
+			return err
+		}
+		common.SetKeyWaitTimeout(keyWaitTimeout)
+
+		publicKeyFile, err := cmd.Flags().GetString("public-key-file")
+		if err != nil {
+			return err
+		}
+		common.SetPublicKeyFile(publicKeyFile)
+
+		err = install.RotateDeployKey(kubeConfig, timeout)
+		if err != nil {
+			logger.Get().Error(err, "Rotating the deploy key failed.")
+			return exitCode(1)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysRotateCmd)
+
+	keysCmd.PersistentFlags().String("kubeconfig", "", "The KUBECONFIG of your base cluster")
+	keysRotateCmd.Flags().Duration("wait-for-key", 0, "Poll until the new key can read the config repo instead of asking for confirmation, for at most this long")
+	keysRotateCmd.Flags().String("public-key-file", "", "Write the new public key in authorized_keys format to this file, - for stdout on a line prefixed with 23kectl-deploy-key:")
+	keysRotateCmd.Flags().Duration("timeout", 5*time.Minute, "How long to wait for flux to fetch the config repository with the new key")
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"io/fs"
+	"os"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/preflight"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// exit codes of the preflight command
+const (
+	preflightExitPass = 0
+	preflightExitWarn = 2
+	preflightExitFail = 3
+)
+
+var preflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check whether a base cluster is able to run 23KE",
+	Long: `This command checks the base cluster before anything is installed:
+
+- the Kubernetes version is supported
+- there is a default StorageClass
+- the nodes provide enough CPU and memory
+- flux isn't installed already by someone else
+- the seed CIDRs in the config file don't overlap with each other or
+  with the default CIDRs of shoots
+
+The same checks run during '23kectl install', which stops if one of them
+fails. The exit code is 0 if all checks pass, 2 if there are warnings and 3
+if at least one of them failed.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.ReadConfig()
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+		kubecontext, _ := cmd.Flags().GetString("context")
+		output, _ := cmd.Flags().GetString("output")
+
+		code, err := runPreflight(kubeconfig, kubecontext, output)
+		if err != nil {
+			return err
+		}
+
+		return exitCode(code)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(preflightCmd)
+	preflightCmd.PersistentFlags().String("kubeconfig", "", "The KUBECONFIG of your base cluster")
+	preflightCmd.PersistentFlags().String("context", "", "The kubeconfig context to use")
+	preflightCmd.Flags().StringP("output", "o", "", "Output format, one of json, yaml")
+}
+
+// runPreflight runs all preflight checks against the base cluster and prints the
+// report. It returns the exit code for the report's status.
+func runPreflight(kubeconfig string, kubecontext string, output string) (int, error) {
+	kubeconfigArgs := genericclioptions.NewConfigFlags(false)
+	kubeconfigArgs.KubeConfig = &kubeconfig
+	kubeconfigArgs.Context = &kubecontext
+
+	report, err := preflight.Execute(context.Background(), kubeconfigArgs, preflight.Config{
+		SeedNodeCidr:    viper.GetString("gardenlet.seedNodeCidr"),
+		SeedPodCidr:     viper.GetString("gardenlet.seedPodCidr"),
+		SeedServiceCidr: viper.GetString("gardenlet.seedServiceCidr"),
+	}, preflight.Checks(), os.Stdout, output)
+	if err != nil {
+		return 0, err
+	}
+
+	switch report.Status {
+	case preflight.StatusFail:
+		return preflightExitFail, nil
+	case preflight.StatusWarn:
+		return preflightExitWarn, nil
+	default:
+		return preflightExitPass, nil
+	}
+}
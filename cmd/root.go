@@ -4,14 +4,20 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/credstore"
+	"github.com/23technologies/23kectl/pkg/redact"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var credentialStore string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -28,13 +34,44 @@ to quickly create a Cobra application.`,
 	// Run: func(cmd *cobra.Command, args []string) { },
 }
 
+// exitCodeError makes a command exit with the given code without printing an error,
+// e.g. when the command's output already explains the failure.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
+// exitCode returns nil for 0 and an *exitCodeError otherwise.
+func exitCode(code int) error {
+	if code == 0 {
+		return nil
+	}
+
+	return &exitCodeError{code: code}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() {
+// It returns the code the process should exit with.
+func Execute() int {
+	rootCmd.SetErr(redact.Writer(os.Stderr))
+	rootCmd.SilenceErrors = true
+
 	err := rootCmd.Execute()
+
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
 	if err != nil {
-		os.Exit(1)
+		rootCmd.PrintErrln("Error:", err.Error())
+		return 1
 	}
+
+	return 0
 }
 
 func init() {
@@ -45,6 +82,7 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "config.yaml", "config file")
+	rootCmd.PersistentFlags().StringVar(&credentialStore, "credential-store", credstore.KindAuto, "Where to keep sensitive config keys: auto, keyring, file or none (plaintext in the config file)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -67,8 +105,13 @@ func initConfig() {
 		viper.SetConfigName(".23kectl")
 	}
 
+	// e.g. 23KECTL_ADMIN_EMAIL overrides admin.email
+	viper.SetEnvPrefix(common.ENV_PREFIX)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv() // read in environment variables that match
 
+	common.SetCredentialStore(credentialStore)
+
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install"
+	"github.com/23technologies/23kectl/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the encrypted secrets in the config repository",
+}
+
+var secretsEditCmd = &cobra.Command{
+	Use:   "edit [file]",
+	Short: "Edit an encrypted secret in the config repository",
+	Long: `This command will decrypt a secret in the config repository, open it in $EDITOR and
+push the re-encrypted result. Without a file argument you are asked which one to edit.
+
+Secrets are only encrypted if sops.enabled is set. They are decrypted with the age
+key in the 'sops-age' secret of your base cluster.
+
+Note that secrets rendered from your 23kectl config, e.g. 'config/identity-values.yaml',
+are overwritten whenever 23kectl renders the config repository, e.g. on install or
+upgrade. Change the config instead.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		isNonInteractive, err := cmd.Flags().GetBool("non-interactive")
+		if err != nil {
+			return err
+		}
+		common.SetNonInteractive(isNonInteractive)
+
+		err = common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		kubeConfig, err := cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		var file string
+		if len(args) > 0 {
+			file = args[0]
+		}
+
+		err = install.EditSecret(kubeConfig, file)
+		if err != nil {
+			logger.Get().Error(err, "Editing the secret failed.")
+			return exitCode(1)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsEditCmd)
+
+	secretsCmd.PersistentFlags().String("kubeconfig", "", "The KUBECONFIG of your base cluster")
+	secretsEditCmd.Flags().Bool("non-interactive", false, "Never prompt. Fail if no file is given instead")
+}
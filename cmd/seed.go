@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install"
+	"github.com/23technologies/23kectl/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Manage additional seed clusters",
+}
+
+var seedAddCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Register an additional seed cluster",
+	Long: `This command will register the cluster of --kubeconfig as an additional seed.
+
+Its provider, region, networks and vertical pod autoscaler are detected like the ones
+of the base cluster and you are asked to confirm them. The seed is added to your
+23kectl config and rendered into the config repository, where flux picks up its
+gardenlet values and deploys the gardenlet into the seed cluster.
+
+The kubeconfig of the seed cluster is stored in the secret 'seed-<name>-kubeconfig'
+of your base cluster and never committed. It mustn't authenticate with a plugin,
+e.g. a cloud provider's CLI, as flux can't run it.
+
+The gardenlet registers the seed with a bootstrap token, which is created in the
+garden and stored in the secret 'seed-<name>-garden-connection'. It connects to
+the garden's API server at --garden-server, which has to be reachable from the
+seed cluster. The token expires after 24 hours.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		isNonInteractive, err := cmd.Flags().GetBool("non-interactive")
+		if err != nil {
+			return err
+		}
+		common.SetNonInteractive(isNonInteractive)
+
+		err = common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		baseKubeconfig, err := cmd.Flags().GetString("base-kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		seedKubeconfig, err := cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		seedContext, err := cmd.Flags().GetString("context")
+		if err != nil {
+			return err
+		}
+
+		gardenServer, err := cmd.Flags().GetString("garden-server")
+		if err != nil {
+			return err
+		}
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		err = install.AddSeed(baseKubeconfig, seedKubeconfig, seedContext, name, gardenServer)
+		if err != nil {
+			logger.Get().Error(err, "Adding the seed failed.")
+			return exitCode(1)
+		}
+
+		return nil
+	},
+}
+
+var seedListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the seed clusters of the config",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		return install.ListSeeds(os.Stdout)
+	},
+}
+
+var seedRemoveCmd = &cobra.Command{
+	Use:   "remove name",
+	Short: "Remove an additional seed cluster",
+	Long: `This command will remove an additional seed from your 23kectl config and the config
+repository. Flux then uninstalls its gardenlet. Afterwards, the kubeconfig and the
+garden connection of the seed cluster are deleted from your base cluster.
+
+Move or delete the shoots of the seed before. The Seed object stays in the garden,
+delete it as printed at the end.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		baseKubeconfig, err := cmd.Flags().GetString("base-kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+
+		err = install.RemoveSeed(baseKubeconfig, args[0], timeout)
+		if err != nil {
+			logger.Get().Error(err, "Removing the seed failed.")
+			return exitCode(1)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+	seedCmd.AddCommand(seedAddCmd)
+	seedCmd.AddCommand(seedListCmd)
+	seedCmd.AddCommand(seedRemoveCmd)
+
+	seedCmd.PersistentFlags().String("base-kubeconfig", "", "The KUBECONFIG of your base cluster")
+	seedAddCmd.Flags().String("kubeconfig", "", "The KUBECONFIG of the seed cluster")
+	seedAddCmd.Flags().String("context", "", "The context of the seed cluster in its KUBECONFIG")
+	seedAddCmd.Flags().String("garden-server", "", "The address of the garden's API server, reachable from the seed cluster")
+	seedAddCmd.Flags().Bool("non-interactive", false, "Never prompt. Use the detected values and fail if one couldn't be detected")
+	_ = seedAddCmd.MarkFlagRequired("kubeconfig")
+	seedRemoveCmd.Flags().Duration("timeout", 10*time.Minute, "How long to wait for flux to uninstall the gardenlet")
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install"
+	"github.com/23technologies/23kectl/pkg/logger"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove a 23KE installation from the base cluster",
+	Long: `This command will tear down 23KE in reverse order of the installation.
+
+The 23KE sources are suspended first. Afterwards, the 23KE kustomizations are deleted
+one after another, waiting for flux to prune everything they deployed.
+Lastly, the sources, the secrets and flux itself are removed.
+
+As long as there are shoots in your gardener, the uninstallation is refused.
+Their infrastructure would be left behind otherwise.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kubeConfig, err := cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		opts := install.UninstallOptions{}
+
+		opts.KeepFlux, err = cmd.Flags().GetBool("keep-flux")
+		if err != nil {
+			return err
+		}
+
+		opts.KeepDeployKey, err = cmd.Flags().GetBool("keep-deploy-key")
+		if err != nil {
+			return err
+		}
+
+		opts.Force, err = cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+
+		opts.Timeout, err = cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+
+		isConfirmed, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+
+		if !isConfirmed {
+			prompt := &survey.Confirm{
+				Message: "This will remove 23KE including gardener from your base cluster. Continue?",
+			}
+			err = common.AskOne(prompt, &isConfirmed, "")
+			common.ExitOnCtrlC(err)
+			if !isConfirmed {
+				fmt.Println("Nothing was changed.")
+				return nil
+			}
+		}
+
+		err = install.Uninstall(kubeConfig, opts)
+		if err != nil {
+			logger.Get().Error(err, "Uninstall failed.")
+			return exitCode(1)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+
+	uninstallCmd.PersistentFlags().String("kubeconfig", "", "The KUBECONFIG of your base cluster")
+	uninstallCmd.Flags().Bool("keep-flux", false, "Don't uninstall flux")
+	uninstallCmd.Flags().Bool("keep-deploy-key", false, "Keep the deploy key of the config repo, so it doesn't have to be registered again")
+	uninstallCmd.Flags().Bool("force", false, "Uninstall even if there are shoots left")
+	uninstallCmd.Flags().Duration("timeout", 10*time.Minute, "How long to wait for each kustomization to be pruned")
+	uninstallCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation")
+}
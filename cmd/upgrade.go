@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install"
+	"github.com/23technologies/23kectl/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade an existing 23KE installation to a newer version",
+	Long: `This command will move an existing 23KE installation to the version given by --to.
+
+The config file is migrated to the schema of the new version, the config repository
+is updated and the '23ke' bucket source is switched to the new version.
+Afterwards, the command waits for the base kustomization to reconcile.
+
+Upgrades can only move one install package at a time, e.g. from v3 to v4.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.ReadConfig()
+		if err != nil {
+			return err
+		}
+
+		kubeConfig, err := cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			return err
+		}
+
+		toVersion, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+
+		err = install.Upgrade(kubeConfig, toVersion, timeout)
+		if err != nil {
+			logger.Get().Error(err, "Upgrade failed.")
+			return exitCode(1)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.PersistentFlags().String("kubeconfig", "", "The KUBECONFIG of your base cluster")
+	upgradeCmd.Flags().String("to", "", "The 23KE version to upgrade to")
+	upgradeCmd.Flags().Duration("timeout", 15*time.Minute, "How long to wait for the base kustomization to reconcile")
+	_ = upgradeCmd.MarkFlagRequired("to")
+}
@@ -4,13 +4,16 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package main
 
 import (
+	"os"
+
 	"github.com/23technologies/23kectl/cmd"
 	"github.com/23technologies/23kectl/pkg/logger"
 )
 
 func main() {
 	disposeLogger := logger.Init()
-	defer disposeLogger()
+	code := cmd.Execute()
+	disposeLogger()
 
-	cmd.Execute()
+	os.Exit(code)
 }
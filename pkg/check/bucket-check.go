@@ -0,0 +1,48 @@
+package check
+
+import (
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type BucketCheck struct {
+	Name      string
+	Namespace string
+}
+
+func (d *BucketCheck) GetName() string {
+	return d.Name
+}
+
+func (d *BucketCheck) Hint(result *Result) string {
+	return hintFor(result)
+}
+
+func (d *BucketCheck) Fixes(result *Result) []Fix {
+	return fixesFor(result)
+}
+
+func (d *BucketCheck) Run(env *Env) *Result {
+	result := &Result{
+		Name:      d.Name,
+		Kind:      sourcev1.BucketKind,
+		Namespace: d.Namespace,
+	}
+
+	bucket := &sourcev1.Bucket{}
+
+	err := env.Client.Get(env.Context, client.ObjectKey{
+		Namespace: d.Namespace,
+		Name:      d.Name,
+	}, bucket)
+
+	if err != nil {
+		result.IsError = true
+		result.Status = err.Error()
+		return result
+	}
+
+	setSourceStatus(result, bucket.Status.Conditions, bucket.Status.Artifact, bucket.Status.ObservedGeneration)
+
+	return result
+}
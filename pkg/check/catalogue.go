@@ -0,0 +1,145 @@
+package check
+
+import (
+	"regexp"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+)
+
+// failureMode is a known way for a 23KE installation to fail.
+// It matches a result either by its condition reason or its status message.
+type failureMode struct {
+	kind    string
+	name    string
+	reasons []string
+	regex   *regexp.Regexp
+	hint    string
+	fixes   func(result *Result) []Fix
+}
+
+func (f *failureMode) matches(result *Result) bool {
+	if f.kind != result.Kind || (f.name != "" && f.name != result.Name) {
+		return false
+	}
+
+	for _, reason := range f.reasons {
+		if reason == result.Reason {
+			return true
+		}
+	}
+
+	return f.regex != nil && f.regex.MatchString(result.Status)
+}
+
+// a lost deploy key shows in the config repository's GitRepository and in the
+// Kustomization applying it
+const deployKeyHint = "The config repository couldn't be fetched. " +
+	"Most likely the deploy key has been removed from the repository or lost its access rights."
+
+func deployKeyFixes(_ *Result) []Fix {
+	return []Fix{deployKeyFix()}
+}
+
+// the order is important, the first matching failure mode wins
+var failureModes = []failureMode{
+	{
+		kind:    sourcev1.GitRepositoryKind,
+		name:    common.CONFIG_23KE_GITREPO_NAME,
+		reasons: []string{sourcev1.AuthenticationFailedReason, sourcev1.GitOperationFailedReason},
+		hint:    deployKeyHint,
+		fixes:   deployKeyFixes,
+	},
+	{
+		kind:    sourcev1.BucketKind,
+		name:    common.BUCKET_NAME,
+		reasons: []string{sourcev1.AuthenticationFailedReason, sourcev1.BucketOperationFailedReason},
+		hint: "The 23KE bucket couldn't be accessed. " +
+			"Check whether your license is still valid, the bucket credentials are part of it.",
+		fixes: func(result *Result) []Fix {
+			return []Fix{reconcileFix(result.Kind, result.Namespace, result.Name)}
+		},
+	},
+	{
+		kind:    sourcev1.HelmRepositoryKind,
+		reasons: []string{sourcev1.IndexationFailedReason, sourcev1.AuthenticationFailedReason, sourcev1.URLInvalidReason},
+		hint:    "The index of the Helm repository couldn't be fetched. Check the URL and whether the repository is reachable from the cluster.",
+		fixes: func(result *Result) []Fix {
+			return []Fix{reconcileFix(result.Kind, result.Namespace, result.Name)}
+		},
+	},
+	{
+		kind:  helmv2.HelmReleaseKind,
+		regex: regexp.MustCompile("retries exhausted"),
+		hint: "Helm gave up after too many failed attempts and won't try again by itself. " +
+			"Once the cause is fixed, suspend and resume the HelmRelease to reset its retries.",
+		fixes: func(result *Result) []Fix {
+			return []Fix{suspendResumeFix(result.Namespace, result.Name)}
+		},
+	},
+	{
+		kind:  helmv2.HelmReleaseKind,
+		regex: regexp.MustCompile("HelmChart '.*' is not ready"),
+		hint: "The chart couldn't be fetched or packaged. " +
+			"Check the HelmRepository or bucket the chart comes from, e.g. whether your license is still valid.",
+		fixes: func(result *Result) []Fix {
+			return []Fix{helmChartFix(result.Namespace, result.Name)}
+		},
+	},
+	{
+		kind:    kustomizev1.KustomizationKind,
+		name:    common.CONFIG_KS_NAME,
+		reasons: []string{kustomizev1.ArtifactFailedReason},
+		hint:    deployKeyHint,
+		fixes:   deployKeyFixes,
+	},
+	{
+		kind:    kustomizev1.KustomizationKind,
+		reasons: []string{kustomizev1.HealthCheckFailedReason},
+		hint: "The resources were applied, but didn't become healthy in time. " +
+			"Check the workloads in the cluster, e.g. with 'kubectl get pods -A'.",
+		fixes: func(result *Result) []Fix {
+			return []Fix{reconcileFix(result.Kind, result.Namespace, result.Name)}
+		},
+	},
+	{
+		kind:    kustomizev1.KustomizationKind,
+		reasons: []string{kustomizev1.BuildFailedReason},
+		hint:    "The manifests couldn't be built. Check the latest changes to your config repository.",
+		fixes: func(result *Result) []Fix {
+			return []Fix{reconcileFix(result.Kind, result.Namespace, result.Name)}
+		},
+	},
+}
+
+func findFailureMode(result *Result) *failureMode {
+	if result.IsOkay {
+		return nil
+	}
+
+	for i := range failureModes {
+		if failureModes[i].matches(result) {
+			return &failureModes[i]
+		}
+	}
+
+	return nil
+}
+
+func hintFor(result *Result) string {
+	if mode := findFailureMode(result); mode != nil {
+		return mode.hint
+	}
+
+	return ""
+}
+
+func fixesFor(result *Result) []Fix {
+	if mode := findFailureMode(result); mode != nil && mode.fixes != nil {
+		return mode.fixes(result)
+	}
+
+	return nil
+}
@@ -1,15 +1,17 @@
 package check
 
 type Runnable interface {
-	Run() *Result
+	Run(env *Env) *Result
 }
 
+// WithHint is implemented by checks which can explain a result that isn't okay.
 type WithHint interface {
-	Hint() string
+	Hint(result *Result) string
 }
 
+// WithFix is implemented by checks which can offer remediations for a result that isn't okay.
 type WithFix interface {
-	Fix()
+	Fixes(result *Result) []Fix
 }
 
 type WithOnError interface {
@@ -0,0 +1,194 @@
+package check_test
+
+import (
+	"github.com/23technologies/23kectl/pkg/check"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func readyCondition(status metav1.ConditionStatus, reason string, message string) []metav1.Condition {
+	return []metav1.Condition{{
+		Type:               meta.ReadyCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}}
+}
+
+func helmRelease(name string, conditions []metav1.Condition) *helmv2.HelmRelease {
+	hr := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "flux-system"},
+	}
+	hr.Status.Conditions = conditions
+	hr.Status.ObservedGeneration = 3
+
+	return hr
+}
+
+func kustomization(name string, conditions []metav1.Condition) *kustomizev1.Kustomization {
+	ks := &kustomizev1.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "flux-system"},
+	}
+	ks.Status.Conditions = conditions
+
+	return ks
+}
+
+var _ = Describe("HelmReleaseCheck", func() {
+	It("is okay once the release is reconciled", func() {
+		env := newTestEnv(helmRelease("gardener", readyCondition(metav1.ConditionTrue, "ReconciliationSucceeded", "Release reconciliation succeeded")))
+
+		result := (&check.HelmReleaseCheck{Name: "gardener", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsOkay).To(BeTrue())
+		Expect(result.IsError).To(BeFalse())
+		Expect(result.Kind).To(Equal(helmv2.HelmReleaseKind))
+		Expect(result.Reason).To(Equal("ReconciliationSucceeded"))
+		Expect(result.ObservedGeneration).To(BeEquivalentTo(3))
+	})
+
+	It("is progressing while the release is being installed", func() {
+		env := newTestEnv(helmRelease("gardener", readyCondition(metav1.ConditionUnknown, meta.ProgressingReason, "Reconciliation in progress")))
+
+		result := (&check.HelmReleaseCheck{Name: "gardener", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsOkay).To(BeFalse())
+		Expect(result.IsError).To(BeFalse())
+		Expect(result.Status).To(Equal("Reconciliation in progress"))
+	})
+
+	It("fails once the retries are exhausted", func() {
+		env := newTestEnv(helmRelease("gardener", readyCondition(metav1.ConditionFalse, "InstallFailed", "install retries exhausted")))
+
+		result := (&check.HelmReleaseCheck{Name: "gardener", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Status).To(Equal("install retries exhausted"))
+	})
+
+	It("reports the reason of a HelmChart which is not ready", func() {
+		hc := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{Name: "flux-system-gardener", Namespace: "flux-system"},
+		}
+		hc.Status.Conditions = readyCondition(metav1.ConditionFalse, "ChartPullError", "chart not found")
+
+		env := newTestEnv(
+			hc,
+			helmRelease("gardener", readyCondition(metav1.ConditionFalse, "ArtifactFailed", "HelmChart 'flux-system/flux-system-gardener' is not ready")),
+		)
+
+		result := (&check.HelmReleaseCheck{Name: "gardener", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Status).To(ContainSubstring("chart not found"))
+	})
+
+	It("fails if the release doesn't exist", func() {
+		env := newTestEnv()
+
+		result := (&check.HelmReleaseCheck{Name: "gardener", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Status).To(ContainSubstring("not found"))
+	})
+})
+
+var _ = Describe("KustomizationCheck", func() {
+	It("is okay once the revision is applied", func() {
+		env := newTestEnv(kustomization("23ke-base", readyCondition(metav1.ConditionTrue, "ReconciliationSucceeded", "Applied revision: v1.60.0/abc")))
+
+		result := (&check.KustomizationCheck{Name: "23ke-base", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsOkay).To(BeTrue())
+		Expect(result.Kind).To(Equal(kustomizev1.KustomizationKind))
+	})
+
+	It("is progressing while a dependency isn't ready", func() {
+		env := newTestEnv(kustomization("23ke-base", readyCondition(metav1.ConditionFalse, kustomizev1.DependencyNotReadyReason, "dependency 'flux-system/23ke-config' is not ready")))
+
+		result := (&check.KustomizationCheck{Name: "23ke-base", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsOkay).To(BeFalse())
+		Expect(result.IsError).To(BeFalse())
+	})
+
+	It("fails if the build failed", func() {
+		env := newTestEnv(kustomization("23ke-base", readyCondition(metav1.ConditionFalse, kustomizev1.BuildFailedReason, "kustomize build failed")))
+
+		result := (&check.KustomizationCheck{Name: "23ke-base", Namespace: "flux-system"}).Run(env)
+
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Reason).To(Equal(kustomizev1.BuildFailedReason))
+	})
+})
+
+var _ = Describe("Source checks", func() {
+	It("reports the revision and age of a ready GitRepository", func() {
+		gr := &sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "23ke-config", Namespace: "flux-system"},
+		}
+		gr.Status.Conditions = readyCondition(metav1.ConditionTrue, meta.SucceededReason, "stored artifact for revision 'main/abc'")
+		gr.Status.Artifact = &sourcev1.Artifact{Revision: "main/abc", LastUpdateTime: metav1.Now()}
+		gr.Status.ObservedGeneration = 2
+
+		result := (&check.GitRepositoryCheck{Name: "23ke-config", Namespace: "flux-system"}).Run(newTestEnv(gr))
+
+		Expect(result.IsOkay).To(BeTrue())
+		Expect(result.Revision).To(Equal("main/abc"))
+		Expect(result.LastUpdateTime).NotTo(BeNil())
+		Expect(result.ObservedGeneration).To(BeEquivalentTo(2))
+	})
+
+	It("reports the fetch error of a Bucket", func() {
+		bucket := &sourcev1.Bucket{
+			ObjectMeta: metav1.ObjectMeta{Name: "23ke", Namespace: "flux-system"},
+		}
+		bucket.Status.Conditions = append(
+			readyCondition(metav1.ConditionFalse, sourcev1.BucketOperationFailedReason, "building artifact: failed"),
+			metav1.Condition{
+				Type:    sourcev1.FetchFailedCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  sourcev1.BucketOperationFailedReason,
+				Message: "bucket '23ke' does not exist",
+			},
+		)
+
+		c := &check.BucketCheck{Name: "23ke", Namespace: "flux-system"}
+		result := c.Run(newTestEnv(bucket))
+
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Status).To(Equal("bucket '23ke' does not exist"))
+		Expect(c.Hint(result)).To(ContainSubstring("license"))
+	})
+
+	It("is progressing while a HelmRepository is fetched for the first time", func() {
+		repo := &sourcev1.HelmRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "gardener-charts", Namespace: "flux-system"},
+		}
+		repo.Status.Conditions = readyCondition(metav1.ConditionUnknown, meta.ProgressingReason, "reconciliation in progress")
+
+		result := (&check.HelmRepositoryCheck{Name: "gardener-charts", Namespace: "flux-system"}).Run(newTestEnv(repo))
+
+		Expect(result.IsOkay).To(BeFalse())
+		Expect(result.IsError).To(BeFalse())
+	})
+
+	It("lists the source of a Kustomization as dependency", func() {
+		ks := kustomization("23ke-config", readyCondition(metav1.ConditionFalse, kustomizev1.ArtifactFailedReason, "Source is not ready"))
+		ks.Spec.SourceRef = kustomizev1.CrossNamespaceSourceReference{Kind: sourcev1.GitRepositoryKind, Name: "23ke-config"}
+		ks.Spec.DependsOn = []meta.NamespacedObjectReference{{Name: "23ke-base"}}
+
+		result := (&check.KustomizationCheck{Name: "23ke-config", Namespace: "flux-system"}).Run(newTestEnv(ks))
+
+		Expect(result.DependsOn).To(ConsistOf(
+			check.ObjectKey(sourcev1.GitRepositoryKind, "flux-system", "23ke-config"),
+			check.ObjectKey(kustomizev1.KustomizationKind, "flux-system", "23ke-base"),
+		))
+	})
+})
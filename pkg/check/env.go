@@ -0,0 +1,47 @@
+package check
+
+import (
+	"context"
+
+	fluxutils "github.com/23technologies/23kectl/pkg/fluxutils"
+	runclient "github.com/fluxcd/pkg/runtime/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Env holds everything a check needs to talk to the cluster.
+type Env struct {
+	Context  context.Context
+	Client   client.WithWatch
+	ClientGo kubernetes.Interface
+	// BlockUntilKeyCanRead waits until the deploy key can read the config repo, registering
+	// it if possible. It's optional, the deploy key fix doesn't wait without it.
+	BlockUntilKeyCanRead func(repoURL string, keys *ssh.PublicKeys, pubkey string) error
+}
+
+// NewEnv connects to the cluster selected by the given kubeconfig flags,
+// e.g. --kubeconfig and --context.
+func NewEnv(ctx context.Context, rcg genericclioptions.RESTClientGetter) (*Env, error) {
+	kubeClient, err := fluxutils.KubeClient(rcg, &runclient.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := rcg.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientGo, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Env{
+		Context:  ctx,
+		Client:   kubeClient,
+		ClientGo: clientGo,
+	}, nil
+}
@@ -0,0 +1,3 @@
+package check
+
+var NewObject = newObject
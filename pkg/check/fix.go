@@ -0,0 +1,158 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Fix is a remediation offered by `doctor --fix`.
+type Fix struct {
+	Description string
+	Apply       func(env *Env) error
+}
+
+// reconcileFix makes flux reconcile the object right away, like `flux reconcile` does.
+func reconcileFix(kind string, namespace string, name string) Fix {
+	return Fix{
+		Description: fmt.Sprintf("Request a reconciliation of %s %s/%s", kind, namespace, name),
+		Apply: func(env *Env) error {
+			obj, err := newObject(kind)
+			if err != nil {
+				return err
+			}
+
+			err = env.Client.Get(env.Context, client.ObjectKey{Namespace: namespace, Name: name}, obj)
+			if err != nil {
+				return err
+			}
+
+			patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+			utils.RequestReconcile(obj)
+
+			return env.Client.Patch(env.Context, obj, patch)
+		},
+	}
+}
+
+// suspendResumeFix resets the install/upgrade retries of a HelmRelease,
+// which helm-controller doesn't do by itself once they are exhausted.
+func suspendResumeFix(namespace string, name string) Fix {
+	return Fix{
+		Description: fmt.Sprintf("Suspend and resume HelmRelease %s/%s to reset its retries", namespace, name),
+		Apply: func(env *Env) error {
+			hr := &helmv2.HelmRelease{}
+			key := client.ObjectKey{Namespace: namespace, Name: name}
+
+			for _, suspend := range []bool{true, false} {
+				err := env.Client.Get(env.Context, key, hr)
+				if err != nil {
+					return err
+				}
+
+				patch := client.MergeFrom(hr.DeepCopy())
+				hr.Spec.Suspend = suspend
+				if !suspend {
+					utils.RequestReconcile(hr)
+				}
+
+				err = env.Client.Patch(env.Context, hr, patch)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// helmChartFix makes flux fetch the chart of a HelmRelease again.
+func helmChartFix(namespace string, name string) Fix {
+	return Fix{
+		Description: fmt.Sprintf("Request a reconciliation of the HelmChart of HelmRelease %s/%s", namespace, name),
+		Apply: func(env *Env) error {
+			hr := &helmv2.HelmRelease{}
+			err := env.Client.Get(env.Context, client.ObjectKey{Namespace: namespace, Name: name}, hr)
+			if err != nil {
+				return err
+			}
+
+			return reconcileFix(sourcev1.HelmChartKind, hr.Spec.Chart.GetNamespace(hr.Namespace), hr.GetHelmChartName()).Apply(env)
+		},
+	}
+}
+
+// deployKeyFix waits until the deploy key of the config repo has been granted
+// read access again, then makes flux fetch the repo. Without Env.BlockUntilKeyCanRead,
+// it only makes flux fetch the repo.
+func deployKeyFix() Fix {
+	return Fix{
+		Description: fmt.Sprintf("Verify the deploy key of GitRepository %s/%s", common.FLUX_NAMESPACE, common.CONFIG_23KE_GITREPO_NAME),
+		Apply: func(env *Env) error {
+			gitRepo := &sourcev1.GitRepository{}
+			err := env.Client.Get(env.Context, client.ObjectKey{
+				Namespace: common.FLUX_NAMESPACE,
+				Name:      common.CONFIG_23KE_GITREPO_NAME,
+			}, gitRepo)
+			if err != nil {
+				return err
+			}
+
+			sec := &corev1.Secret{}
+			err = env.Client.Get(env.Context, client.ObjectKey{
+				Namespace: common.FLUX_NAMESPACE,
+				Name:      common.CONFIG_23KE_GITREPO_KEY,
+			}, sec)
+			if err != nil {
+				return fmt.Errorf("couldn't read the deploy key of the config repo: %w", err)
+			}
+
+			reconcile := reconcileFix(sourcev1.GitRepositoryKind, gitRepo.Namespace, gitRepo.Name)
+
+			// https remotes use a token, there's no deploy key to wait for
+			if _, ok := sec.Data["identity"]; !ok || env.BlockUntilKeyCanRead == nil {
+				return reconcile.Apply(env)
+			}
+
+			publicKeys, err := ssh.NewPublicKeys("git", sec.Data["identity"], "")
+			if err != nil {
+				return err
+			}
+
+			err = env.BlockUntilKeyCanRead(gitRepo.Spec.URL, publicKeys, string(sec.Data["identity.pub"]))
+			if err != nil {
+				return err
+			}
+
+			return reconcile.Apply(env)
+		},
+	}
+}
+
+// newObject returns an empty flux object of the kind.
+func newObject(kind string) (client.Object, error) {
+	switch kind {
+	case helmv2.HelmReleaseKind:
+		return &helmv2.HelmRelease{}, nil
+	case kustomizev1.KustomizationKind:
+		return &kustomizev1.Kustomization{}, nil
+	case sourcev1.HelmChartKind:
+		return &sourcev1.HelmChart{}, nil
+	case sourcev1.GitRepositoryKind:
+		return &sourcev1.GitRepository{}, nil
+	case sourcev1.BucketKind:
+		return &sourcev1.Bucket{}, nil
+	case sourcev1.HelmRepositoryKind:
+		return &sourcev1.HelmRepository{}, nil
+	default:
+		return nil, fmt.Errorf("unknown kind %s", kind)
+	}
+}
@@ -0,0 +1,100 @@
+package check_test
+
+import (
+	"github.com/23technologies/23kectl/pkg/check"
+	"github.com/23technologies/23kectl/pkg/common"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	fluxssh "github.com/fluxcd/pkg/ssh"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Hints and fixes", func() {
+	It("resets the retries of an exhausted HelmRelease", func() {
+		env := newTestEnv(helmRelease("gardener", readyCondition(metav1.ConditionFalse, "InstallFailed", "install retries exhausted")))
+		c := &check.HelmReleaseCheck{Name: "gardener", Namespace: "flux-system"}
+
+		result := c.Run(env)
+		Expect(c.Hint(result)).To(ContainSubstring("suspend and resume"))
+
+		fixes := c.Fixes(result)
+		Expect(fixes).To(HaveLen(1))
+		Expect(fixes[0].Apply(env)).To(Succeed())
+
+		hr := &helmv2.HelmRelease{}
+		Expect(env.Client.Get(env.Context, client.ObjectKey{Namespace: "flux-system", Name: "gardener"}, hr)).To(Succeed())
+		Expect(hr.Spec.Suspend).To(BeFalse())
+		Expect(hr.Annotations).To(HaveKey(meta.ReconcileRequestAnnotation))
+	})
+
+	It("requests a reconciliation of a Kustomization failing its health checks", func() {
+		env := newTestEnv(kustomization("23ke-base", readyCondition(metav1.ConditionFalse, kustomizev1.HealthCheckFailedReason, "timeout waiting for: [Deployment/garden/gardener-apiserver status: 'InProgress']")))
+		c := &check.KustomizationCheck{Name: "23ke-base", Namespace: "flux-system"}
+
+		result := c.Run(env)
+		Expect(c.Hint(result)).To(ContainSubstring("didn't become healthy"))
+
+		fixes := c.Fixes(result)
+		Expect(fixes).To(HaveLen(1))
+		Expect(fixes[0].Apply(env)).To(Succeed())
+
+		ks := &kustomizev1.Kustomization{}
+		Expect(env.Client.Get(env.Context, client.ObjectKey{Namespace: "flux-system", Name: "23ke-base"}, ks)).To(Succeed())
+		Expect(ks.Annotations).To(HaveKey(meta.ReconcileRequestAnnotation))
+	})
+
+	It("has neither hints nor fixes for a healthy resource", func() {
+		env := newTestEnv(kustomization("23ke-base", readyCondition(metav1.ConditionTrue, "ReconciliationSucceeded", "Applied revision: v1.60.0/abc")))
+		c := &check.KustomizationCheck{Name: "23ke-base", Namespace: "flux-system"}
+
+		result := c.Run(env)
+		Expect(c.Hint(result)).To(BeEmpty())
+		Expect(c.Fixes(result)).To(BeEmpty())
+	})
+
+	It("waits for the deploy key with the injected function before fetching the config repo", func() {
+		keyPair, err := fluxssh.NewEd25519Generator().Generate()
+		Expect(err).NotTo(HaveOccurred())
+
+		gitRepo := &sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: common.CONFIG_23KE_GITREPO_NAME, Namespace: common.FLUX_NAMESPACE},
+			Spec:       sourcev1.GitRepositorySpec{URL: "ssh://git@github.com/my-org/my-config.git"},
+		}
+		gitRepo.Status.Conditions = readyCondition(metav1.ConditionFalse, sourcev1.AuthenticationFailedReason, "ssh: unable to authenticate")
+		env := newTestEnv(gitRepo, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: common.CONFIG_23KE_GITREPO_KEY, Namespace: common.FLUX_NAMESPACE},
+			Data: map[string][]byte{
+				"identity":     keyPair.PrivateKey,
+				"identity.pub": keyPair.PublicKey,
+			},
+		})
+
+		var waitedFor string
+		env.BlockUntilKeyCanRead = func(repoURL string, _ *ssh.PublicKeys, pubkey string) error {
+			waitedFor = repoURL
+			Expect(pubkey).To(Equal(string(keyPair.PublicKey)))
+			return nil
+		}
+
+		c := &check.GitRepositoryCheck{Name: common.CONFIG_23KE_GITREPO_NAME, Namespace: common.FLUX_NAMESPACE}
+		fixes := c.Fixes(c.Run(env))
+		Expect(fixes).To(HaveLen(1))
+		Expect(fixes[0].Apply(env)).To(Succeed())
+
+		Expect(waitedFor).To(Equal(gitRepo.Spec.URL))
+		Expect(env.Client.Get(env.Context, client.ObjectKeyFromObject(gitRepo), gitRepo)).To(Succeed())
+		Expect(gitRepo.Annotations).To(HaveKey(meta.ReconcileRequestAnnotation))
+	})
+
+	It("reports unknown kinds instead of panicking", func() {
+		_, err := check.NewObject("OCIRepository")
+		Expect(err).To(MatchError("unknown kind OCIRepository"))
+	})
+})
@@ -0,0 +1,48 @@
+package check
+
+import (
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type GitRepositoryCheck struct {
+	Name      string
+	Namespace string
+}
+
+func (d *GitRepositoryCheck) GetName() string {
+	return d.Name
+}
+
+func (d *GitRepositoryCheck) Hint(result *Result) string {
+	return hintFor(result)
+}
+
+func (d *GitRepositoryCheck) Fixes(result *Result) []Fix {
+	return fixesFor(result)
+}
+
+func (d *GitRepositoryCheck) Run(env *Env) *Result {
+	result := &Result{
+		Name:      d.Name,
+		Kind:      sourcev1.GitRepositoryKind,
+		Namespace: d.Namespace,
+	}
+
+	gr := &sourcev1.GitRepository{}
+
+	err := env.Client.Get(env.Context, client.ObjectKey{
+		Namespace: d.Namespace,
+		Name:      d.Name,
+	}, gr)
+
+	if err != nil {
+		result.IsError = true
+		result.Status = err.Error()
+		return result
+	}
+
+	setSourceStatus(result, gr.Status.Conditions, gr.Status.Artifact, gr.Status.ObservedGeneration)
+
+	return result
+}
@@ -1,7 +1,6 @@
 package check
 
 import (
-	"context"
 	v1 "github.com/fluxcd/source-controller/api/v1beta2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -15,30 +14,37 @@ func (d *HelmChartsCheck) GetName() string {
 	return d.Name
 }
 
-func (d *HelmChartsCheck) Run() *Result {
-	result := &Result{}
+func (d *HelmChartsCheck) Hint(result *Result) string {
+	return hintFor(result)
+}
+
+func (d *HelmChartsCheck) Fixes(result *Result) []Fix {
+	return fixesFor(result)
+}
+
+func (d *HelmChartsCheck) Run(env *Env) *Result {
+	result := &Result{
+		Name:      d.Name,
+		Kind:      v1.HelmChartKind,
+		Namespace: d.Namespace,
+	}
 
 	hc := &v1.HelmChart{}
 
-	err := KubeClient.Get(context.Background(), client.ObjectKey{
+	err := env.Client.Get(env.Context, client.ObjectKey{
 		Namespace: d.Namespace,
 		Name:      d.Name,
 	}, hc)
 
 	if err != nil {
 		result.IsError = true
+		result.Status = err.Error()
 		return result
 	}
 
-	result.Status = getMessage(hc.Status.Conditions, "Ready")
+	result.DependsOn = []string{ObjectKey(hc.Spec.SourceRef.Kind, hc.Namespace, hc.Spec.SourceRef.Name)}
 
-	if result.Status == "Applied revision" {
-		result.IsError = false
-		result.IsOkay = true
-	} else if result.Status == "SOME DEFINITIVE ERROR" {
-		result.IsError = true
-		result.IsOkay = false
-	}
+	setSourceStatus(result, hc.Status.Conditions, hc.Status.Artifact, hc.Status.ObservedGeneration)
 
 	return result
 }
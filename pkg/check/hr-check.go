@@ -1,7 +1,6 @@
 package check
 
 import (
-	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -24,28 +23,48 @@ func (d *HelmReleaseCheck) GetName() string {
 	return d.Name
 }
 
-func (d *HelmReleaseCheck) Run() *Result {
-	result := &Result{}
+func (d *HelmReleaseCheck) Hint(result *Result) string {
+	return hintFor(result)
+}
+
+func (d *HelmReleaseCheck) Fixes(result *Result) []Fix {
+	return fixesFor(result)
+}
+
+func (d *HelmReleaseCheck) Run(env *Env) *Result {
+	result := &Result{
+		Name:      d.Name,
+		Kind:      helmv2.HelmReleaseKind,
+		Namespace: d.Namespace,
+	}
 
 	hr := &helmv2.HelmRelease{}
 
-	err := KubeClient.Get(context.Background(), client.ObjectKey{
+	err := env.Client.Get(env.Context, client.ObjectKey{
 		Namespace: d.Namespace,
 		Name:      d.Name,
 	}, hr)
 
 	if err != nil {
 		result.IsError = true
+		result.Status = err.Error()
 		return result
 	}
 
+	setReadyCondition(result, hr.Status.Conditions, hr.Status.ObservedGeneration)
+	result.DependsOn = helmReleaseDependencies(hr)
+	result.Revision = hr.Status.LastAppliedRevision
+
+	// fallback, if none of the handlers below matches
+	result.Status = getMessage(hr.Status.Conditions, "Ready")
+
 	// define a slice of handler including a regexp and a function
 	// if we find a match we process the event by an appropriate function
 	// this is assumed to stay branchless in the future which enables easy extensibility
 	// the order of processing is important as we prioritize the status messages
 	type handler struct {
 		regex *regexp.Regexp
-		fn    func(name string, res *Result, matches []string)
+		fn    func(env *Env, name string, res *Result, matches []string)
 	}
 
 	handlers := []handler{
@@ -59,7 +78,7 @@ func (d *HelmReleaseCheck) Run() *Result {
 		},
 		{
 			regex: regexp.MustCompile("(install retries exhausted|upgrade retries exhausted|Helm install failed|Helm upgrade failed).*"),
-			fn: func(name string, res *Result, matches []string) {
+			fn: func(env *Env, name string, res *Result, matches []string) {
 				res.Status = prettify(matches[0])
 				res.IsError = true
 				res.IsOkay = false
@@ -71,7 +90,7 @@ func (d *HelmReleaseCheck) Run() *Result {
 		},
 		{
 			regex: regexp.MustCompile("Release reconciliation succeeded"),
-			fn: func(name string, res *Result, matches []string) {
+			fn: func(env *Env, name string, res *Result, matches []string) {
 				res.Status = prettify(matches[0])
 				res.IsError = false
 				res.IsOkay = true
@@ -85,7 +104,7 @@ func (d *HelmReleaseCheck) Run() *Result {
 		for _, condition := range hr.GetConditions() {
 			matches := curHandler.regex.FindStringSubmatch(condition.Message)
 			if matches != nil {
-				curHandler.fn(hr.Name, result, matches)
+				curHandler.fn(env, hr.Name, result, matches)
 				return result
 			}
 		}
@@ -94,13 +113,27 @@ func (d *HelmReleaseCheck) Run() *Result {
 	return result
 }
 
+func helmReleaseDependencies(hr *helmv2.HelmRelease) []string {
+	dependencies := []string{ObjectKey(sourcev1.HelmChartKind, hr.Spec.Chart.GetNamespace(hr.Namespace), hr.GetHelmChartName())}
+
+	for _, dep := range hr.Spec.DependsOn {
+		namespace := dep.Namespace
+		if namespace == "" {
+			namespace = hr.Namespace
+		}
+		dependencies = append(dependencies, ObjectKey(helmv2.HelmReleaseKind, namespace, dep.Name))
+	}
+
+	return dependencies
+}
+
 // handeHelmTestError ...
-func handeHelmTestError(name string, res *Result, matches []string) {
+func handeHelmTestError(env *Env, name string, res *Result, matches []string) {
 
 	// It seems controller-runtime does not allow to access the logs.
 	// Use kubectl directly for the moment.
-	test := KubeClientGo.CoreV1().Pods("garden").GetLogs(matches[1], &corev1.PodLogOptions{})
-	logs, err := test.Do(context.Background()).Raw()
+	test := env.ClientGo.CoreV1().Pods("garden").GetLogs(matches[1], &corev1.PodLogOptions{})
+	logs, err := test.Do(env.Context).Raw()
 	log := string(logs)
 	if err != nil {
 		log = fmt.Sprintf("couldn't get pod logs: %s", err)
@@ -111,18 +144,18 @@ func handeHelmTestError(name string, res *Result, matches []string) {
 	res.IsOkay = false
 }
 
-func handleHelmInstallTimeoutError(name string, res *Result, matches []string) {
+func handleHelmInstallTimeoutError(env *Env, name string, res *Result, matches []string) {
 
 	// implement further checks here by adding other cases
 	// todo: define a cleaner interface for this process
 	switch name {
 	case "internal-gardenlet":
-		test, _ := KubeClientGo.CoreV1().Pods("garden").List(context.Background(), metav1.ListOptions{
+		test, _ := env.ClientGo.CoreV1().Pods("garden").List(env.Context, metav1.ListOptions{
 			LabelSelector: "role=gardenlet,app=gardener",
 		})
 		var log string
 		for _, pod := range test.Items {
-			logs, _ := KubeClientGo.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).Do(context.Background()).Raw()
+			logs, _ := env.ClientGo.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).Do(env.Context).Raw()
 			if !strings.Contains(log, string(logs)) {
 				log += "\n" + string(logs)
 			}
@@ -138,13 +171,13 @@ func handleHelmInstallTimeoutError(name string, res *Result, matches []string) {
 	}
 }
 
-func handleHelmChartError(name string, res *Result, matches []string) {
+func handleHelmChartError(env *Env, name string, res *Result, matches []string) {
 	namespace := matches[1]
 	podName := matches[2]
 
 	hc := &sourcev1.HelmChart{}
 
-	err := KubeClient.Get(context.Background(), client.ObjectKey{
+	err := env.Client.Get(env.Context, client.ObjectKey{
 		Namespace: namespace,
 		Name:      podName,
 	}, hc)
@@ -0,0 +1,48 @@
+package check
+
+import (
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type HelmRepositoryCheck struct {
+	Name      string
+	Namespace string
+}
+
+func (d *HelmRepositoryCheck) GetName() string {
+	return d.Name
+}
+
+func (d *HelmRepositoryCheck) Hint(result *Result) string {
+	return hintFor(result)
+}
+
+func (d *HelmRepositoryCheck) Fixes(result *Result) []Fix {
+	return fixesFor(result)
+}
+
+func (d *HelmRepositoryCheck) Run(env *Env) *Result {
+	result := &Result{
+		Name:      d.Name,
+		Kind:      sourcev1.HelmRepositoryKind,
+		Namespace: d.Namespace,
+	}
+
+	repo := &sourcev1.HelmRepository{}
+
+	err := env.Client.Get(env.Context, client.ObjectKey{
+		Namespace: d.Namespace,
+		Name:      d.Name,
+	}, repo)
+
+	if err != nil {
+		result.IsError = true
+		result.Status = err.Error()
+		return result
+	}
+
+	setSourceStatus(result, repo.Status.Conditions, repo.Status.Artifact, repo.Status.ObservedGeneration)
+
+	return result
+}
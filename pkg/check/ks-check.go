@@ -1,7 +1,6 @@
 package check
 
 import (
-	"context"
 	"github.com/fluxcd/kustomize-controller/api/v1beta2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strings"
@@ -16,30 +15,77 @@ func (d *KustomizationCheck) GetName() string {
 	return d.Name
 }
 
-func (d *KustomizationCheck) Run() *Result {
-	result := &Result{}
+func (d *KustomizationCheck) Hint(result *Result) string {
+	return hintFor(result)
+}
+
+func (d *KustomizationCheck) Fixes(result *Result) []Fix {
+	return fixesFor(result)
+}
+
+func (d *KustomizationCheck) Run(env *Env) *Result {
+	result := &Result{
+		Name:      d.Name,
+		Kind:      v1beta2.KustomizationKind,
+		Namespace: d.Namespace,
+	}
 
 	ks := &v1beta2.Kustomization{}
 
-	err := KubeClient.Get(context.Background(), client.ObjectKey{
+	err := env.Client.Get(env.Context, client.ObjectKey{
 		Namespace: d.Namespace,
 		Name:      d.Name,
 	}, ks)
 
 	if err != nil {
 		result.IsError = true
+		result.Status = err.Error()
 		return result
 	}
 
+	setReadyCondition(result, ks.Status.Conditions, ks.Status.ObservedGeneration)
+	result.DependsOn = kustomizationDependencies(ks)
+	result.Revision = ks.Status.LastAppliedRevision
+
 	result.Status = getMessage(ks.Status.Conditions, "Ready")
 
 	if strings.Contains(result.Status, "Applied revision") {
 		result.IsError = false
 		result.IsOkay = true
-	} else if result.Status == "SOME DEFINITIVE ERROR" {
+	} else if isDefinitiveKustomizationFailure(result.Reason) {
 		result.IsError = true
 		result.IsOkay = false
 	}
 
 	return result
 }
+
+// isDefinitiveKustomizationFailure tells failures that need intervention apart
+// from ones flux recovers from by itself, e.g. missing dependencies
+func isDefinitiveKustomizationFailure(reason string) bool {
+	switch reason {
+	case v1beta2.BuildFailedReason, v1beta2.PruneFailedReason, v1beta2.HealthCheckFailedReason:
+		return true
+	}
+
+	return false
+}
+
+func kustomizationDependencies(ks *v1beta2.Kustomization) []string {
+	sourceNamespace := ks.Spec.SourceRef.Namespace
+	if sourceNamespace == "" {
+		sourceNamespace = ks.Namespace
+	}
+
+	dependencies := []string{ObjectKey(ks.Spec.SourceRef.Kind, sourceNamespace, ks.Spec.SourceRef.Name)}
+
+	for _, dep := range ks.Spec.DependsOn {
+		namespace := dep.Namespace
+		if namespace == "" {
+			namespace = ks.Namespace
+		}
+		dependencies = append(dependencies, ObjectKey(v1beta2.KustomizationKind, namespace, dep.Name))
+	}
+
+	return dependencies
+}
@@ -0,0 +1,44 @@
+package check
+
+import (
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setSourceStatus evaluates the conditions and artifact shared by all flux sources.
+func setSourceStatus(result *Result, conditions []metav1.Condition, artifact *sourcev1.Artifact, observedGeneration int64) {
+	setReadyCondition(result, conditions, observedGeneration)
+
+	result.Status = getMessage(conditions, meta.ReadyCondition)
+
+	if artifact != nil {
+		result.Revision = artifact.Revision
+		result.LastUpdateTime = artifact.LastUpdateTime.DeepCopy()
+	}
+
+	ready := apimeta.FindStatusCondition(conditions, meta.ReadyCondition)
+
+	if ready != nil && ready.Status == metav1.ConditionTrue {
+		result.IsError = false
+		result.IsOkay = true
+	} else if apimeta.IsStatusConditionTrue(conditions, sourcev1.FetchFailedCondition) {
+		// prefer the fetch error, the Ready message may only say the artifact is outdated
+		result.Status = getMessage(conditions, sourcev1.FetchFailedCondition)
+		result.IsError = true
+		result.IsOkay = false
+	} else if ready != nil && ready.Status == metav1.ConditionFalse && !isProgressingReason(ready.Reason) {
+		result.IsError = true
+		result.IsOkay = false
+	}
+}
+
+func isProgressingReason(reason string) bool {
+	switch reason {
+	case meta.ProgressingReason, meta.ProgressingWithRetryReason, meta.DependencyNotReadyReason:
+		return true
+	}
+
+	return false
+}
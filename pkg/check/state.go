@@ -1,7 +1,31 @@
 package check
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type Result struct {
-	IsError    bool
-	IsOkay     bool
-	Status     string
+	Name               string       `json:"name"`
+	Kind               string       `json:"kind"`
+	Namespace          string       `json:"namespace"`
+	IsError            bool         `json:"isError"`
+	IsOkay             bool         `json:"isOkay"`
+	Status             string       `json:"status"`
+	Hint               string       `json:"hint,omitempty"`
+	Reason             string       `json:"reason,omitempty"`
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	Revision           string       `json:"revision,omitempty"`
+	LastUpdateTime     *metav1.Time `json:"lastUpdateTime,omitempty"`
+	DependsOn          []string     `json:"dependsOn,omitempty"`
+	RootCause          string       `json:"rootCause,omitempty"`
+}
+
+// Key identifies the checked object, see ObjectKey.
+func (r *Result) Key() string {
+	return ObjectKey(r.Kind, r.Namespace, r.Name)
+}
+
+// ObjectKey is how results refer to each other in DependsOn and RootCause.
+func ObjectKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
 }
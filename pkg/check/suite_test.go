@@ -0,0 +1,31 @@
+package check_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/23technologies/23kectl/pkg/check"
+	fluxutils "github.com/23technologies/23kectl/pkg/fluxutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Suite")
+}
+
+// newTestEnv returns an Env backed by fake clients holding the given objects.
+func newTestEnv(objects ...client.Object) *check.Env {
+	return &check.Env{
+		Context: context.Background(),
+		Client: fake.NewClientBuilder().
+			WithScheme(fluxutils.NewScheme()).
+			WithObjects(objects...).
+			Build(),
+		ClientGo: kubefake.NewSimpleClientset(),
+	}
+}
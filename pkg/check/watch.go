@@ -0,0 +1,260 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/object"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectStatus is the Ready condition of a single flux object.
+type ObjectStatus struct {
+	Kind               string
+	Namespace          string
+	Name               string
+	Ready              metav1.ConditionStatus
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+func (s *ObjectStatus) IsReady() bool {
+	return s.Ready == metav1.ConditionTrue
+}
+
+type conditionsGetter interface {
+	GetConditions() []metav1.Condition
+}
+
+// AllReady returns true if there's at least one status and all of them are Ready.
+func AllReady(statuses []ObjectStatus) bool {
+	if len(statuses) == 0 {
+		return false
+	}
+
+	for _, status := range statuses {
+		if !status.IsReady() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// kindEvent is either a single watch event or, if listed is set, the result
+// of (re)listing all objects of the kind.
+type kindEvent struct {
+	kind    string
+	event   watch.Event
+	listed  bool
+	objects []runtime.Object
+}
+
+// Watch sends the status of all flux sources, HelmCharts, HelmReleases and
+// Kustomizations in the namespace every time one of them changes.
+// The first status is sent once every kind has been listed, so it's complete.
+// The channel is closed once the env's context is done.
+func Watch(env *Env, namespace string) <-chan []ObjectStatus {
+	ctx := env.Context
+
+	lists := map[string]client.ObjectList{
+		sourcev1.GitRepositoryKind:    &sourcev1.GitRepositoryList{},
+		sourcev1.BucketKind:           &sourcev1.BucketList{},
+		sourcev1.HelmRepositoryKind:   &sourcev1.HelmRepositoryList{},
+		sourcev1.HelmChartKind:        &sourcev1.HelmChartList{},
+		helmv2.HelmReleaseKind:        &helmv2.HelmReleaseList{},
+		kustomizev1.KustomizationKind: &kustomizev1.KustomizationList{},
+	}
+
+	events := make(chan kindEvent)
+	for kind, list := range lists {
+		go watchKind(env, namespace, kind, list, events)
+	}
+
+	updates := make(chan []ObjectStatus)
+	go func() {
+		defer close(updates)
+
+		statuses := map[string]ObjectStatus{}
+		listed := map[string]bool{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev := <-events:
+				if ev.listed {
+					for key, status := range statuses {
+						if status.Kind == ev.kind {
+							delete(statuses, key)
+						}
+					}
+					for _, item := range ev.objects {
+						if obj, ok := item.(client.Object); ok {
+							statuses[statusKey(ev.kind, obj)] = newObjectStatus(ev.kind, obj)
+						}
+					}
+					listed[ev.kind] = true
+				} else {
+					obj, ok := ev.event.Object.(client.Object)
+					if !ok {
+						continue
+					}
+
+					if ev.event.Type == watch.Deleted {
+						delete(statuses, statusKey(ev.kind, obj))
+					} else {
+						statuses[statusKey(ev.kind, obj)] = newObjectStatus(ev.kind, obj)
+					}
+				}
+
+				// a partial snapshot could look ready while other objects fail
+				if len(listed) < len(lists) {
+					continue
+				}
+
+				select {
+				case updates <- sortedStatuses(statuses):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return updates
+}
+
+func statusKey(kind string, obj client.Object) string {
+	return kind + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// watchKind lists the objects of the kind and watches them from there on.
+// It lists and watches again whenever the API server closes the watch.
+func watchKind(env *Env, namespace string, kind string, list client.ObjectList, events chan<- kindEvent) {
+	ctx := env.Context
+	for ctx.Err() == nil {
+		err := env.Client.List(ctx, list, client.InNamespace(namespace))
+		if apimeta.IsNoMatchError(err) {
+			// the CRD isn't installed, so there's nothing to wait for
+			select {
+			case events <- kindEvent{kind: kind, listed: true}:
+			case <-ctx.Done():
+			}
+			return
+		}
+		if err != nil {
+			sleepOrDone(ctx, 5*time.Second)
+			continue
+		}
+
+		objects, err := apimeta.ExtractList(list)
+		if err != nil {
+			sleepOrDone(ctx, 5*time.Second)
+			continue
+		}
+
+		select {
+		case events <- kindEvent{kind: kind, listed: true, objects: objects}:
+		case <-ctx.Done():
+			return
+		}
+
+		watcher, err := env.Client.Watch(ctx, list, &client.ListOptions{
+			Namespace: namespace,
+			Raw:       &metav1.ListOptions{ResourceVersion: list.GetResourceVersion()},
+		})
+		if err != nil {
+			sleepOrDone(ctx, 5*time.Second)
+			continue
+		}
+
+		for event := range watcher.ResultChan() {
+			if event.Type == watch.Error {
+				continue
+			}
+
+			select {
+			case events <- kindEvent{kind: kind, event: event}:
+			case <-ctx.Done():
+				watcher.Stop()
+				return
+			}
+		}
+	}
+}
+
+func sleepOrDone(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+func newObjectStatus(kind string, obj client.Object) ObjectStatus {
+	status := ObjectStatus{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Ready:     metav1.ConditionUnknown,
+	}
+
+	getter, ok := obj.(conditionsGetter)
+	if !ok {
+		return status
+	}
+
+	condition := apimeta.FindStatusCondition(getter.GetConditions(), meta.ReadyCondition)
+	if condition != nil {
+		status.Ready = condition.Status
+		status.Reason = condition.Reason
+		status.Message = condition.Message
+		status.LastTransitionTime = condition.LastTransitionTime.Time
+	}
+
+	// the condition is stale until flux reconciled the latest change, e.g. a config push
+	observed, err := object.GetStatusObservedGeneration(obj)
+	if err == nil && observed < obj.GetGeneration() {
+		status.Ready = metav1.ConditionUnknown
+		status.Reason = meta.ProgressingReason
+		status.Message = fmt.Sprintf("generation %d hasn't been reconciled yet, the last reconciled one is %d", obj.GetGeneration(), observed)
+	}
+
+	return status
+}
+
+// sortedStatuses orders the statuses like flux resolves them: sources first.
+func sortedStatuses(statuses map[string]ObjectStatus) []ObjectStatus {
+	kindOrder := map[string]int{
+		sourcev1.GitRepositoryKind:    0,
+		sourcev1.BucketKind:           1,
+		sourcev1.HelmRepositoryKind:   2,
+		sourcev1.HelmChartKind:        3,
+		kustomizev1.KustomizationKind: 4,
+		helmv2.HelmReleaseKind:        5,
+	}
+
+	result := make([]ObjectStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, status)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return kindOrder[result[i].Kind] < kindOrder[result[j].Kind]
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,64 @@
+package check_test
+
+import (
+	"context"
+
+	"github.com/23technologies/23kectl/pkg/check"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Watch", func() {
+	It("sends the first status once every kind is listed", func() {
+		gitRepo := &sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "23ke-config", Namespace: "flux-system"},
+		}
+		gitRepo.Status.Conditions = readyCondition(metav1.ConditionTrue, "Succeeded", "stored artifact")
+
+		env := newTestEnv(
+			gitRepo,
+			kustomization("23ke-base", readyCondition(metav1.ConditionFalse, "BuildFailed", "kustomize build failed")),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		env.Context = ctx
+
+		var statuses []check.ObjectStatus
+		Eventually(check.Watch(env, "flux-system")).Should(Receive(&statuses))
+
+		Expect(statuses).To(HaveLen(2))
+		Expect(statuses[0].Kind).To(Equal(sourcev1.GitRepositoryKind))
+		Expect(statuses[0].IsReady()).To(BeTrue())
+		Expect(statuses[1].Kind).To(Equal(kustomizev1.KustomizationKind))
+		Expect(statuses[1].IsReady()).To(BeFalse())
+		Expect(check.AllReady(statuses)).To(BeFalse())
+	})
+
+	It("isn't ready until the latest generation is reconciled", func() {
+		gitRepo := &sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "23ke-config", Namespace: "flux-system", Generation: 2},
+		}
+		gitRepo.Status.ObservedGeneration = 1
+		gitRepo.Status.Conditions = readyCondition(metav1.ConditionTrue, "Succeeded", "stored artifact")
+
+		env := newTestEnv(gitRepo)
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		env.Context = ctx
+
+		var statuses []check.ObjectStatus
+		Eventually(check.Watch(env, "flux-system")).Should(Receive(&statuses))
+
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].IsReady()).To(BeFalse())
+		Expect(statuses[0].Reason).To(Equal("Progressing"))
+		Expect(check.AllReady(statuses)).To(BeFalse())
+	})
+
+	It("isn't ready without any objects", func() {
+		Expect(check.AllReady(nil)).To(BeFalse())
+	})
+})
@@ -0,0 +1,225 @@
+// Package clusterdetect infers the provider, region and node CIDR of a base
+// cluster from its nodes, and whether it provides vertical pod autoscaling.
+// Every value comes with an explanation, as it's a default to be confirmed.
+package clusterdetect
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/netdetect"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Finding is a detected value and where it came from.
+type Finding struct {
+	Value  string
+	Source string
+}
+
+// BaseCluster holds what was detected about the base cluster, nil if nothing was.
+type BaseCluster struct {
+	Provider                 *Finding
+	Region                   *Finding
+	NodeCidr                 *Finding
+	HasVerticalPodAutoscaler *VPAFinding
+}
+
+// VPAFinding tells whether the base cluster provides vertical pod autoscaling.
+type VPAFinding struct {
+	Present bool
+	Source  string
+}
+
+// providers of base clusters by the scheme of Node.spec.providerID
+var providerIDSchemes = map[string]string{
+	"hcloud":    "hcloud",
+	"azure":     "azure",
+	"aws":       "aws",
+	"openstack": "openstack",
+	"gce":       "gcp",
+}
+
+// alicloudProviderID matches the providerID of alicloud nodes, which has no
+// scheme but is the region and the instance id, e.g. eu-central-1.i-gw8abc
+var alicloudProviderID = regexp.MustCompile(`^([a-z]{2}-[a-z0-9-]+)\.(i-[a-z0-9]+)$`)
+
+// region labels of the nodes, newest first
+var regionLabels = []string{
+	corev1.LabelTopologyRegion,
+	corev1.LabelFailureDomainBetaRegion,
+}
+
+// MAX_NODE_CIDR_PREFIX_LENGTH widens the network of the nodes' addresses, so
+// nodes added later are likely contained.
+const MAX_NODE_CIDR_PREFIX_LENGTH = 24
+
+// Detect lists the nodes of the base cluster and derives what it can from them.
+func Detect(ctx context.Context, kubeClient client.Client) (BaseCluster, error) {
+	list := corev1.NodeList{}
+	err := kubeClient.List(ctx, &list)
+	if err != nil {
+		return BaseCluster{}, err
+	}
+
+	result := BaseCluster{
+		Provider: DetectProvider(list.Items),
+		Region:   DetectRegion(list.Items),
+		NodeCidr: DetectNodeCidr(list.Items),
+	}
+
+	result.HasVerticalPodAutoscaler, err = DetectVerticalPodAutoscaler(ctx, kubeClient)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// DetectProvider reads the provider from the providerID the cloud controller
+// manager sets on every node, e.g. hcloud://12345678 or eu-central-1.i-gw8abc
+// on alicloud.
+func DetectProvider(nodes []corev1.Node) *Finding {
+	provider := ""
+	for _, node := range nodes {
+		nodeProvider := providerOfProviderID(node.Spec.ProviderID)
+		if nodeProvider == "" || provider != "" && nodeProvider != provider {
+			return nil
+		}
+		provider = nodeProvider
+	}
+
+	if provider == "" {
+		return nil
+	}
+
+	return &Finding{
+		Value:  provider,
+		Source: fmt.Sprintf("the providerID %s of node %s", nodes[0].Spec.ProviderID, nodes[0].Name),
+	}
+}
+
+func providerOfProviderID(providerID string) string {
+	if alicloudProviderID.MatchString(providerID) {
+		return "alicloud"
+	}
+
+	scheme, _, ok := strings.Cut(providerID, "://")
+	if !ok {
+		return ""
+	}
+
+	return providerIDSchemes[scheme]
+}
+
+// DetectRegion reads the region from the topology labels of the nodes. Without
+// them, it's derived from the zone in the providerID of aws and gcp nodes, or
+// the region in the providerID of openstack and alicloud nodes.
+func DetectRegion(nodes []corev1.Node) *Finding {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	for _, label := range regionLabels {
+		region := nodes[0].Labels[label]
+		if region == "" {
+			continue
+		}
+
+		for _, node := range nodes[1:] {
+			if node.Labels[label] != region {
+				// a cluster spanning regions needs to be entered explicitly
+				return nil
+			}
+		}
+
+		return &Finding{Value: region, Source: fmt.Sprintf("the label %s of the nodes", label)}
+	}
+
+	region := regionOfProviderID(nodes[0].Spec.ProviderID)
+	if region == "" {
+		return nil
+	}
+
+	return &Finding{Value: region, Source: fmt.Sprintf("the providerID %s of node %s", nodes[0].Spec.ProviderID, nodes[0].Name)}
+}
+
+// regionOfProviderID parses e.g. aws:///eu-central-1a/i-0123, gce://project/europe-west3-a/node,
+// openstack://RegionOne/5a2c... and eu-central-1.i-gw8abc
+func regionOfProviderID(providerID string) string {
+	if match := alicloudProviderID.FindStringSubmatch(providerID); match != nil {
+		return match[1]
+	}
+
+	scheme, rest, ok := strings.Cut(providerID, "://")
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(strings.TrimPrefix(rest, "/"), "/")
+
+	switch scheme {
+	case "aws":
+		// the zone is the region plus a letter
+		if len(parts) == 2 && len(parts[0]) > 1 {
+			return parts[0][:len(parts[0])-1]
+		}
+	case "gce":
+		// the zone is the region plus -a, -b, ...
+		if len(parts) == 3 {
+			if i := strings.LastIndex(parts[1], "-"); i > 0 {
+				return parts[1][:i]
+			}
+		}
+	case "openstack":
+		if len(parts) == 2 && !strings.HasPrefix(rest, "/") {
+			return parts[0]
+		}
+	}
+
+	return ""
+}
+
+// DetectNodeCidr returns the smallest network containing the InternalIPs of all
+// nodes, widened to at least a /24.
+func DetectNodeCidr(nodes []corev1.Node) *Finding {
+	var cidrs []string
+	count := 0
+	for _, node := range nodes {
+		found := false
+		for _, address := range node.Status.Addresses {
+			ip := net.ParseIP(address.Address)
+			if address.Type != corev1.NodeInternalIP || ip == nil || ip.To4() == nil {
+				continue
+			}
+			cidrs = append(cidrs, ip.String()+"/32")
+			found = true
+		}
+		if found {
+			count++
+		}
+	}
+
+	if len(cidrs) == 0 {
+		return nil
+	}
+
+	cidr, err := netdetect.AggregateCidrs(cidrs)
+	if err != nil {
+		return nil
+	}
+
+	_, network, _ := net.ParseCIDR(cidr)
+	if ones, _ := network.Mask.Size(); ones > MAX_NODE_CIDR_PREFIX_LENGTH {
+		network.Mask = net.CIDRMask(MAX_NODE_CIDR_PREFIX_LENGTH, 32)
+		network.IP = network.IP.Mask(network.Mask)
+	}
+
+	return &Finding{
+		Value:  network.String(),
+		Source: fmt.Sprintf("the InternalIPs of %d node(s), widen it to the nodes' subnet if new nodes may get other addresses", count),
+	}
+}
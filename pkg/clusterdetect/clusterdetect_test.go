@@ -0,0 +1,175 @@
+package clusterdetect_test
+
+import (
+	"context"
+
+	"github.com/23technologies/23kectl/pkg/clusterdetect"
+	fluxutils "github.com/23technologies/23kectl/pkg/fluxutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func node(name string, providerID string, labels map[string]string, internalIPs ...string) corev1.Node {
+	n := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+	}
+	for _, ip := range internalIPs {
+		n.Status.Addresses = append(n.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip})
+	}
+
+	return n
+}
+
+func region(name string) map[string]string {
+	return map[string]string{corev1.LabelTopologyRegion: name}
+}
+
+func recommender(namespace string, ready int32) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpa-recommender", Namespace: namespace},
+	}
+	deployment.Status.ReadyReplicas = ready
+
+	return deployment
+}
+
+var vpaCrd = &apiextensionsv1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{Name: clusterdetect.VPA_CRD_NAME},
+}
+
+func newTestClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(fluxutils.NewScheme()).
+		WithObjects(objects...).
+		Build()
+}
+
+var _ = Describe("DetectProvider", func() {
+	DescribeTable("reads the provider from the providerID",
+		func(providerID string, expected string) {
+			finding := clusterdetect.DetectProvider([]corev1.Node{node("a", providerID, nil)})
+			Expect(finding).NotTo(BeNil())
+			Expect(finding.Value).To(Equal(expected))
+			Expect(finding.Source).To(ContainSubstring(providerID))
+		},
+		Entry("hcloud", "hcloud://12345678", "hcloud"),
+		Entry("azure", "azure:///subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm", "azure"),
+		Entry("aws", "aws:///eu-central-1a/i-0123456789abcdef0", "aws"),
+		Entry("openstack", "openstack:///5a2c0e4c-5a3f-4f4e-8d47-3f1f0c0b6b9a", "openstack"),
+		Entry("gcp", "gce://my-project/europe-west3-a/gke-node", "gcp"),
+		Entry("alicloud", "eu-central-1.i-gw8abcdef0123456789", "alicloud"),
+	)
+
+	It("detects nothing for unknown or mixed providers", func() {
+		Expect(clusterdetect.DetectProvider([]corev1.Node{node("a", "kind://docker/kind/kind-control-plane", nil)})).To(BeNil())
+		Expect(clusterdetect.DetectProvider([]corev1.Node{node("a", "", nil)})).To(BeNil())
+		Expect(clusterdetect.DetectProvider([]corev1.Node{
+			node("a", "hcloud://1", nil),
+			node("b", "aws:///eu-central-1a/i-0123", nil),
+		})).To(BeNil())
+		Expect(clusterdetect.DetectProvider(nil)).To(BeNil())
+	})
+})
+
+var _ = Describe("DetectRegion", func() {
+	It("reads the region label", func() {
+		finding := clusterdetect.DetectRegion([]corev1.Node{
+			node("a", "hcloud://1", region("hel1")),
+			node("b", "hcloud://2", region("hel1")),
+		})
+		Expect(finding).To(Equal(&clusterdetect.Finding{Value: "hel1", Source: "the label topology.kubernetes.io/region of the nodes"}))
+	})
+
+	It("falls back to the beta label", func() {
+		finding := clusterdetect.DetectRegion([]corev1.Node{
+			node("a", "azure:///x", map[string]string{corev1.LabelFailureDomainBetaRegion: "germanywestcentral"}),
+		})
+		Expect(finding.Value).To(Equal("germanywestcentral"))
+	})
+
+	DescribeTable("derives the region from the providerID",
+		func(providerID string, expected string) {
+			finding := clusterdetect.DetectRegion([]corev1.Node{node("a", providerID, nil)})
+			Expect(finding).NotTo(BeNil())
+			Expect(finding.Value).To(Equal(expected))
+		},
+		Entry("aws", "aws:///eu-central-1a/i-0123456789abcdef0", "eu-central-1"),
+		Entry("gcp", "gce://my-project/europe-west3-a/gke-node", "europe-west3"),
+		Entry("openstack with a region", "openstack://RegionOne/5a2c0e4c", "RegionOne"),
+		Entry("alicloud", "cn-hangzhou.i-bp1abcdef0123456789", "cn-hangzhou"),
+	)
+
+	It("detects nothing for clusters spanning regions or without hints", func() {
+		Expect(clusterdetect.DetectRegion([]corev1.Node{
+			node("a", "aws:///eu-central-1a/i-1", region("eu-central-1")),
+			node("b", "aws:///eu-west-1a/i-2", region("eu-west-1")),
+		})).To(BeNil())
+		Expect(clusterdetect.DetectRegion([]corev1.Node{node("a", "hcloud://1", nil)})).To(BeNil())
+		Expect(clusterdetect.DetectRegion([]corev1.Node{node("a", "openstack:///5a2c0e4c", nil)})).To(BeNil())
+	})
+})
+
+var _ = Describe("DetectNodeCidr", func() {
+	It("widens the network of the InternalIPs to a /24", func() {
+		finding := clusterdetect.DetectNodeCidr([]corev1.Node{
+			node("a", "", nil, "10.250.0.2", "fd00::2"),
+			node("b", "", nil, "10.250.0.3"),
+		})
+		Expect(finding.Value).To(Equal("10.250.0.0/24"))
+		Expect(finding.Source).To(ContainSubstring("2 node(s)"))
+	})
+
+	It("contains addresses of different subnets", func() {
+		finding := clusterdetect.DetectNodeCidr([]corev1.Node{
+			node("a", "", nil, "10.0.1.10"),
+			node("b", "", nil, "10.0.2.10"),
+		})
+		Expect(finding.Value).To(Equal("10.0.0.0/22"))
+	})
+
+	It("ignores ExternalIPs", func() {
+		n := node("a", "", nil)
+		n.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeExternalIP, Address: "203.0.113.10"}}
+		Expect(clusterdetect.DetectNodeCidr([]corev1.Node{n})).To(BeNil())
+	})
+})
+
+var _ = Describe("DetectVerticalPodAutoscaler", func() {
+	It("detects no VPA without its CRD", func() {
+		finding, err := clusterdetect.DetectVerticalPodAutoscaler(context.Background(), newTestClient())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(finding.Present).To(BeFalse())
+	})
+
+	It("detects a VPA with a running recommender", func() {
+		finding, err := clusterdetect.DetectVerticalPodAutoscaler(context.Background(), newTestClient(vpaCrd, recommender("kube-system", 1)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(finding.Present).To(BeTrue())
+		Expect(finding.Source).To(ContainSubstring("kube-system/vpa-recommender"))
+	})
+
+	It("ignores the VPA of gardener and recommenders which aren't ready", func() {
+		finding, err := clusterdetect.DetectVerticalPodAutoscaler(context.Background(), newTestClient(vpaCrd, recommender("garden", 1), recommender("vpa", 0)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(finding).To(BeNil())
+	})
+})
+
+var _ = Describe("Detect", func() {
+	It("detects everything from an hcloud cluster", func() {
+		a := node("a", "hcloud://1", region("fsn1"), "10.0.0.2")
+		result, err := clusterdetect.Detect(context.Background(), newTestClient(&a))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Provider.Value).To(Equal("hcloud"))
+		Expect(result.Region.Value).To(Equal("fsn1"))
+		Expect(result.NodeCidr.Value).To(Equal("10.0.0.0/24"))
+		Expect(result.HasVerticalPodAutoscaler.Present).To(BeFalse())
+	})
+})
@@ -0,0 +1,69 @@
+package clusterdetect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VPA_CRD_NAME is the CRD every vertical pod autoscaler installs.
+const VPA_CRD_NAME = "verticalpodautoscalers.autoscaling.k8s.io"
+
+// DetectVerticalPodAutoscaler checks for the VPA CRD and a running recommender.
+// VPAs deployed by gardener itself don't count, as they're removed together
+// with it. If the CRD exists without a visible recommender, e.g. because the
+// provider runs it outside the cluster, nil is returned.
+func DetectVerticalPodAutoscaler(ctx context.Context, kubeClient client.Client) (*VPAFinding, error) {
+	crd := apiextensionsv1.CustomResourceDefinition{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Name: VPA_CRD_NAME}, &crd)
+	if apierrors.IsNotFound(err) {
+		return &VPAFinding{Present: false, Source: fmt.Sprintf("the CRD %s doesn't exist", VPA_CRD_NAME)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list := appsv1.DeploymentList{}
+	err = kubeClient.List(ctx, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deployment := range list.Items {
+		if !isRecommender(&deployment) || isManagedByGardener(&deployment) {
+			continue
+		}
+
+		if deployment.Status.ReadyReplicas > 0 {
+			return &VPAFinding{
+				Present: true,
+				Source:  fmt.Sprintf("the CRD %s and the recommender %s/%s", VPA_CRD_NAME, deployment.Namespace, deployment.Name),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func isRecommender(deployment *appsv1.Deployment) bool {
+	return strings.Contains(deployment.Name, "vpa-recommender") || deployment.Labels["app"] == "vpa-recommender"
+}
+
+func isManagedByGardener(deployment *appsv1.Deployment) bool {
+	if deployment.Namespace == "garden" {
+		return true
+	}
+
+	for label := range deployment.Labels {
+		if strings.HasPrefix(label, "gardener.cloud/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,259 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/credstore"
+	"github.com/23technologies/23kectl/pkg/redact"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// CREDENTIAL_REF_PREFIX marks config values kept in the credential store,
+// e.g. bucket.secretkey: credstore:1a2b3c4d/bucket.secretkey
+const CREDENTIAL_REF_PREFIX = "credstore:"
+
+// CREDENTIAL_STORE_PASSPHRASE_ENV holds the passphrase of the file credential
+// store, so it isn't prompted for.
+const CREDENTIAL_STORE_PASSPHRASE_ENV = ENV_PREFIX + "_CREDENTIAL_STORE_PASSPHRASE"
+
+// CREDENTIAL_STORE_NONE writes sensitive config keys to the config file.
+const CREDENTIAL_STORE_NONE = "none"
+
+// SensitiveConfigKeys are kept in the credential store instead of the config
+// file, including all keys below them.
+var SensitiveConfigKeys = []string{
+	"bucket.secretkey",
+	"admin.gitToken",
+	"admin.gitProviderToken",
+	"domainConfig.credentials",
+	"backupConfig.credentials",
+}
+
+var credentialStoreKind string
+var credentialStore credstore.Store
+
+// SetCredentialStore sets the kind of credential store sensitive config keys
+// are kept in, see credstore.Open. If it's empty or "none", they are written
+// to the config file.
+func SetCredentialStore(kind string) {
+	if kind == CREDENTIAL_STORE_NONE {
+		kind = ""
+	}
+	credentialStoreKind = kind
+	credentialStore = nil
+}
+
+// IsSensitiveConfigKey reports whether the value of the given config key must
+// not be written to the config file or logged.
+func IsSensitiveConfigKey(configKey string) bool {
+	configKey = strings.ToLower(configKey)
+
+	for _, sensitiveKey := range SensitiveConfigKeys {
+		sensitiveKey = strings.ToLower(sensitiveKey)
+		if configKey == sensitiveKey || strings.HasPrefix(configKey, sensitiveKey+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadConfig reads the config file like viper.ReadInConfig and resolves the
+// values referencing the credential store.
+func ReadConfig() error {
+	err := viper.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, configKey := range viper.AllKeys() {
+		value, ok := viper.Get(configKey).(string)
+		if !ok {
+			continue
+		}
+
+		if !strings.HasPrefix(value, CREDENTIAL_REF_PREFIX) {
+			if IsSensitiveConfigKey(configKey) {
+				redact.Add(value)
+			}
+			continue
+		}
+
+		store, err := getCredentialStore()
+		if err != nil {
+			return fmt.Errorf("%s is kept in the credential store, but it can't be opened: %w", configKey, err)
+		}
+
+		resolved, err := store.Get(strings.TrimPrefix(value, CREDENTIAL_REF_PREFIX))
+		if err != nil {
+			return fmt.Errorf("couldn't read %s from %s: %w", configKey, store, err)
+		}
+
+		redact.Add(resolved)
+		viper.Set(configKey, resolved)
+	}
+
+	return nil
+}
+
+// WriteConfig writes the config file like viper.WriteConfig. Sensitive values
+// are kept in the credential store, if there is one, and only referenced.
+func WriteConfig() error {
+	if viper.ConfigFileUsed() == "" {
+		// fails the same way without a config file
+		return viper.WriteConfig()
+	}
+
+	content, err := MarshalConfig(viper.AllSettings())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(viper.ConfigFileUsed(), content, 0600)
+}
+
+// MarshalConfig returns the content of a config file with the given settings.
+// Sensitive values are moved to the credential store, if there is one, and
+// replaced with references. Either way, they are redacted from now on.
+func MarshalConfig(settings map[string]interface{}) ([]byte, error) {
+	// values set from structs, e.g. by QueryConfigKey, are converted to maps
+	content, err := yaml.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	normalized := map[string]interface{}{}
+	err = yaml.Unmarshal(content, &normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentialStoreKind == "" {
+		redactSensitiveValues(normalized, "")
+		return content, nil
+	}
+
+	store, err := getCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+
+	err = storeSensitiveValues(store, normalized, "", configFileID())
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(normalized)
+}
+
+// storeSensitiveValues moves the sensitive values in settings to the store and
+// replaces them with references.
+func storeSensitiveValues(store credstore.Store, settings map[string]interface{}, prefix string, id string) error {
+	for key, value := range settings {
+		configKey := prefix + key
+
+		switch value := value.(type) {
+		case map[string]interface{}:
+			err := storeSensitiveValues(store, value, configKey+".", id)
+			if err != nil {
+				return err
+			}
+		case string:
+			if value == "" || !IsSensitiveConfigKey(configKey) || strings.HasPrefix(value, CREDENTIAL_REF_PREFIX) {
+				continue
+			}
+
+			redact.Add(value)
+			storeKey := id + "/" + configKey
+
+			current, err := store.Get(storeKey)
+			if err != nil || current != value {
+				err = store.Set(storeKey, value)
+				if err != nil {
+					return fmt.Errorf("couldn't store %s in %s: %w", configKey, store, err)
+				}
+				fmt.Printf("Keeping %s in %s\n", configKey, store)
+			}
+
+			settings[key] = CREDENTIAL_REF_PREFIX + storeKey
+		}
+	}
+
+	return nil
+}
+
+func redactSensitiveValues(settings map[string]interface{}, prefix string) {
+	for key, value := range settings {
+		configKey := prefix + key
+
+		switch value := value.(type) {
+		case map[string]interface{}:
+			redactSensitiveValues(value, configKey+".")
+		case string:
+			if IsSensitiveConfigKey(configKey) {
+				redact.Add(value)
+			}
+		}
+	}
+}
+
+// configFileID tells apart the values of different config files in the store.
+func configFileID() string {
+	path, err := filepath.Abs(viper.ConfigFileUsed())
+	if err != nil {
+		path = viper.ConfigFileUsed()
+	}
+
+	hash := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(hash[:4])
+}
+
+func getCredentialStore() (credstore.Store, error) {
+	if credentialStore != nil {
+		return credentialStore, nil
+	}
+
+	kind := credentialStoreKind
+	if kind == "" {
+		// references are resolved even if new values are written to the config file
+		kind = credstore.KindAuto
+	}
+
+	file, err := credstore.DefaultFile()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := credstore.Open(kind, credstore.Options{
+		File: file,
+		Passphrase: func() (string, error) {
+			if passphrase, ok := os.LookupEnv(CREDENTIAL_STORE_PASSPHRASE_ENV); ok {
+				return passphrase, nil
+			}
+
+			prompt := &survey.Password{
+				Message: fmt.Sprintf("Please enter the passphrase of the credential store %s.", file),
+				Help: fmt.Sprintf(`
+There is no keyring available, so sensitive config keys are kept in a file encrypted with this passphrase.
+It's set when the file is created. Set %s to skip this prompt.
+`, CREDENTIAL_STORE_PASSPHRASE_ENV),
+			}
+			var passphrase string
+			err := AskOne(prompt, &passphrase, "required")
+			ExitOnCtrlC(err)
+			return passphrase, err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	credentialStore = store
+	return credentialStore, nil
+}
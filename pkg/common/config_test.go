@@ -0,0 +1,85 @@
+package common_test
+
+import (
+	"os"
+	"path"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/redact"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("config file", Ordered, func() {
+	var configFile string
+
+	type dnsCredentials struct {
+		ClientID     string `yaml:"clientID"`
+		ClientSecret string `yaml:"clientSecret"`
+	}
+
+	BeforeAll(func() {
+		tmpDir := GinkgoT().TempDir()
+		configFile = path.Join(tmpDir, "config.yaml")
+
+		// the file store is created in the user's config dir
+		GinkgoT().Setenv("XDG_CONFIG_HOME", tmpDir)
+		GinkgoT().Setenv(common.CREDENTIAL_STORE_PASSPHRASE_ENV, "my-passphrase")
+
+		viper.Reset()
+		viper.SetConfigFile(configFile)
+		common.SetCredentialStore("file")
+		DeferCleanup(common.SetCredentialStore, "")
+	})
+
+	It("identifies sensitive config keys", func() {
+		Expect(common.IsSensitiveConfigKey("bucket.secretkey")).To(BeTrue())
+		Expect(common.IsSensitiveConfigKey("admin.gittoken")).To(BeTrue())
+		Expect(common.IsSensitiveConfigKey("domainConfig.credentials.clientSecret")).To(BeTrue())
+		Expect(common.IsSensitiveConfigKey("domainConfig.provider")).To(BeFalse())
+		Expect(common.IsSensitiveConfigKey("bucket.secretkeys")).To(BeFalse())
+	})
+
+	It("keeps sensitive values out of the config file", func() {
+		viper.Set("bucket.endpoint", "localhost:9000")
+		viper.Set("bucket.secretkey", "my-bucket-secret-key")
+		viper.Set("domainConfig.provider", "azure-dns")
+		viper.Set("domainConfig.credentials", &dnsCredentials{ClientID: "my-client-id", ClientSecret: "my-client-secret"})
+
+		Expect(common.WriteConfig()).To(Succeed())
+
+		content, err := os.ReadFile(configFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("localhost:9000"))
+		Expect(string(content)).To(ContainSubstring("azure-dns"))
+		Expect(string(content)).To(ContainSubstring(common.CREDENTIAL_REF_PREFIX))
+		Expect(string(content)).NotTo(ContainSubstring("my-bucket-secret-key"))
+		Expect(string(content)).NotTo(ContainSubstring("my-client-secret"))
+	})
+
+	It("redacts sensitive values", func() {
+		Expect(redact.String("secret key my-bucket-secret-key")).To(Equal("secret key " + redact.REDACTED))
+	})
+
+	It("resolves the references when reading the config file", func() {
+		viper.Reset()
+		viper.SetConfigFile(configFile)
+
+		Expect(common.ReadConfig()).To(Succeed())
+
+		Expect(viper.GetString("bucket.endpoint")).To(Equal("localhost:9000"))
+		Expect(viper.GetString("bucket.secretkey")).To(Equal("my-bucket-secret-key"))
+		Expect(viper.GetString("domainConfig.credentials.clientSecret")).To(Equal("my-client-secret"))
+	})
+
+	It("writes the config file in plaintext without a credential store", func() {
+		common.SetCredentialStore(common.CREDENTIAL_STORE_NONE)
+
+		Expect(common.WriteConfig()).To(Succeed())
+
+		content, err := os.ReadFile(configFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("my-bucket-secret-key"))
+	})
+})
@@ -1,17 +1,147 @@
 package common
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/viper"
+)
+
+// ENV_PREFIX is the prefix of environment variables overriding config keys,
+// e.g. 23KECTL_ADMIN_EMAIL overrides admin.email
+const ENV_PREFIX = "23KECTL"
+
+// MissingConfigKey describes a config key which would have been prompted for
+// in interactive mode.
+type MissingConfigKey struct {
+	Key       string
+	Type      string
+	Validator string
+	Message   string
+}
+
+// MissingConfigKeysError is returned by CheckMissingConfigKeys and lists every
+// config key that couldn't be queried in non-interactive mode.
+type MissingConfigKeysError struct {
+	Keys []MissingConfigKey
+}
+
+func (e *MissingConfigKeysError) Error() string {
+	bob := strings.Builder{}
+	fmt.Fprintf(&bob, "running in non-interactive mode, but %d config key(s) are missing:", len(e.Keys))
+
+	for _, key := range e.Keys {
+		fmt.Fprintf(&bob, "\n  - %s (type: %s", key.Key, key.Type)
+		if key.Validator != "" {
+			fmt.Fprintf(&bob, ", validate: %s", key.Validator)
+		}
+		bob.WriteString(")")
+		if key.Message != "" {
+			fmt.Fprintf(&bob, ": %s", key.Message)
+		}
+	}
+
+	fmt.Fprintf(&bob, "\nset them in the config file or via %s_* environment variables", ENV_PREFIX)
+
+	return bob.String()
+}
+
+var nonInteractive bool
+var missingConfigKeys []MissingConfigKey
+
+// SetNonInteractive disables all prompts. Instead of asking, missing config
+// keys are collected and reported by CheckMissingConfigKeys.
+func SetNonInteractive(enabled bool) {
+	nonInteractive = enabled
+	missingConfigKeys = nil
+}
+
+func IsNonInteractive() bool {
+	return nonInteractive
+}
 
 func QueryConfigKey(configKey string, fn func() (any, error)) error {
+	bindEnv(configKey)
+
 	if !viper.IsSet(configKey) {
 		result, err := fn()
+
+		var promptErr *PromptError
+		if errors.As(err, &promptErr) {
+			addMissingConfigKey(configKey, promptErr)
+		}
 		if err != nil {
 			return err
 		}
 
 		viper.Set(configKey, result)
-		viper.WriteConfig()
+
+		// don't persist values derived from missing ones
+		if len(missingConfigKeys) == 0 {
+			WriteConfig()
+		}
 	}
 
 	return nil
 }
+
+// QueryNestedConfigKey reads a key nested in a config key which is stored as a
+// whole by QueryConfigKey, e.g. domainConfig.domain, into response. If it isn't
+// set, fn is called to ask for it. In non-interactive mode a missing key is
+// recorded for CheckMissingConfigKeys and nil is returned, so that the remaining
+// nested keys are checked as well.
+func QueryNestedConfigKey(configKey string, response interface{}, fn func() error) error {
+	bindEnv(configKey)
+
+	if viper.IsSet(configKey) {
+		return viper.UnmarshalKey(configKey, response)
+	}
+
+	err := fn()
+
+	var promptErr *PromptError
+	if errors.As(err, &promptErr) {
+		addMissingConfigKey(configKey, promptErr)
+		return nil
+	}
+
+	return err
+}
+
+// AskConfigKey asks like AskOne for a nested config key, see QueryNestedConfigKey.
+func AskConfigKey(configKey string, prompt survey.Prompt, response interface{}, tag string) error {
+	return QueryNestedConfigKey(configKey, response, func() error {
+		return AskOne(prompt, response, tag)
+	})
+}
+
+func addMissingConfigKey(configKey string, promptErr *PromptError) {
+	missingConfigKeys = append(missingConfigKeys, MissingConfigKey{
+		Key:       configKey,
+		Type:      promptErr.Type,
+		Validator: promptErr.Validator,
+		Message:   promptErr.Message,
+	})
+}
+
+// CheckMissingConfigKeys returns a *MissingConfigKeysError if any config key
+// couldn't be queried because prompts are disabled.
+func CheckMissingConfigKeys() error {
+	if len(missingConfigKeys) == 0 {
+		return nil
+	}
+
+	return &MissingConfigKeysError{Keys: missingConfigKeys}
+}
+
+// bindEnv makes a value set by environment variable visible to viper.Unmarshal,
+// which ignores variables only picked up by viper.AutomaticEnv.
+func bindEnv(configKey string) {
+	envKey := ENV_PREFIX + "_" + strings.ToUpper(strings.ReplaceAll(configKey, ".", "_"))
+	if _, ok := os.LookupEnv(envKey); ok {
+		_ = viper.BindEnv(configKey, envKey)
+	}
+}
@@ -0,0 +1,63 @@
+package common_test
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("QueryConfigKey", func() {
+	BeforeEach(func() {
+		configFile := path.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(configFile, []byte("domainConfig:\n  domain: gardener.example.org\n  provider: aws-route53\n"), 0600)).To(Succeed())
+
+		viper.Reset()
+		viper.SetConfigFile(configFile)
+		viper.SetEnvPrefix(common.ENV_PREFIX)
+		viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		viper.AutomaticEnv()
+		Expect(viper.ReadInConfig()).To(Succeed())
+	})
+
+	It("honors environment variables overriding keys nested in the queried one", func() {
+		GinkgoT().Setenv(common.ENV_PREFIX+"_DOMAINCONFIG_DOMAIN", "other.example.org")
+
+		Expect(common.QueryConfigKey("domainConfig", func() (any, error) {
+			Fail("domainConfig is set in the config file")
+			return nil, nil
+		})).To(Succeed())
+
+		var config struct {
+			DomainConfig struct {
+				Domain   string
+				Provider string
+			}
+		}
+		Expect(viper.Unmarshal(&config)).To(Succeed())
+		Expect(config.DomainConfig.Domain).To(Equal("other.example.org"))
+		Expect(config.DomainConfig.Provider).To(Equal("aws-route53"))
+	})
+
+	It("reads nested keys instead of asking", func() {
+		common.SetNonInteractive(true)
+		DeferCleanup(common.SetNonInteractive, false)
+		GinkgoT().Setenv(common.ENV_PREFIX+"_BACKUPCONFIG_REGION", "hel1")
+
+		var region, bucketName string
+		Expect(common.QueryNestedConfigKey("backupConfig.region", &region, func() error {
+			Fail("backupConfig.region is set by environment variable")
+			return nil
+		})).To(Succeed())
+		Expect(region).To(Equal("hel1"))
+
+		Expect(common.QueryNestedConfigKey("backupConfig.bucketName", &bucketName, func() error {
+			return &common.PromptError{Type: "string", Validator: "required", Message: "Define the name for the backup bucket."}
+		})).To(Succeed())
+		Expect(common.CheckMissingConfigKeys()).To(MatchError(ContainSubstring("backupConfig.bucketName (type: string, validate: required)")))
+	})
+})
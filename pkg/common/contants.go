@@ -6,8 +6,37 @@ const (
 	PROVIDER_GCP       = "provider-gcp"
 	PROVIDER_OPENSTACK = "provider-openstack"
 	PROVIDER_ALICLOUD  = "provider-alicloud"
+	PROVIDER_HCLOUD    = "provider-hcloud"
 )
 
+// providers of the base cluster, i.e. the seed's provider type
+const (
+	BASE_CLUSTER_PROVIDER_HCLOUD    = "hcloud"
+	BASE_CLUSTER_PROVIDER_AZURE     = "azure"
+	BASE_CLUSTER_PROVIDER_AWS       = "aws"
+	BASE_CLUSTER_PROVIDER_OPENSTACK = "openstack"
+	BASE_CLUSTER_PROVIDER_GCP       = "gcp"
+	BASE_CLUSTER_PROVIDER_ALICLOUD  = "alicloud"
+)
+
+var BASE_CLUSTER_PROVIDERS = []string{
+	BASE_CLUSTER_PROVIDER_HCLOUD,
+	BASE_CLUSTER_PROVIDER_AZURE,
+	BASE_CLUSTER_PROVIDER_AWS,
+	BASE_CLUSTER_PROVIDER_OPENSTACK,
+	BASE_CLUSTER_PROVIDER_GCP,
+	BASE_CLUSTER_PROVIDER_ALICLOUD,
+}
+
+var BASE_CLUSTER_PROVIDER_TO_PROVIDER = map[string]string{
+	BASE_CLUSTER_PROVIDER_HCLOUD:    PROVIDER_HCLOUD,
+	BASE_CLUSTER_PROVIDER_AZURE:     PROVIDER_AZURE,
+	BASE_CLUSTER_PROVIDER_AWS:       PROVIDER_AWS,
+	BASE_CLUSTER_PROVIDER_OPENSTACK: PROVIDER_OPENSTACK,
+	BASE_CLUSTER_PROVIDER_GCP:       PROVIDER_GCP,
+	BASE_CLUSTER_PROVIDER_ALICLOUD:  PROVIDER_ALICLOUD,
+}
+
 const (
 	DNS_PROVIDER_AWS_ROUTE_53        = "aws-route53"
 	DNS_PROVIDER_AZURE_DNS           = "azure-dns"
@@ -18,9 +47,22 @@ const (
 )
 
 const (
-	BUCKET_PROVIDER_AZURE = "azure"
+	BUCKET_PROVIDER_AZURE     = "azure"
+	BUCKET_PROVIDER_AWS       = "aws"
+	BUCKET_PROVIDER_GCP       = "gcp"
+	BUCKET_PROVIDER_OPENSTACK = "openstack"
+	// S3 compatible object storage, e.g. MinIO
+	BUCKET_PROVIDER_S3 = "s3"
 )
 
+var BUCKET_PROVIDER_TO_PROVIDER = map[string]string{
+	BUCKET_PROVIDER_AZURE:     PROVIDER_AZURE,
+	BUCKET_PROVIDER_AWS:       PROVIDER_AWS,
+	BUCKET_PROVIDER_GCP:       PROVIDER_GCP,
+	BUCKET_PROVIDER_OPENSTACK: PROVIDER_OPENSTACK,
+	BUCKET_PROVIDER_S3:        PROVIDER_AWS,
+}
+
 var DNS_PROVIDER_TO_PROVIDER = map[string]string{
 	DNS_PROVIDER_AWS_ROUTE_53:        PROVIDER_AWS,
 	DNS_PROVIDER_AZURE_DNS:           PROVIDER_AZURE,
@@ -39,3 +81,11 @@ const CONFIG_23KE_GITREPO_KEY = "23ke-config-key"
 
 const BUCKET_SECRET_NAME = "bucket-credentials"
 const BUCKET_NAME = "23ke"
+
+const CONFIG_23KE_SECRET_NAME = "23ke-config"
+
+// SOPS_AGE_SECRET_NAME holds the age key flux decrypts the secrets in the config repo with.
+const SOPS_AGE_SECRET_NAME = "sops-age"
+
+// GARDENER_KUBECONFIG_SECRET_NAME holds the kubeconfig of the virtual garden.
+const GARDENER_KUBECONFIG_SECRET_NAME = "gardener-internal-kubeconfig"
@@ -0,0 +1,42 @@
+package common
+
+import "time"
+
+// DEFAULT_KEY_WAIT_TIMEOUT is how long to wait for a deploy key to be granted
+// access to the config repo in non-interactive mode.
+const DEFAULT_KEY_WAIT_TIMEOUT = 10 * time.Minute
+
+// PUBLIC_KEY_LINE_PREFIX marks the public deploy key when it is written to
+// stdout, so it can be told apart from the progress output of the installation.
+const PUBLIC_KEY_LINE_PREFIX = "23kectl-deploy-key: "
+
+var keyWaitTimeout time.Duration
+var publicKeyFile string
+
+// SetKeyWaitTimeout makes the installation poll until the deploy key can read
+// the config repo instead of asking for confirmation. 0 disables polling,
+// unless running in non-interactive mode.
+func SetKeyWaitTimeout(timeout time.Duration) {
+	keyWaitTimeout = timeout
+}
+
+// KeyWaitTimeout returns how long to poll for the deploy key to be granted
+// access. 0 means asking for confirmation instead.
+func KeyWaitTimeout() time.Duration {
+	if keyWaitTimeout == 0 && nonInteractive {
+		return DEFAULT_KEY_WAIT_TIMEOUT
+	}
+
+	return keyWaitTimeout
+}
+
+// SetPublicKeyFile sets a file the public deploy key is written to, so it can
+// be registered by an outer automation. "-" writes it to stdout on a line
+// starting with PUBLIC_KEY_LINE_PREFIX.
+func SetPublicKeyFile(path string) {
+	publicKeyFile = path
+}
+
+func PublicKeyFile() string {
+	return publicKeyFile
+}
@@ -20,10 +20,9 @@ func CreateMinioClient() (*minio.Client, error) {
 
 	if err != nil {
 		return nil, fmt.Errorf(
-			"couldn't create minio client (endpoint '%s', accesskey: '%s', secretkey: '%s', secure: %t) %w",
+			"couldn't create minio client (endpoint '%s', accesskey: '%s', secure: %t) %w",
 			endpoint,
 			accessKeyID,
-			secretAccessKey,
 			secure,
 			err)
 	}
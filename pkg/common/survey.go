@@ -0,0 +1,108 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// PromptError is returned instead of prompting when running in non-interactive mode.
+// It describes the answer that would have been expected.
+type PromptError struct {
+	Type      string
+	Validator string
+	Message   string
+}
+
+func (e *PromptError) Error() string {
+	return fmt.Sprintf("can't prompt in non-interactive mode: %s", e.Message)
+}
+
+// AskOne wraps survey.AskOne. The answer is validated with the given validator tag, if any.
+// In non-interactive mode it returns a *PromptError instead of prompting.
+func AskOne(prompt survey.Prompt, response interface{}, tag string) error {
+	if nonInteractive {
+		return &PromptError{
+			Type:      describeType(prompt, response),
+			Validator: tag,
+			Message:   describePrompt(prompt),
+		}
+	}
+
+	if tag == "" {
+		return survey.AskOne(prompt, response)
+	}
+
+	return survey.AskOne(prompt, response, WithValidator(tag))
+}
+
+// Ask wraps survey.Ask for the keys nested in configKey, e.g. the credentials
+// in domainConfig.credentials. Each question is asked for the field of response
+// with the question's name, unless the config key named by the field's yaml tag
+// is set already, see QueryNestedConfigKey. In non-interactive mode the missing
+// keys are described by the fields' validate tags.
+func Ask(configKey string, qs []*survey.Question, response interface{}) error {
+	responseType := reflect.TypeOf(response).Elem()
+	responseValue := reflect.ValueOf(response).Elem()
+
+	for _, q := range qs {
+		name := q.Name
+		field, ok := responseType.FieldByNameFunc(func(fieldName string) bool {
+			return strings.EqualFold(fieldName, name)
+		})
+		if !ok {
+			return fmt.Errorf("%s has no field %s", responseType, q.Name)
+		}
+
+		nestedKey := configKey + "." + strings.Split(field.Tag.Get("yaml"), ",")[0]
+		fieldPtr := responseValue.FieldByIndex(field.Index).Addr().Interface()
+
+		question := q
+		err := QueryNestedConfigKey(nestedKey, fieldPtr, func() error {
+			if nonInteractive {
+				return &PromptError{
+					Type:      field.Type.Kind().String(),
+					Validator: field.Tag.Get("validate"),
+					Message:   describePrompt(question.Prompt),
+				}
+			}
+
+			return survey.Ask([]*survey.Question{question}, response)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func describeType(prompt survey.Prompt, response interface{}) string {
+	typeName := reflect.TypeOf(response).Elem().Kind().String()
+
+	if sel, ok := prompt.(*survey.Select); ok {
+		return fmt.Sprintf("%s, one of %s", typeName, strings.Join(sel.Options, ", "))
+	}
+
+	return typeName
+}
+
+func describePrompt(prompt survey.Prompt) string {
+	var message string
+
+	switch p := prompt.(type) {
+	case *survey.Input:
+		message = p.Message
+	case *survey.Password:
+		message = p.Message
+	case *survey.Select:
+		message = p.Message
+	case *survey.Confirm:
+		message = p.Message
+	}
+
+	// multi-line messages carry explanations, the first line is enough here
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
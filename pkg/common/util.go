@@ -58,9 +58,13 @@ func RandHex(bytes int) string {
 }
 
 func ExitOnCtrlC(err error) {
+	var promptErr *PromptError
 	if errors.Is(err, terminal.InterruptErr) {
 		fmt.Println("Ctrl+C, exiting.")
 		os.Exit(1)
+	} else if errors.As(err, &promptErr) {
+		// not a failure, the caller passes it up so the missing config key can be reported
+		return
 	} else if err != nil {
 		panic(err)
 	}
@@ -1,13 +1,71 @@
 package common
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/go-playground/validator/v10"
 )
 
+// BASE_CLUSTER_REGION_PATTERNS match the region names of the base cluster
+// providers. OpenStack regions are named freely, so they aren't checked.
+var BASE_CLUSTER_REGION_PATTERNS = map[string]*regexp.Regexp{
+	// e.g. fsn1, nbg1, hel1, ash
+	BASE_CLUSTER_PROVIDER_HCLOUD: regexp.MustCompile(`^[a-z]{3}[0-9]*$`),
+	// e.g. westeurope, germanywestcentral, eastus2
+	BASE_CLUSTER_PROVIDER_AZURE: regexp.MustCompile(`^[a-z]+[0-9]*$`),
+	// e.g. eu-central-1, us-gov-west-1
+	BASE_CLUSTER_PROVIDER_AWS: regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`),
+	// e.g. europe-west3, us-central1
+	BASE_CLUSTER_PROVIDER_GCP: regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+$`),
+	// e.g. eu-central-1, cn-hangzhou, cn-shanghai-finance-1
+	BASE_CLUSTER_PROVIDER_ALICLOUD: regexp.MustCompile(`^[a-z]{2}-[a-z]+(-[a-z]+)*(-[0-9]+)?$`),
+}
+
+// IsValidRegion reports whether region is a region name of the given base
+// cluster provider. Regions of unknown providers are always valid.
+func IsValidRegion(provider string, region string) bool {
+	pattern, ok := BASE_CLUSTER_REGION_PATTERNS[provider]
+	if !ok {
+		return region != ""
+	}
+
+	return pattern.MatchString(region)
+}
+
+// newValidator returns a validator knowing the custom rules of 23kectl:
+//   - gitremote: an ssh:// or https:// git remote
+//   - region=<provider>: a region of the given base cluster provider
+//   - encodedurl: a base64 encoded URL, the way the wizard stores credentials
+func newValidator() *validator.Validate {
+	vtor := validator.New()
+
+	_ = vtor.RegisterValidation("gitremote", func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		return strings.HasPrefix(value, "ssh://") || strings.HasPrefix(value, "https://")
+	})
+
+	_ = vtor.RegisterValidation("region", func(fl validator.FieldLevel) bool {
+		return IsValidRegion(fl.Param(), fl.Field().String())
+	})
+
+	_ = vtor.RegisterValidation("encodedurl", func(fl validator.FieldLevel) bool {
+		decoded, err := base64.StdEncoding.DecodeString(fl.Field().String())
+		return err == nil && vtor.Var(string(decoded), "url") == nil
+	})
+
+	return vtor
+}
+
 // Available validators: https://pkg.go.dev/github.com/go-playground/validator/v10
+// and the custom ones of newValidator
 func MakeValidatorFn(tag string) func(value interface{}) error {
-	vtor := validator.New()
+	vtor := newValidator()
 
 	return func(value interface{}) error {
 		return vtor.Var(value, tag)
@@ -17,3 +75,60 @@ func MakeValidatorFn(tag string) func(value interface{}) error {
 func WithValidator(tag string) survey.AskOpt {
 	return survey.WithValidator(MakeValidatorFn(tag))
 }
+
+// ValidationError describes a single violation of a validator tag.
+// Path is the YAML path of the offending value.
+type ValidationError struct {
+	Path  string
+	Tag   string
+	Value interface{}
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: value '%v' violates '%s'", e.Path, e.Value, e.Tag)
+}
+
+// ValidateStruct validates s by its `validate` struct tags and returns all violations.
+// Paths are built from the `yaml` struct tags and prefixed with pathPrefix.
+func ValidateStruct(s interface{}, pathPrefix string) ([]ValidationError, error) {
+	vtor := newValidator()
+	vtor.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
+	err := vtor.Struct(s)
+	if err == nil {
+		return nil, nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil, err
+	}
+
+	var result []ValidationError
+	for _, fieldErr := range fieldErrs {
+		// the namespace starts with the name of the validated struct's type
+		path := strings.SplitN(fieldErr.Namespace(), ".", 2)[1]
+		if pathPrefix != "" {
+			path = pathPrefix + "." + path
+		}
+
+		tag := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			tag += "=" + fieldErr.Param()
+		}
+
+		result = append(result, ValidationError{
+			Path:  path,
+			Tag:   tag,
+			Value: fieldErr.Value(),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package common_test
+
+import (
+	"github.com/23technologies/23kectl/pkg/common"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("region validation", func() {
+	DescribeTable("accepts the regions of the provider",
+		func(provider string, region string) {
+			Expect(common.IsValidRegion(provider, region)).To(BeTrue())
+			Expect(common.MakeValidatorFn("region=" + provider)(region)).To(Succeed())
+		},
+		Entry("hcloud", "hcloud", "fsn1"),
+		Entry("azure", "azure", "germanywestcentral"),
+		Entry("aws", "aws", "eu-central-1"),
+		Entry("aws GovCloud", "aws", "us-gov-west-1"),
+		Entry("gcp", "gcp", "europe-west3"),
+		Entry("alicloud", "alicloud", "eu-central-1"),
+		Entry("alicloud without a number", "alicloud", "cn-hangzhou"),
+		Entry("openstack", "openstack", "RegionOne"),
+	)
+
+	DescribeTable("rejects regions of other providers and zones",
+		func(provider string, region string) {
+			Expect(common.IsValidRegion(provider, region)).To(BeFalse())
+			Expect(common.MakeValidatorFn("region=" + provider)(region)).NotTo(Succeed())
+		},
+		Entry("gcp zone", "gcp", "europe-west3-a"),
+		Entry("aws region on gcp", "gcp", "eu-central-1"),
+		Entry("gcp region on aws", "aws", "europe-west3"),
+		Entry("aws zone", "aws", "eu-central-1a"),
+		Entry("alicloud zone", "alicloud", "eu-central-1a"),
+		Entry("azure display name", "azure", "Germany West Central"),
+		Entry("empty openstack region", "openstack", ""),
+	)
+})
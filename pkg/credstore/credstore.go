@@ -0,0 +1,89 @@
+// Package credstore keeps sensitive config values, e.g. the credentials of DNS
+// and backup providers, out of the config file. They are stored in the keyring
+// of the OS or, if there is none, in a file encrypted with a passphrase.
+package credstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// supported kinds of credential stores
+const (
+	// KindAuto uses the keyring if one is available and the file otherwise
+	KindAuto    = "auto"
+	KindKeyring = "keyring"
+	KindFile    = "file"
+)
+
+// SERVICE_NAME is the service values are stored for in the keyring.
+const SERVICE_NAME = "23kectl"
+
+// ErrNotFound is returned by Get if there is no value for the key.
+var ErrNotFound = errors.New("not found in the credential store")
+
+// Store is a credential store.
+type Store interface {
+	Get(key string) (string, error)
+	Set(key string, value string) error
+	Delete(key string) error
+	// String describes where the values are stored
+	String() string
+}
+
+// Options configure the file store.
+type Options struct {
+	// File is the path of the encrypted file, DefaultFile() if empty
+	File string
+	// Passphrase returns the passphrase of the file, it's called once at most
+	Passphrase func() (string, error)
+}
+
+// Open returns the credential store of the given kind.
+func Open(kind string, opts Options) (Store, error) {
+	switch kind {
+	case KindAuto, "":
+		if KeyringAvailable() {
+			return NewKeyring(), nil
+		}
+		return openFile(opts)
+	case KindKeyring:
+		if !KeyringAvailable() {
+			return nil, errors.New("there is no keyring available, use the file credential store instead")
+		}
+		return NewKeyring(), nil
+	case KindFile:
+		return openFile(opts)
+	default:
+		return nil, fmt.Errorf("unknown credential store '%s', use one of %s, %s or %s", kind, KindAuto, KindKeyring, KindFile)
+	}
+}
+
+// DefaultFile returns the path of the encrypted file in the user's config directory.
+func DefaultFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "23kectl", "credentials.age"), nil
+}
+
+func openFile(opts Options) (Store, error) {
+	path := opts.File
+	if path == "" {
+		var err error
+		path, err = DefaultFile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Passphrase == nil {
+		return nil, errors.New("the file credential store needs a passphrase")
+	}
+
+	return NewFile(path, opts.Passphrase), nil
+}
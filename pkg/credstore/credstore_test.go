@@ -0,0 +1,103 @@
+package credstore_test
+
+import (
+	"os"
+	"path"
+
+	"github.com/23technologies/23kectl/pkg/credstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/zalando/go-keyring"
+)
+
+var _ = Describe("file store", func() {
+	var file string
+	var asked int
+
+	passphrase := func(pass string) func() (string, error) {
+		return func() (string, error) {
+			asked++
+			return pass, nil
+		}
+	}
+
+	BeforeEach(func() {
+		file = path.Join(GinkgoT().TempDir(), "23kectl", "credentials.age")
+		asked = 0
+	})
+
+	It("reports missing values", func() {
+		store := credstore.NewFile(file, passphrase("my-passphrase"))
+
+		_, err := store.Get("bucket.secretkey")
+		Expect(err).To(MatchError(credstore.ErrNotFound))
+		Expect(asked).To(Equal(0))
+	})
+
+	It("keeps values encrypted", func() {
+		store := credstore.NewFile(file, passphrase("my-passphrase"))
+		Expect(store.Set("bucket.secretkey", "my-secret-key")).To(Succeed())
+		Expect(store.Set("admin.gitToken", "my-token")).To(Succeed())
+		Expect(asked).To(Equal(1))
+
+		content, err := os.ReadFile(file)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(HavePrefix("-----BEGIN AGE ENCRYPTED FILE-----"))
+		Expect(string(content)).NotTo(ContainSubstring("my-secret-key"))
+
+		info, err := os.Stat(file)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		reopened := credstore.NewFile(file, passphrase("my-passphrase"))
+		Expect(reopened.Get("bucket.secretkey")).To(Equal("my-secret-key"))
+		Expect(reopened.Get("admin.gitToken")).To(Equal("my-token"))
+
+		Expect(reopened.Delete("admin.gitToken")).To(Succeed())
+		_, err = credstore.NewFile(file, passphrase("my-passphrase")).Get("admin.gitToken")
+		Expect(err).To(MatchError(credstore.ErrNotFound))
+	})
+
+	It("refuses a wrong passphrase", func() {
+		Expect(credstore.NewFile(file, passphrase("my-passphrase")).Set("bucket.secretkey", "my-secret-key")).To(Succeed())
+
+		_, err := credstore.NewFile(file, passphrase("wrong")).Get("bucket.secretkey")
+		Expect(err).To(MatchError(ContainSubstring("is the passphrase correct?")))
+	})
+
+	It("refuses an empty passphrase", func() {
+		err := credstore.NewFile(file, passphrase("")).Set("bucket.secretkey", "my-secret-key")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Open", func() {
+	It("uses the keyring if available", func() {
+		keyring.MockInit()
+
+		store, err := credstore.Open(credstore.KindAuto, credstore.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.String()).To(ContainSubstring("keyring"))
+
+		Expect(store.Set("bucket.secretkey", "my-secret-key")).To(Succeed())
+		Expect(store.Get("bucket.secretkey")).To(Equal("my-secret-key"))
+		Expect(store.Delete("bucket.secretkey")).To(Succeed())
+		_, err = store.Get("bucket.secretkey")
+		Expect(err).To(MatchError(credstore.ErrNotFound))
+	})
+
+	It("uses the file if asked to", func() {
+		file := path.Join(GinkgoT().TempDir(), "credentials.age")
+		store, err := credstore.Open(credstore.KindFile, credstore.Options{
+			File:       file,
+			Passphrase: func() (string, error) { return "my-passphrase", nil },
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.String()).To(Equal(file))
+	})
+
+	It("rejects unknown kinds", func() {
+		_, err := credstore.Open("vault", credstore.Options{})
+		Expect(err).To(MatchError(ContainSubstring("unknown credential store")))
+	})
+})
@@ -0,0 +1,177 @@
+package credstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+type fileStore struct {
+	path       string
+	passphrase func() (string, error)
+
+	// cached, so the passphrase is asked for and the file is decrypted once only
+	pass   string
+	values map[string]string
+}
+
+// NewFile returns a store backed by a file encrypted with an age passphrase.
+func NewFile(path string, passphrase func() (string, error)) Store {
+	return &fileStore{path: path, passphrase: passphrase}
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := s.values[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (s *fileStore) Set(key string, value string) error {
+	err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if current, ok := s.values[key]; ok && current == value {
+		return nil
+	}
+	s.values[key] = value
+
+	return s.save()
+}
+
+func (s *fileStore) Delete(key string) error {
+	err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.values[key]; !ok {
+		return nil
+	}
+	delete(s.values, key)
+
+	return s.save()
+}
+
+func (s *fileStore) String() string {
+	return s.path
+}
+
+func (s *fileStore) getPassphrase() (string, error) {
+	if s.pass == "" {
+		pass, err := s.passphrase()
+		if err != nil {
+			return "", err
+		}
+		if pass == "" {
+			return "", errors.New("the passphrase of the credential store must not be empty")
+		}
+		s.pass = pass
+	}
+
+	return s.pass, nil
+}
+
+func (s *fileStore) load() error {
+	if s.values != nil {
+		return nil
+	}
+
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.values = map[string]string{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pass, err := s.getPassphrase()
+	if err != nil {
+		return err
+	}
+
+	identity, err := age.NewScryptIdentity(pass)
+	if err != nil {
+		return err
+	}
+
+	reader, err := age.Decrypt(armor.NewReader(bytes.NewReader(content)), identity)
+	if err != nil {
+		return fmt.Errorf("couldn't decrypt the credential store %s, is the passphrase correct? %w", s.path, err)
+	}
+
+	plain, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{}
+	err = json.Unmarshal(plain, &values)
+	if err != nil {
+		return fmt.Errorf("the credential store %s is corrupt: %w", s.path, err)
+	}
+	s.values = values
+
+	return nil
+}
+
+func (s *fileStore) save() error {
+	pass, err := s.getPassphrase()
+	if err != nil {
+		return err
+	}
+
+	recipient, err := age.NewScryptRecipient(pass)
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+
+	var encrypted bytes.Buffer
+	armorWriter := armor.NewWriter(&encrypted)
+	writer, err := age.Encrypt(armorWriter, recipient)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(plain)
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	err = armorWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, encrypted.Bytes(), 0600)
+}
@@ -0,0 +1,48 @@
+package credstore
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+type keyringStore struct{}
+
+// NewKeyring returns a store backed by the keyring of the OS, i.e. the Secret
+// Service on Linux, the Keychain on macOS and the Credential Manager on Windows.
+func NewKeyring() Store {
+	return &keyringStore{}
+}
+
+// KeyringAvailable reports whether the keyring of the OS can be used. On Linux
+// it's missing without a D-Bus session, e.g. on servers and in CI.
+func KeyringAvailable() bool {
+	_, err := keyring.Get(SERVICE_NAME, "23kectl-probe")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (s *keyringStore) Get(key string) (string, error) {
+	value, err := keyring.Get(SERVICE_NAME, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+
+	return value, err
+}
+
+func (s *keyringStore) Set(key string, value string) error {
+	return keyring.Set(SERVICE_NAME, key, value)
+}
+
+func (s *keyringStore) Delete(key string) error {
+	err := keyring.Delete(SERVICE_NAME, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *keyringStore) String() string {
+	return "the keyring of your OS"
+}
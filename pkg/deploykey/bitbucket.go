@@ -0,0 +1,55 @@
+package deploykey
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// bitbucket registers deploy keys via the Bitbucket Cloud 2.0 API using a
+// repository or workspace access token. Bitbucket deploy keys are always
+// read-only, so ReadOnly is ignored.
+type bitbucket struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+func (b *bitbucket) Register(ctx context.Context, repo *Repo, key Key) error {
+	endpoint := fmt.Sprintf("%s/2.0/repositories/%s/%s/deploy-keys", b.apiURL, repo.Owner(), repo.Name())
+
+	return postJSON(ctx, b.client, endpoint, http.Header{"Authorization": {"Bearer " + b.token}}, map[string]interface{}{
+		"label": key.Title,
+		"key":   key.PublicKey,
+	})
+}
+
+func (b *bitbucket) Deregister(ctx context.Context, repo *Repo, publicKey string) error {
+	endpoint := fmt.Sprintf("%s/2.0/repositories/%s/%s/deploy-keys", b.apiURL, repo.Owner(), repo.Name())
+	header := http.Header{"Authorization": {"Bearer " + b.token}}
+
+	for next := endpoint; next != ""; {
+		page := struct {
+			Values []struct {
+				ID  int    `json:"id"`
+				Key string `json:"key"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}{}
+
+		_, err := getJSON(ctx, b.client, next, header, &page)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range page.Values {
+			if sameKey(key.Key, publicKey) {
+				return deleteRequest(ctx, b.client, fmt.Sprintf("%s/%d", endpoint, key.ID), header)
+			}
+		}
+
+		next = page.Next
+	}
+
+	return nil
+}
@@ -0,0 +1,288 @@
+// Package deploykey registers deploy keys for the config repository with the
+// API of its git hosting provider, so they don't have to be added by hand.
+package deploykey
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// supported git hosting providers
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderGitea     = "gitea"
+	ProviderBitbucket = "bitbucket"
+)
+
+// Repo is a repository on a git hosting provider.
+type Repo struct {
+	// Host is the host name of the repo URL without port
+	Host string
+	// Path is the full path of the repository without the .git suffix,
+	// e.g. "group/subgroup/repo"
+	Path string
+}
+
+// Owner returns the user, organization or (nested) group owning the repo.
+func (r *Repo) Owner() string {
+	return r.Path[:strings.LastIndex(r.Path, "/")]
+}
+
+// Name returns the name of the repo without its owner.
+func (r *Repo) Name() string {
+	return r.Path[strings.LastIndex(r.Path, "/")+1:]
+}
+
+// ParseRepoURL parses ssh, https and scp-like (git@host:owner/repo.git) repo URLs.
+func ParseRepoURL(rawURL string) (*Repo, error) {
+	var host, path string
+
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax, e.g. git@github.com:owner/repo.git
+		hostPart, pathPart, found := strings.Cut(rawURL, ":")
+		if !found {
+			return nil, fmt.Errorf("couldn't parse repo URL %s", rawURL)
+		}
+		if i := strings.LastIndex(hostPart, "@"); i >= 0 {
+			hostPart = hostPart[i+1:]
+		}
+		host, path = hostPart, pathPart
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return nil, fmt.Errorf("repo URL %s doesn't contain a host, an owner and a repository name", rawURL)
+	}
+
+	return &Repo{Host: host, Path: path}, nil
+}
+
+// Key is a deploy key to be registered.
+type Key struct {
+	Title     string
+	PublicKey string
+	ReadOnly  bool
+}
+
+// Registrar registers deploy keys with a git hosting provider. Registering a
+// key which is already registered isn't an error, neither is deregistering a
+// key which isn't registered.
+type Registrar interface {
+	Register(ctx context.Context, repo *Repo, key Key) error
+	// Deregister removes the deploy key with the given public key in
+	// authorized_keys format. Comments are ignored.
+	Deregister(ctx context.Context, repo *Repo, publicKey string) error
+}
+
+// Options configure a Registrar.
+type Options struct {
+	// Provider is one of the supported providers. It is detected from the repo
+	// host if empty.
+	Provider string
+	// APIURL is the base URL of the provider's API. It defaults to the public
+	// API of the provider or, for self-hosted instances, to https://<host>.
+	APIURL string
+	// Token authenticates against the API. It is read from the provider's
+	// environment variable if empty, see TokenEnv.
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New returns a Registrar for repo. It returns an error if the provider can't
+// be detected or no token is available.
+func New(repo *Repo, opts Options) (Registrar, error) {
+	provider := opts.Provider
+	if provider == "" {
+		provider = DetectProvider(repo.Host)
+	}
+	if provider == "" {
+		return nil, fmt.Errorf("couldn't detect the git provider of %s, please configure it", repo.Host)
+	}
+
+	token := opts.Token
+	if token == "" {
+		for _, env := range TokenEnv(provider) {
+			token = os.Getenv(env)
+			if token != "" {
+				break
+			}
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no API token for %s, set one of %s", provider, strings.Join(TokenEnv(provider), ", "))
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	apiURL := strings.TrimSuffix(opts.APIURL, "/")
+
+	switch provider {
+	case ProviderGitHub:
+		return newGitHub(repo, apiURL, token, client)
+	case ProviderGitLab:
+		if apiURL == "" {
+			apiURL = "https://" + repo.Host
+		}
+		return &gitLab{apiURL: apiURL, token: token, client: client}, nil
+	case ProviderGitea:
+		if apiURL == "" {
+			apiURL = "https://" + repo.Host
+		}
+		return &gitea{apiURL: apiURL, token: token, client: client}, nil
+	case ProviderBitbucket:
+		if apiURL == "" {
+			apiURL = "https://api.bitbucket.org"
+		}
+		return &bitbucket{apiURL: apiURL, token: token, client: client}, nil
+	}
+
+	return nil, fmt.Errorf("unknown git provider %s", provider)
+}
+
+// DetectProvider guesses the provider from the host of a repo URL.
+// It returns an empty string if the host is unknown.
+func DetectProvider(host string) string {
+	host = strings.ToLower(host)
+
+	switch {
+	case host == "github.com" || strings.Contains(host, "github"):
+		return ProviderGitHub
+	case host == "bitbucket.org":
+		return ProviderBitbucket
+	case strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	case strings.Contains(host, "gitea") || host == "codeberg.org":
+		return ProviderGitea
+	}
+
+	return ""
+}
+
+// TokenEnv returns the environment variables holding an API token for provider.
+func TokenEnv(provider string) []string {
+	switch provider {
+	case ProviderGitHub:
+		return []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	case ProviderGitLab:
+		return []string{"GITLAB_TOKEN"}
+	case ProviderGitea:
+		return []string{"GITEA_TOKEN"}
+	case ProviderBitbucket:
+		return []string{"BITBUCKET_TOKEN"}
+	}
+
+	return nil
+}
+
+// postJSON posts body to url. Responses telling that the key is already in use
+// are treated as success.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	if res.StatusCode < 500 && isAlreadyRegistered(string(msg)) {
+		return nil
+	}
+
+	return fmt.Errorf("POST %s: %s: %s", url, res.Status, strings.TrimSpace(string(msg)))
+}
+
+func isAlreadyRegistered(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "already in use") ||
+		strings.Contains(msg, "already been taken") ||
+		strings.Contains(msg, "already exists")
+}
+
+// getJSON decodes the response of a GET request into v and returns the response headers.
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header, v interface{}) (http.Header, error) {
+	res, err := do(ctx, client, http.MethodGet, url, header)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	return res.Header, json.NewDecoder(res.Body).Decode(v)
+}
+
+func deleteRequest(ctx context.Context, client *http.Client, url string, header http.Header) error {
+	res, err := do(ctx, client, http.MethodDelete, url, header)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+func do(ctx context.Context, client *http.Client, method string, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, url, res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return res, nil
+}
+
+// sameKey compares two public keys in authorized_keys format, ignoring comments.
+func sameKey(a, b string) bool {
+	fieldsA, fieldsB := strings.Fields(a), strings.Fields(b)
+	if len(fieldsA) < 2 || len(fieldsB) < 2 {
+		return false
+	}
+
+	return fieldsA[0] == fieldsB[0] && fieldsA[1] == fieldsB[1]
+}
@@ -0,0 +1,198 @@
+package deploykey_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/23technologies/23kectl/pkg/deploykey"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// fakeAPI records every request and answers with the given status and body.
+func fakeAPI(status int, body string) (*httptest.Server, *[]request) {
+	requests := &[]request{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		req := request{Method: r.Method, Path: r.URL.EscapedPath(), Header: r.Header}
+		_ = json.Unmarshal(payload, &req.Body)
+		*requests = append(*requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	DeferCleanup(server.Close)
+
+	return server, requests
+}
+
+var key = deploykey.Key{Title: "23ke-config", PublicKey: "ssh-ed25519 AAAA", ReadOnly: false}
+
+var _ = Describe("ParseRepoURL", func() {
+	DescribeTable("parses",
+		func(rawURL, host, path, owner, name string) {
+			repo, err := deploykey.ParseRepoURL(rawURL)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.Host).To(Equal(host))
+			Expect(repo.Path).To(Equal(path))
+			Expect(repo.Owner()).To(Equal(owner))
+			Expect(repo.Name()).To(Equal(name))
+		},
+		Entry("ssh URLs", "ssh://git@github.com/owner/repo.git", "github.com", "owner/repo", "owner", "repo"),
+		Entry("ssh URLs with port", "ssh://git@git.example.com:2222/owner/repo", "git.example.com", "owner/repo", "owner", "repo"),
+		Entry("nested groups", "ssh://git@gitlab.example.com/group/sub/repo.git", "gitlab.example.com", "group/sub/repo", "group/sub", "repo"),
+		Entry("https URLs", "https://gitea.example.com:3000/owner/repo.git", "gitea.example.com", "owner/repo", "owner", "repo"),
+		Entry("scp-like URLs", "git@bitbucket.org:workspace/repo.git", "bitbucket.org", "workspace/repo", "workspace", "repo"),
+	)
+
+	It("rejects URLs without owner", func() {
+		_, err := deploykey.ParseRepoURL("ssh://git@github.com/repo.git")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("DetectProvider", func() {
+	DescribeTable("detects",
+		func(host, provider string) {
+			Expect(deploykey.DetectProvider(host)).To(Equal(provider))
+		},
+		Entry("GitHub", "github.com", deploykey.ProviderGitHub),
+		Entry("GitLab", "gitlab.example.com", deploykey.ProviderGitLab),
+		Entry("Gitea", "gitea.example.com", deploykey.ProviderGitea),
+		Entry("Bitbucket", "bitbucket.org", deploykey.ProviderBitbucket),
+		Entry("nothing for unknown hosts", "git.example.com", ""),
+	)
+})
+
+var _ = Describe("Registrar", func() {
+	register := func(provider, apiURL, rawURL string) error {
+		repo, err := deploykey.ParseRepoURL(rawURL)
+		Expect(err).NotTo(HaveOccurred())
+
+		registrar, err := deploykey.New(repo, deploykey.Options{Provider: provider, APIURL: apiURL, Token: "token"})
+		Expect(err).NotTo(HaveOccurred())
+
+		return registrar.Register(context.Background(), repo, key)
+	}
+
+	It("registers keys with GitHub", func() {
+		server, requests := fakeAPI(http.StatusCreated, `{}`)
+
+		Expect(register(deploykey.ProviderGitHub, server.URL, "ssh://git@github.example.com/owner/repo.git")).To(Succeed())
+		Expect(*requests).To(HaveLen(1))
+		Expect((*requests)[0].Path).To(Equal("/api/v3/repos/owner/repo/keys"))
+		Expect((*requests)[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+		Expect((*requests)[0].Body).To(HaveKeyWithValue("key", key.PublicKey))
+		Expect((*requests)[0].Body).To(HaveKeyWithValue("read_only", false))
+	})
+
+	It("registers keys with GitLab for projects in nested groups", func() {
+		server, requests := fakeAPI(http.StatusCreated, `{}`)
+
+		Expect(register(deploykey.ProviderGitLab, server.URL, "ssh://git@gitlab.example.com:2222/group/sub/repo.git")).To(Succeed())
+		Expect(*requests).To(HaveLen(1))
+		Expect((*requests)[0].Path).To(Equal("/api/v4/projects/group%2Fsub%2Frepo/deploy_keys"))
+		Expect((*requests)[0].Header.Get("Private-Token")).To(Equal("token"))
+		Expect((*requests)[0].Body).To(HaveKeyWithValue("can_push", true))
+	})
+
+	It("registers keys with Gitea", func() {
+		server, requests := fakeAPI(http.StatusCreated, `{}`)
+
+		Expect(register(deploykey.ProviderGitea, server.URL, "ssh://git@gitea.example.com/owner/repo.git")).To(Succeed())
+		Expect(*requests).To(HaveLen(1))
+		Expect((*requests)[0].Path).To(Equal("/api/v1/repos/owner/repo/keys"))
+		Expect((*requests)[0].Header.Get("Authorization")).To(Equal("token token"))
+		Expect((*requests)[0].Body).To(HaveKeyWithValue("read_only", false))
+	})
+
+	It("registers keys with Bitbucket", func() {
+		server, requests := fakeAPI(http.StatusOK, `{}`)
+
+		Expect(register(deploykey.ProviderBitbucket, server.URL, "git@bitbucket.org:workspace/repo.git")).To(Succeed())
+		Expect(*requests).To(HaveLen(1))
+		Expect((*requests)[0].Path).To(Equal("/2.0/repositories/workspace/repo/deploy-keys"))
+		Expect((*requests)[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+		Expect((*requests)[0].Body).To(HaveKeyWithValue("label", key.Title))
+	})
+
+	It("accepts keys which are already registered", func() {
+		server, _ := fakeAPI(http.StatusBadRequest, `{"message":{"fingerprint":["has already been taken"]}}`)
+
+		Expect(register(deploykey.ProviderGitLab, server.URL, "ssh://git@gitlab.example.com/owner/repo.git")).To(Succeed())
+	})
+
+	It("reports other API errors", func() {
+		server, _ := fakeAPI(http.StatusForbidden, `{"message":"403 Forbidden"}`)
+
+		err := register(deploykey.ProviderGitea, server.URL, "ssh://git@gitea.example.com/owner/repo.git")
+		Expect(err).To(MatchError(ContainSubstring("403 Forbidden")))
+	})
+
+	It("requires a token", func() {
+		repo, _ := deploykey.ParseRepoURL("ssh://git@gitea.example.com/owner/repo.git")
+		GinkgoT().Setenv("GITEA_TOKEN", "")
+
+		_, err := deploykey.New(repo, deploykey.Options{})
+		Expect(err).To(MatchError(ContainSubstring("GITEA_TOKEN")))
+	})
+})
+
+var _ = Describe("Deregister", func() {
+	DescribeTable("removes the matching key",
+		func(provider, rawURL, listBody, expectedDelete string) {
+			var deleted []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(listBody))
+				case http.MethodDelete:
+					deleted = append(deleted, r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			repo, err := deploykey.ParseRepoURL(rawURL)
+			Expect(err).NotTo(HaveOccurred())
+			registrar, err := deploykey.New(repo, deploykey.Options{Provider: provider, APIURL: server.URL, Token: "token"})
+			Expect(err).NotTo(HaveOccurred())
+
+			// comments don't matter
+			Expect(registrar.Deregister(context.Background(), repo, "ssh-ed25519 OLD 23ke-config-key")).To(Succeed())
+			Expect(deleted).To(Equal([]string{expectedDelete}))
+		},
+		Entry("GitHub", deploykey.ProviderGitHub, "ssh://git@github.example.com/owner/repo.git",
+			`[{"id":1,"key":"ssh-ed25519 NEW"},{"id":2,"key":"ssh-ed25519 OLD"}]`, "/api/v3/repos/owner/repo/keys/2"),
+		Entry("GitLab", deploykey.ProviderGitLab, "ssh://git@gitlab.example.com/group/sub/repo.git",
+			`[{"id":1,"key":"ssh-ed25519 NEW"},{"id":2,"key":"ssh-ed25519 OLD"}]`, "/api/v4/projects/group%2Fsub%2Frepo/deploy_keys/2"),
+		Entry("Gitea", deploykey.ProviderGitea, "ssh://git@gitea.example.com/owner/repo.git",
+			`[{"id":1,"key":"ssh-ed25519 NEW"},{"id":2,"key":"ssh-ed25519 OLD"}]`, "/api/v1/repos/owner/repo/keys/2"),
+		Entry("Bitbucket", deploykey.ProviderBitbucket, "git@bitbucket.org:workspace/repo.git",
+			`{"values":[{"id":1,"key":"ssh-ed25519 NEW"},{"id":2,"key":"ssh-ed25519 OLD"}]}`, "/2.0/repositories/workspace/repo/deploy-keys/2"),
+	)
+
+	It("doesn't fail if the key isn't registered", func() {
+		server, requests := fakeAPI(http.StatusOK, `[]`)
+
+		repo, _ := deploykey.ParseRepoURL("ssh://git@gitea.example.com/owner/repo.git")
+		registrar, err := deploykey.New(repo, deploykey.Options{Provider: deploykey.ProviderGitea, APIURL: server.URL, Token: "token"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(registrar.Deregister(context.Background(), repo, "ssh-ed25519 OLD")).To(Succeed())
+		Expect(*requests).To(HaveLen(1))
+	})
+})
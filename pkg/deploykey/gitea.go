@@ -0,0 +1,50 @@
+package deploykey
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// gitea registers deploy keys via the Gitea v1 API, which is also used by Forgejo.
+type gitea struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+func (g *gitea) Register(ctx context.Context, repo *Repo, key Key) error {
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/keys", g.apiURL, repo.Owner(), repo.Name())
+
+	return postJSON(ctx, g.client, endpoint, http.Header{"Authorization": {"token " + g.token}}, map[string]interface{}{
+		"title":     key.Title,
+		"key":       key.PublicKey,
+		"read_only": key.ReadOnly,
+	})
+}
+
+func (g *gitea) Deregister(ctx context.Context, repo *Repo, publicKey string) error {
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/keys", g.apiURL, repo.Owner(), repo.Name())
+	header := http.Header{"Authorization": {"token " + g.token}}
+
+	for page := 1; ; page++ {
+		keys := []struct {
+			ID  int    `json:"id"`
+			Key string `json:"key"`
+		}{}
+
+		_, err := getJSON(ctx, g.client, fmt.Sprintf("%s?limit=50&page=%d", endpoint, page), header, &keys)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		for _, key := range keys {
+			if sameKey(key.Key, publicKey) {
+				return deleteRequest(ctx, g.client, fmt.Sprintf("%s/%d", endpoint, key.ID), header)
+			}
+		}
+	}
+}
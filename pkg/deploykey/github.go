@@ -0,0 +1,73 @@
+package deploykey
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	gh "github.com/google/go-github/v36/github"
+	"golang.org/x/oauth2"
+	"k8s.io/utils/pointer"
+)
+
+type gitHub struct {
+	client *gh.Client
+}
+
+// newGitHub returns a registrar for github.com or, if apiURL is set or the
+// repo is hosted elsewhere, for a GitHub Enterprise instance.
+func newGitHub(repo *Repo, apiURL, token string, httpClient *http.Client) (*gitHub, error) {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+	tokenClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+
+	if apiURL == "" && repo.Host == "github.com" {
+		return &gitHub{client: gh.NewClient(tokenClient)}, nil
+	}
+
+	// the client appends /api/v3/ for GitHub Enterprise
+	if apiURL == "" {
+		apiURL = "https://" + repo.Host
+	}
+	client, err := gh.NewEnterpriseClient(apiURL+"/", apiURL+"/", tokenClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitHub{client: client}, nil
+}
+
+func (g *gitHub) Register(ctx context.Context, repo *Repo, key Key) error {
+	_, _, err := g.client.Repositories.CreateKey(ctx, repo.Owner(), repo.Name(), &gh.Key{
+		Title:    pointer.String(key.Title),
+		Key:      pointer.String(key.PublicKey),
+		ReadOnly: pointer.Bool(key.ReadOnly),
+	})
+	if err != nil && !strings.Contains(err.Error(), "key is already in use") {
+		return err
+	}
+
+	return nil
+}
+
+func (g *gitHub) Deregister(ctx context.Context, repo *Repo, publicKey string) error {
+	opts := &gh.ListOptions{PerPage: 100}
+
+	for {
+		keys, res, err := g.client.Repositories.ListKeys(ctx, repo.Owner(), repo.Name(), opts)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if sameKey(key.GetKey(), publicKey) {
+				_, err = g.client.Repositories.DeleteKey(ctx, repo.Owner(), repo.Name(), key.GetID())
+				return err
+			}
+		}
+
+		if res.NextPage == 0 {
+			return nil
+		}
+		opts.Page = res.NextPage
+	}
+}
@@ -0,0 +1,53 @@
+package deploykey
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// gitLab registers deploy keys via the GitLab v4 API, which addresses
+// projects in nested groups by their URL encoded path.
+type gitLab struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+func (g *gitLab) Register(ctx context.Context, repo *Repo, key Key) error {
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/deploy_keys", g.apiURL, url.QueryEscape(repo.Path))
+
+	return postJSON(ctx, g.client, endpoint, http.Header{"Private-Token": {g.token}}, map[string]interface{}{
+		"title":    key.Title,
+		"key":      key.PublicKey,
+		"can_push": !key.ReadOnly,
+	})
+}
+
+func (g *gitLab) Deregister(ctx context.Context, repo *Repo, publicKey string) error {
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/deploy_keys", g.apiURL, url.QueryEscape(repo.Path))
+	header := http.Header{"Private-Token": {g.token}}
+
+	for page := "1"; page != ""; {
+		keys := []struct {
+			ID  int    `json:"id"`
+			Key string `json:"key"`
+		}{}
+
+		resHeader, err := getJSON(ctx, g.client, endpoint+"?per_page=100&page="+page, header, &keys)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if sameKey(key.Key, publicKey) {
+				return deleteRequest(ctx, g.client, fmt.Sprintf("%s/%d", endpoint, key.ID), header)
+			}
+		}
+
+		page = resHeader.Get("X-Next-Page")
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package dnscheck
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+)
+
+// Alicloud lists the domains of an Alibaba Cloud DNS account.
+type Alicloud struct {
+	AccessKeyID     string
+	AccessKeySecret string
+
+	// Endpoint defaults to the Alibaba Cloud DNS API
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (a *Alicloud) ListZones(ctx context.Context) ([]Zone, error) {
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = "https://alidns.aliyuncs.com"
+	}
+
+	var zones []Zone
+
+	for pageNumber := 1; ; pageNumber++ {
+		query := url.Values{
+			"Action":           {"DescribeDomains"},
+			"Version":          {"2015-01-09"},
+			"Format":           {"JSON"},
+			"AccessKeyId":      {a.AccessKeyID},
+			"SignatureMethod":  {"HMAC-SHA1"},
+			"SignatureVersion": {"1.0"},
+			"SignatureNonce":   {common.RandHex(16)},
+			"Timestamp":        {time.Now().UTC().Format("2006-01-02T15:04:05Z")},
+			"PageSize":         {"100"},
+			"PageNumber":       {strconv.Itoa(pageNumber)},
+		}
+		query.Set("Signature", signRPC(http.MethodGet, query, a.AccessKeySecret))
+
+		page := struct {
+			TotalCount int `json:"TotalCount"`
+			Domains    struct {
+				Domain []struct {
+					DomainName string `json:"DomainName"`
+					DnsServers struct {
+						DnsServer []string `json:"DnsServer"`
+					} `json:"DnsServers"`
+				} `json:"Domain"`
+			} `json:"Domains"`
+		}{}
+
+		err := getJSON(ctx, httpClient(a.HTTPClient), endpoint+"/?"+query.Encode(), nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range page.Domains.Domain {
+			zones = append(zones, Zone{Name: d.DomainName, NameServers: d.DnsServers.DnsServer})
+		}
+
+		if len(page.Domains.Domain) == 0 || len(zones) >= page.TotalCount {
+			return zones, nil
+		}
+	}
+}
+
+// signRPC signs the query of an Alibaba Cloud RPC style API call.
+// https://www.alibabacloud.com/help/en/alibaba-cloud-dns/latest/request-signing
+func signRPC(method string, query url.Values, secret string) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = percentEncode(key) + "=" + percentEncode(query.Get(key))
+	}
+
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func percentEncode(s string) string {
+	return strings.NewReplacer("+", "%20", "*", "%2A", "%7E", "~").Replace(url.QueryEscape(s))
+}
@@ -0,0 +1,99 @@
+package dnscheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// Azure lists the (private) DNS zones of an Azure subscription.
+type Azure struct {
+	TenantID       string
+	SubscriptionID string
+	ClientID       string
+	ClientSecret   string
+	Private        bool
+
+	// LoginURL and ManagementURL default to the Azure public cloud
+	LoginURL      string
+	ManagementURL string
+	HTTPClient    *http.Client
+}
+
+func (a *Azure) ListZones(ctx context.Context) ([]Zone, error) {
+	loginURL := a.LoginURL
+	if loginURL == "" {
+		loginURL = "https://login.microsoftonline.com"
+	}
+	managementURL := a.ManagementURL
+	if managementURL == "" {
+		managementURL = "https://management.azure.com"
+	}
+
+	conf := clientcredentials.Config{
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginURL, a.TenantID),
+		Scopes:       []string{managementURL + "/.default"},
+	}
+	client := conf.Client(context.WithValue(ctx, oauth2.HTTPClient, httpClient(a.HTTPClient)))
+
+	resource, apiVersion := "dnszones", "2018-05-01"
+	if a.Private {
+		resource, apiVersion = "privateDnsZones", "2020-06-01"
+	}
+
+	var zones []Zone
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Network/%s?api-version=%s", managementURL, a.SubscriptionID, resource, apiVersion)
+
+	for next != "" {
+		page := struct {
+			Value []struct {
+				Name       string `json:"name"`
+				Properties struct {
+					NameServers []string `json:"nameServers"`
+				} `json:"properties"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}{}
+
+		err := getJSON(ctx, client, next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, z := range page.Value {
+			zones = append(zones, Zone{Name: z.Name, NameServers: z.Properties.NameServers, Private: a.Private})
+		}
+		next = page.NextLink
+	}
+
+	return zones, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = checkResponse(res)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
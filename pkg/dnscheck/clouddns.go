@@ -0,0 +1,83 @@
+package dnscheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+// CloudDNS lists the managed zones of a Google Cloud project.
+type CloudDNS struct {
+	// ServiceAccountJSON is the JSON key file of a service account
+	ServiceAccountJSON []byte
+
+	// Endpoint defaults to the Cloud DNS API, the token URL is taken from the key file
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (c *CloudDNS) ListZones(ctx context.Context) ([]Zone, error) {
+	key := struct {
+		ProjectID    string `json:"project_id"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}{}
+	err := json.Unmarshal(c.ServiceAccountJSON, &key)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the service account key: %w", err)
+	}
+
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://dns.googleapis.com"
+	}
+
+	conf := jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{"https://www.googleapis.com/auth/ndev.clouddns.readonly"},
+		TokenURL:     key.TokenURI,
+	}
+	client := conf.Client(context.WithValue(ctx, oauth2.HTTPClient, httpClient(c.HTTPClient)))
+
+	var zones []Zone
+	pageToken := ""
+
+	for {
+		query := url.Values{}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		page := struct {
+			ManagedZones []struct {
+				DNSName     string   `json:"dnsName"`
+				NameServers []string `json:"nameServers"`
+				Visibility  string   `json:"visibility"`
+			} `json:"managedZones"`
+			NextPageToken string `json:"nextPageToken"`
+		}{}
+
+		err = getJSON(ctx, client, fmt.Sprintf("%s/dns/v1/projects/%s/managedZones?%s", endpoint, key.ProjectID, query.Encode()), nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, z := range page.ManagedZones {
+			zones = append(zones, Zone{Name: z.DNSName, NameServers: z.NameServers, Private: z.Visibility == "private"})
+		}
+
+		if page.NextPageToken == "" {
+			return zones, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
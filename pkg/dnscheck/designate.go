@@ -0,0 +1,152 @@
+package dnscheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Designate lists the zones of an OpenStack project using an application credential.
+type Designate struct {
+	AuthURL                     string
+	ApplicationCredentialID     string
+	ApplicationCredentialSecret string
+
+	HTTPClient *http.Client
+}
+
+func (d *Designate) ListZones(ctx context.Context) ([]Zone, error) {
+	token, dnsURL, err := d.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{"X-Auth-Token": []string{token}}
+
+	var zones []Zone
+	next := strings.TrimSuffix(dnsURL, "/") + "/v2/zones"
+
+	for next != "" {
+		page := struct {
+			Zones []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"zones"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
+		}{}
+
+		err = getJSON(ctx, httpClient(d.HTTPClient), next, header, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, z := range page.Zones {
+			zones = append(zones, Zone{ID: z.ID, Name: z.Name})
+		}
+		next = page.Links.Next
+	}
+
+	return zones, nil
+}
+
+// ListNameServers returns the records of the NS recordset at the apex of a
+// zone, designate doesn't list them with the zone.
+func (d *Designate) ListNameServers(ctx context.Context, zone Zone) ([]string, error) {
+	token, dnsURL, err := d.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"type": []string{"NS"}, "name": []string{zone.Name}}
+	recordsets := struct {
+		Recordsets []struct {
+			Name    string   `json:"name"`
+			Records []string `json:"records"`
+		} `json:"recordsets"`
+	}{}
+
+	err = getJSON(ctx, httpClient(d.HTTPClient), strings.TrimSuffix(dnsURL, "/")+"/v2/zones/"+url.PathEscape(zone.ID)+"/recordsets?"+query.Encode(),
+		http.Header{"X-Auth-Token": []string{token}}, &recordsets)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameServers []string
+	for _, recordset := range recordsets.Recordsets {
+		if normalize(recordset.Name) == normalize(zone.Name) {
+			nameServers = append(nameServers, recordset.Records...)
+		}
+	}
+
+	return nameServers, nil
+}
+
+// authenticate returns a keystone token and the public endpoint of designate from the service catalog.
+func (d *Designate) authenticate(ctx context.Context) (string, string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"application_credential"},
+				"application_credential": map[string]string{
+					"id":     d.ApplicationCredentialID,
+					"secret": d.ApplicationCredentialSecret,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(d.AuthURL, "/")+"/auth/tokens", bytes.NewReader(body))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := httpClient(d.HTTPClient).Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+
+	err = checkResponse(res)
+	if err != nil {
+		return "", "", err
+	}
+
+	tokenResponse := struct {
+		Token struct {
+			Catalog []struct {
+				Type      string `json:"type"`
+				Endpoints []struct {
+					Interface string `json:"interface"`
+					URL       string `json:"url"`
+				} `json:"endpoints"`
+			} `json:"catalog"`
+		} `json:"token"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&tokenResponse)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, service := range tokenResponse.Token.Catalog {
+		if service.Type != "dns" {
+			continue
+		}
+		for _, endpoint := range service.Endpoints {
+			if endpoint.Interface == "public" {
+				return res.Header.Get("X-Subject-Token"), endpoint.URL, nil
+			}
+		}
+	}
+
+	return "", "", fmt.Errorf("there's no public dns endpoint in the service catalog of %s", d.AuthURL)
+}
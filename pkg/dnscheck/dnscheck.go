@@ -0,0 +1,163 @@
+// Package dnscheck verifies a DNS configuration before it is handed to
+// external-dns and cert-manager inside the cluster, where mistakes are only
+// discovered much later.
+package dnscheck
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Zone is a DNS zone hosted by a provider. NameServers may be empty if the
+// provider doesn't report them when listing zones.
+type Zone struct {
+	ID          string
+	Name        string
+	NameServers []string
+	Private     bool
+}
+
+// ZoneLister lists the zones which can be managed with a set of credentials.
+type ZoneLister interface {
+	ListZones(ctx context.Context) ([]Zone, error)
+}
+
+// NameServerLister is implemented by providers which don't report the name
+// servers of a zone when listing zones, but can look them up per zone.
+type NameServerLister interface {
+	ListNameServers(ctx context.Context, zone Zone) ([]string, error)
+}
+
+// Resolver looks up the public name servers of a zone.
+type Resolver interface {
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+}
+
+// NewResolver returns a resolver asking the given DNS server, e.g. "127.0.0.1:53".
+// An empty server uses the resolvers of the system.
+func NewResolver(server string) Resolver {
+	if server == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: 5 * time.Second}
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// Verify checks that the credentials behind lister are accepted by the provider,
+// that one of the provider's zones contains domain and that this zone is
+// delegated to the provider. Private zones aren't publicly resolvable, so their
+// delegation isn't checked.
+func Verify(ctx context.Context, domain string, lister ZoneLister, resolver Resolver) error {
+	zones, err := lister.ListZones(ctx)
+	if err != nil {
+		return fmt.Errorf("the DNS provider rejected the credentials: %w", err)
+	}
+
+	zone := findZone(zones, domain)
+	if zone == nil {
+		names := make([]string, len(zones))
+		for i, z := range zones {
+			names[i] = normalize(z.Name)
+		}
+		return fmt.Errorf("none of the zones accessible with the DNS credentials contains %s, found: [%s]", domain, strings.Join(names, ", "))
+	}
+
+	if zone.Private {
+		return nil
+	}
+
+	if nsLister, ok := lister.(NameServerLister); ok && len(zone.NameServers) == 0 {
+		zone.NameServers, err = nsLister.ListNameServers(ctx, *zone)
+		if err != nil {
+			return fmt.Errorf("couldn't get the name servers of zone %s from the DNS provider: %w", normalize(zone.Name), err)
+		}
+	}
+
+	nss, err := resolver.LookupNS(ctx, normalize(zone.Name))
+	if err != nil {
+		return fmt.Errorf("zone %s doesn't seem to be delegated, couldn't look up its NS records: %w", normalize(zone.Name), err)
+	}
+
+	if len(zone.NameServers) == 0 {
+		return nil
+	}
+
+	public := make([]string, len(nss))
+	for i, ns := range nss {
+		public[i] = normalize(ns.Host)
+		for _, nameServer := range zone.NameServers {
+			if normalize(nameServer) == public[i] {
+				return nil
+			}
+		}
+	}
+
+	provider := make([]string, len(zone.NameServers))
+	for i, nameServer := range zone.NameServers {
+		provider[i] = normalize(nameServer)
+	}
+
+	return fmt.Errorf("zone %s isn't delegated to the DNS provider: its public name servers are [%s], the provider's are [%s]",
+		normalize(zone.Name), strings.Join(public, ", "), strings.Join(provider, ", "))
+}
+
+// findZone returns the most specific zone containing domain.
+func findZone(zones []Zone, domain string) *Zone {
+	domain = normalize(domain)
+
+	var found *Zone
+	for i := range zones {
+		name := normalize(zones[i].Name)
+		if domain != name && !strings.HasSuffix(domain, "."+name) {
+			continue
+		}
+
+		if found == nil || len(name) > len(normalize(found.Name)) {
+			found = &zones[i]
+		}
+	}
+
+	return found
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// DecodeCredential returns the plain value of a credential which the wizard
+// stores base64 encoded. Values which aren't valid base64 are returned as is.
+func DecodeCredential(value string) string {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return value
+	}
+
+	return string(decoded)
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return client
+}
+
+func checkResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	return fmt.Errorf("%s %s: %s", res.Request.Method, res.Request.URL.Redacted(), res.Status)
+}
@@ -0,0 +1,201 @@
+package dnscheck_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/23technologies/23kectl/pkg/dnscheck"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeAzure serves the token endpoint and the DNS zones of a subscription.
+// Only the client secret "secret" is accepted.
+func fakeAzure(zones []map[string]interface{}) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("client_secret") != "secret" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/subscriptions/sub/providers/Microsoft.Network/dnszones", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"value": zones})
+	})
+
+	server := httptest.NewServer(mux)
+	DeferCleanup(server.Close)
+
+	return server
+}
+
+func newAzure(server *httptest.Server, secret string) *dnscheck.Azure {
+	return &dnscheck.Azure{
+		TenantID:       "tenant",
+		SubscriptionID: "sub",
+		ClientID:       "client",
+		ClientSecret:   secret,
+		LoginURL:       server.URL,
+		ManagementURL:  server.URL,
+	}
+}
+
+// fakeRoute53 serves the hosted zone list and the delegation set of the zone
+// example.com.
+func fakeRoute53(nameServers ...string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2013-04-01/hostedzone", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<ListHostedZonesResponse><HostedZones><HostedZone><Id>/hostedzone/Z1</Id><Name>example.com.</Name></HostedZone></HostedZones><IsTruncated>false</IsTruncated></ListHostedZonesResponse>`))
+	})
+	mux.HandleFunc("/2013-04-01/hostedzone/Z1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<GetHostedZoneResponse><DelegationSet><NameServers>`))
+		for _, nameServer := range nameServers {
+			_, _ = w.Write([]byte("<NameServer>" + nameServer + "</NameServer>"))
+		}
+		_, _ = w.Write([]byte(`</NameServers></DelegationSet></GetHostedZoneResponse>`))
+	})
+
+	server := httptest.NewServer(mux)
+	DeferCleanup(server.Close)
+
+	return server
+}
+
+// fakeDesignate serves keystone, the zone example.com and its NS recordset.
+func fakeDesignate(nameServers ...string) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	DeferCleanup(server.Close)
+
+	mux.HandleFunc("/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Subject-Token", "token")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"token": map[string]interface{}{"catalog": []interface{}{
+			map[string]interface{}{"type": "dns", "endpoints": []interface{}{
+				map[string]interface{}{"interface": "public", "url": server.URL + "/dns"},
+			}},
+		}}})
+	})
+	mux.HandleFunc("/dns/v2/zones", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"zones": []interface{}{
+			map[string]interface{}{"id": "z1", "name": "example.com."},
+		}})
+	})
+	mux.HandleFunc("/dns/v2/zones/z1/recordsets", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "token" || r.URL.Query().Get("type") != "NS" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"recordsets": []interface{}{
+			map[string]interface{}{"name": "example.com.", "records": nameServers},
+		}})
+	})
+
+	return server
+}
+
+type staticZones []dnscheck.Zone
+
+func (z staticZones) ListZones(_ context.Context) ([]dnscheck.Zone, error) {
+	return z, nil
+}
+
+var _ = Describe("Verify", func() {
+	var resolver dnscheck.Resolver
+
+	BeforeEach(func() {
+		resolver = dnscheck.NewResolver(startFakeDNSServer(map[string][]string{
+			"example.com":     {"ns1-01.azure-dns.com", "ns2-01.azure-dns.net"},
+			"elsewhere.com":   {"ns1.registrar.example"},
+			"sub.example.com": {"ns1-02.azure-dns.com"},
+		}))
+	})
+
+	It("accepts a zone delegated to the provider", func() {
+		server := fakeAzure([]map[string]interface{}{
+			{"name": "example.com", "properties": map[string]interface{}{"nameServers": []string{"ns1-01.azure-dns.com."}}},
+		})
+
+		Expect(dnscheck.Verify(context.Background(), "gardener.example.com", newAzure(server, "secret"), resolver)).To(Succeed())
+	})
+
+	It("reports rejected credentials", func() {
+		server := fakeAzure(nil)
+
+		err := dnscheck.Verify(context.Background(), "gardener.example.com", newAzure(server, "wrong"), resolver)
+		Expect(err).To(MatchError(ContainSubstring("rejected the credentials")))
+	})
+
+	It("reports a domain which isn't part of any zone", func() {
+		server := fakeAzure([]map[string]interface{}{
+			{"name": "other.org"},
+		})
+
+		err := dnscheck.Verify(context.Background(), "gardener.example.com", newAzure(server, "secret"), resolver)
+		Expect(err).To(MatchError(ContainSubstring("found: [other.org]")))
+	})
+
+	It("reports a zone delegated to other name servers", func() {
+		zones := staticZones{{Name: "elsewhere.com", NameServers: []string{"ns1-01.azure-dns.com"}}}
+
+		err := dnscheck.Verify(context.Background(), "gardener.elsewhere.com", zones, resolver)
+		Expect(err).To(MatchError(ContainSubstring("public name servers are [ns1.registrar.example]")))
+	})
+
+	It("reports a zone which isn't delegated at all", func() {
+		zones := staticZones{{Name: "missing.com", NameServers: []string{"ns1-01.azure-dns.com"}}}
+
+		err := dnscheck.Verify(context.Background(), "gardener.missing.com", zones, resolver)
+		Expect(err).To(MatchError(ContainSubstring("doesn't seem to be delegated")))
+	})
+
+	It("picks the most specific zone", func() {
+		zones := staticZones{
+			{Name: "example.com", NameServers: []string{"ns1-01.azure-dns.com"}},
+			{Name: "sub.example.com", NameServers: []string{"ns1-02.azure-dns.com"}},
+		}
+
+		Expect(dnscheck.Verify(context.Background(), "gardener.sub.example.com", zones, resolver)).To(Succeed())
+	})
+
+	It("checks the delegation set of a Route 53 zone", func() {
+		route53 := &dnscheck.Route53{Endpoint: fakeRoute53("ns1-01.azure-dns.com").URL}
+		Expect(dnscheck.Verify(context.Background(), "gardener.example.com", route53, resolver)).To(Succeed())
+
+		route53 = &dnscheck.Route53{Endpoint: fakeRoute53("ns-1.awsdns-01.org").URL}
+		err := dnscheck.Verify(context.Background(), "gardener.example.com", route53, resolver)
+		Expect(err).To(MatchError(ContainSubstring("the provider's are [ns-1.awsdns-01.org]")))
+	})
+
+	It("checks the NS records of a Designate zone", func() {
+		designate := &dnscheck.Designate{AuthURL: fakeDesignate("ns2-01.azure-dns.net.").URL + "/v3"}
+		Expect(dnscheck.Verify(context.Background(), "gardener.example.com", designate, resolver)).To(Succeed())
+
+		designate = &dnscheck.Designate{AuthURL: fakeDesignate("ns1.openstack.example.").URL + "/v3"}
+		err := dnscheck.Verify(context.Background(), "gardener.example.com", designate, resolver)
+		Expect(err).To(MatchError(ContainSubstring("the provider's are [ns1.openstack.example]")))
+	})
+
+	It("doesn't check the delegation of private zones", func() {
+		zones := staticZones{{Name: "internal.corp", Private: true}}
+
+		Expect(dnscheck.Verify(context.Background(), "gardener.internal.corp", zones, resolver)).To(Succeed())
+	})
+})
+
+var _ = Describe("DecodeCredential", func() {
+	It("decodes base64 values and keeps plain ones", func() {
+		Expect(dnscheck.DecodeCredential("c2VjcmV0")).To(Equal("secret"))
+		Expect(dnscheck.DecodeCredential("not base64!")).To(Equal("not base64!"))
+	})
+})
@@ -0,0 +1,176 @@
+package dnscheck
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Route53 lists the hosted zones of an AWS account.
+type Route53 struct {
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Endpoint defaults to the global Route 53 endpoint
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (r *Route53) ListZones(ctx context.Context) ([]Zone, error) {
+	var zones []Zone
+	marker := ""
+
+	for {
+		query := url.Values{}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		page := struct {
+			HostedZones []struct {
+				ID     string `xml:"Id"`
+				Name   string `xml:"Name"`
+				Config struct {
+					PrivateZone bool `xml:"PrivateZone"`
+				} `xml:"Config"`
+			} `xml:"HostedZones>HostedZone"`
+			IsTruncated bool   `xml:"IsTruncated"`
+			NextMarker  string `xml:"NextMarker"`
+		}{}
+
+		err := r.getXML(ctx, "/2013-04-01/hostedzone?"+query.Encode(), &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, z := range page.HostedZones {
+			zones = append(zones, Zone{ID: z.ID, Name: z.Name, Private: z.Config.PrivateZone})
+		}
+
+		if !page.IsTruncated {
+			return zones, nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+// ListNameServers returns the delegation set of a hosted zone, which isn't part
+// of the zone list.
+func (r *Route53) ListNameServers(ctx context.Context, zone Zone) ([]string, error) {
+	hostedZone := struct {
+		NameServers []string `xml:"DelegationSet>NameServers>NameServer"`
+	}{}
+
+	// ids are listed as /hostedzone/<id>
+	id := strings.TrimPrefix(zone.ID, "/hostedzone/")
+	err := r.getXML(ctx, "/2013-04-01/hostedzone/"+url.PathEscape(id), &hostedZone)
+	if err != nil {
+		return nil, err
+	}
+
+	return hostedZone.NameServers, nil
+}
+
+func (r *Route53) getXML(ctx context.Context, path string, v interface{}) error {
+	endpoint := r.Endpoint
+	if endpoint == "" {
+		endpoint = "https://route53.amazonaws.com"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	signV4(req, r.AccessKeyID, r.SecretAccessKey, "us-east-1", "route53", time.Now())
+
+	res, err := httpClient(r.HTTPClient).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = checkResponse(res)
+	if err != nil {
+		return err
+	}
+
+	return xml.NewDecoder(res.Body).Decode(v)
+}
+
+// signV4 signs a request without body with AWS signature version 4.
+// https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html
+func signV4(req *http.Request, accessKeyID string, secretAccessKey string, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	emptyPayloadHash := hex.EncodeToString(sha256Sum(""))
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(req.Header.Get(key))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := strings.Builder{}
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalPath := req.URL.EscapedPath()
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(sha256Sum(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Sum(s string) []byte {
+	sum := sha256.Sum256([]byte(s))
+	return sum[:]
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,65 @@
+package dnscheck_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Suite")
+}
+
+// startFakeDNSServer answers NS queries from the given records on a local UDP port
+// and returns its address. Unknown names are answered with NXDOMAIN.
+func startFakeDNSServer(nsRecords map[string][]string) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(conn.Close)
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var query dnsmessage.Message
+			if query.Unpack(buf[:n]) != nil || len(query.Questions) == 0 {
+				continue
+			}
+			question := query.Questions[0]
+
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+
+			hosts, ok := nsRecords[strings.TrimSuffix(question.Name.String(), ".")]
+			if !ok {
+				response.RCode = dnsmessage.RCodeNameError
+			} else if question.Type == dnsmessage.TypeNS {
+				for _, host := range hosts {
+					response.Answers = append(response.Answers, dnsmessage.Resource{
+						Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET, TTL: 60},
+						Body:   &dnsmessage.NSResource{NS: dnsmessage.MustNewName(host + ".")},
+					})
+				}
+			}
+
+			packed, err := response.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
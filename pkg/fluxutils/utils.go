@@ -26,11 +26,13 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
@@ -129,6 +131,7 @@ func NewScheme() *apiruntime.Scheme {
 	_ = rbacv1.AddToScheme(scheme)
 	_ = appsv1.AddToScheme(scheme)
 	_ = networkingv1.AddToScheme(scheme)
+	_ = storagev1.AddToScheme(scheme)
 	_ = sourcev1.AddToScheme(scheme)
 	_ = kustomizev1.AddToScheme(scheme)
 	_ = helmv2.AddToScheme(scheme)
@@ -250,6 +253,21 @@ func MakeDependsOn(deps []string) []meta.NamespacedObjectReference {
 	return refs
 }
 
+// RequestReconcile makes flux reconcile the object right away instead of waiting for the next interval.
+// It returns the requested time, which flux reports as status.lastHandledReconcileAt once it's handled.
+func RequestReconcile(obj client.Object) string {
+	requestedAt := time.Now().Format(time.RFC3339Nano)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[meta.ReconcileRequestAnnotation] = requestedAt
+	obj.SetAnnotations(annotations)
+
+	return requestedAt
+}
+
 func ValidateComponents(components []string) error {
 	defaults := install.MakeDefaultOptions()
 	bootstrapAllComponents := append(defaults.Components, defaults.ComponentsExtra...)
@@ -43,6 +43,32 @@ func Install(kubeconfig string, isDryRun bool) error {
 	}
 }
 
+// InstallPkgVersion returns the install package required by the configured 23KE version.
+func InstallPkgVersion() (string, error) {
+	yaml23kectl, err := fetch23kectlyaml()
+	if err != nil {
+		return "", err
+	}
+
+	return yaml23kectl["installPkgVersion"], nil
+}
+
+// ValidateConfig validates the current configuration against the schema of the given install package.
+func ValidateConfig(installPkgVersion string) ([]common.ValidationError, error) {
+	switch installPkgVersion {
+	case "v1-trial", "v1":
+		return installv1.ValidateConfig()
+	case "v2-trial", "v2":
+		return installv2.ValidateConfig()
+	case "v3":
+		return installv3.ValidateConfig()
+	case "v4":
+		return installv4.ValidateConfig()
+	default:
+		return nil, fmt.Errorf("unknown install package version '%s'", installPkgVersion)
+	}
+}
+
 func queryBucketConfig() error {
 
 	common.QueryConfigKey("version", func() (any, error) {
@@ -50,7 +76,7 @@ func queryBucketConfig() error {
 			Message: "Please enter the version to install.",
 		}
 		var queryResult string
-		err := survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err := common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -62,7 +88,7 @@ func queryBucketConfig() error {
 			Message: "Please enter the bucket endpoint, you got from 23T. This is part of your 23ke license.",
 		}
 		var queryResult string
-		err := survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err := common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -75,7 +101,7 @@ func queryBucketConfig() error {
 			Message: "Please enter the accesskey, you got from 23T. This is part of your 23ke license.",
 		}
 		var queryResult string
-		err := survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err := common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -89,7 +115,7 @@ func queryBucketConfig() error {
 			Message: "Please enter the secretkey, you got from 23T. This is part of your 23ke license.",
 		}
 		var queryResult string
-		err := survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err := common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -109,12 +135,21 @@ func fetch23kectlyaml() (map[string]string, error) {
 		return nil, err
 	}
 
+	err = common.CheckMissingConfigKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return fetch23kectlyamlFromBucket(viper.GetString("version"))
+}
+
+func fetch23kectlyamlFromBucket(bucket string) (map[string]string, error) {
 	s3Client, err := common.CreateMinioClient()
 	if err != nil {
 		return nil, err
 	}
 
-	obj, err := s3Client.GetObject(context.Background(), viper.GetString("version"),
+	obj, err := s3Client.GetObject(context.Background(), bucket,
 		"23kectl.yaml", minio.GetObjectOptions{})
 	if err != nil {
 		return nil, err
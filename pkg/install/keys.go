@@ -0,0 +1,32 @@
+package install
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+
+	installv4 "github.com/23technologies/23kectl/pkg/install/v4"
+)
+
+// RotateDeployKey replaces the deploy key of the config repo of an existing installation.
+func RotateDeployKey(kubeconfig string, timeout time.Duration) error {
+	installPkgVersion, err := InstallPkgVersion()
+	if err != nil {
+		return err
+	}
+
+	switch installPkgVersion {
+	case "v4":
+		return installv4.RotateDeployKey(kubeconfig, timeout)
+	default:
+		return fmt.Errorf("rotating the deploy key isn't supported by install package '%s', please upgrade first", installPkgVersion)
+	}
+}
+
+// BlockUntilKeyCanRead waits until the deploy key can read the config repo, registering it
+// with the git provider if there's an API token. It doesn't depend on the config, so the
+// latest install package's implementation serves every installation.
+func BlockUntilKeyCanRead(repoURL string, keys *ssh.PublicKeys, pubkey string) error {
+	return installv4.Container.BlockUntilKeyCanRead(repoURL, keys, pubkey)
+}
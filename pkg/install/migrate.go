@@ -0,0 +1,228 @@
+package install
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// installPkgOrder lists the install packages from oldest to newest.
+// Trial packages share the schema of the corresponding full package.
+var installPkgOrder = []string{"v1", "v2", "v3", "v4"}
+
+type configMigration struct {
+	From    string
+	To      string
+	Migrate func(settings map[string]interface{})
+}
+
+// configMigrations converts the config of an install package into the shape
+// expected by the next one in installPkgOrder.
+var configMigrations = []configMigration{
+	{From: "v1", To: "v2", Migrate: migrateConfigV1ToV2},
+	{From: "v2", To: "v3", Migrate: func(_ map[string]interface{}) {}},
+	{From: "v3", To: "v4", Migrate: migrateConfigV3ToV4},
+}
+
+func migrateConfigV1ToV2(settings map[string]interface{}) {
+	if _, ok := settings["backupconfig"]; !ok {
+		settings["backupconfig"] = map[string]interface{}{"enabled": false}
+	}
+	if _, ok := settings["cloudprofiles"]; !ok {
+		settings["cloudprofiles"] = []string{"alicloud", "aws", "azure", "gcp", "hcloud", "regiocloud", "wavestack"}
+	}
+}
+
+func migrateConfigV3ToV4(settings map[string]interface{}) {
+	// the dashboard and the kube-apiserver don't need generated secrets anymore
+	delete(settings, "dashboard")
+	delete(settings, "kubeapiserver")
+}
+
+// MigrationReport summarizes the changes MigrateConfig made to the config file.
+type MigrationReport struct {
+	From       string
+	To         string
+	Added      []string
+	Dropped    []string
+	BackupFile string
+}
+
+func (r *MigrationReport) Print() {
+	fmt.Printf("Migrated config from install package %s to %s\n", r.From, r.To)
+	for _, key := range r.Added {
+		fmt.Printf("  + %s\n", key)
+	}
+	for _, key := range r.Dropped {
+		fmt.Printf("  - %s\n", key)
+	}
+	if r.BackupFile != "" {
+		fmt.Printf("The original config was saved to %s\n", r.BackupFile)
+	}
+}
+
+func installPkgIndex(installPkgVersion string) int {
+	base := strings.TrimSuffix(installPkgVersion, "-trial")
+	for i, pkg := range installPkgOrder {
+		if pkg == base {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// checkUpgradeAllowed allows staying on the same install package or moving to the next one.
+func checkUpgradeAllowed(from string, to string) error {
+	fromIndex := installPkgIndex(from)
+	toIndex := installPkgIndex(to)
+
+	if fromIndex == -1 || toIndex == -1 {
+		return fmt.Errorf("unknown install package transition %s -> %s. Please update 23kectl and try again", from, to)
+	}
+
+	if toIndex < fromIndex {
+		return fmt.Errorf("downgrading from install package %s to %s isn't supported", from, to)
+	}
+
+	if toIndex > fromIndex+1 {
+		return fmt.Errorf("upgrading from install package %s to %s skips %s. Please upgrade to a version using %s first", from, to, installPkgOrder[fromIndex+1], installPkgOrder[fromIndex+1])
+	}
+
+	return nil
+}
+
+// MigrateConfig converts the current config from one install package's schema to another's
+// by applying every migration step in between. The config file is rewritten, the original
+// is kept as a backup next to it.
+func MigrateConfig(from string, to string) (*MigrationReport, error) {
+	report, settings, err := migrateSettings(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report.BackupFile, err = backupConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := common.MarshalConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(viper.ConfigFileUsed(), content, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	// viper can't unset keys, re-reading the file drops removed ones
+	err = common.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// migrateSettings applies every migration step from one install package to another
+// to a copy of the current settings. Neither viper nor the config file are changed.
+func migrateSettings(from string, to string) (*MigrationReport, map[string]interface{}, error) {
+	fromIndex := installPkgIndex(from)
+	toIndex := installPkgIndex(to)
+
+	if fromIndex == -1 || toIndex == -1 {
+		return nil, nil, fmt.Errorf("unknown install package transition %s -> %s", from, to)
+	}
+
+	if toIndex < fromIndex {
+		return nil, nil, fmt.Errorf("migrating from install package %s back to %s isn't supported", from, to)
+	}
+
+	settings := viper.AllSettings()
+	keysBefore := flattenKeys(settings, "")
+
+	for _, migration := range configMigrations {
+		if installPkgIndex(migration.From) >= fromIndex && installPkgIndex(migration.To) <= toIndex {
+			migration.Migrate(settings)
+		}
+	}
+
+	keysAfter := flattenKeys(settings, "")
+
+	report := &MigrationReport{
+		From:    from,
+		To:      to,
+		Added:   difference(keysAfter, keysBefore),
+		Dropped: difference(keysBefore, keysAfter),
+	}
+
+	return report, settings, nil
+}
+
+// loadSettings replaces the config viper holds in memory. The config file isn't touched.
+func loadSettings(settings map[string]interface{}) error {
+	content, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	// viper can't unset keys, reading the whole config drops removed ones
+	return viper.ReadConfig(bytes.NewReader(content))
+}
+
+// backupConfigFile copies the config file next to itself and returns the copy's path.
+// It returns an empty path if there's no config file yet.
+func backupConfigFile() (string, error) {
+	configFile := viper.ConfigFileUsed()
+	original, err := os.ReadFile(configFile)
+	if err != nil {
+		return "", nil
+	}
+
+	backupFile := fmt.Sprintf("%s.%s.bak", configFile, time.Now().Format("20060102150405"))
+	err = os.WriteFile(backupFile, original, 0600)
+	if err != nil {
+		return "", fmt.Errorf("couldn't back up the config file: %w", err)
+	}
+
+	return backupFile, nil
+}
+
+func flattenKeys(settings map[string]interface{}, prefix string) []string {
+	var keys []string
+	for key, value := range settings {
+		if nested, ok := value.(map[string]interface{}); ok {
+			keys = append(keys, flattenKeys(nested, prefix+key+".")...)
+		} else {
+			keys = append(keys, prefix+key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// difference returns the elements of a which aren't in b.
+func difference(a []string, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, key := range b {
+		inB[key] = true
+	}
+
+	var result []string
+	for _, key := range a {
+		if !inB[key] {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
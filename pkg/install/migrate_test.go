@@ -0,0 +1,95 @@
+package install
+
+import (
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("MigrateConfig", func() {
+	var configFile string
+
+	BeforeEach(func() {
+		tmpFolder, err := os.MkdirTemp("", "23kectl-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, tmpFolder)
+
+		configFile = path.Join(tmpFolder, "config.yaml")
+		err = os.WriteFile(configFile, []byte(`
+version: v1.0.0
+admin:
+  email: test@example.org
+dashboard:
+  clientSecret: my-client-secret
+  sessionSecret: my-session-secret
+kubeApiServer:
+  basicAuthPassword: my-basic-auth-password
+`), 0600)
+		Expect(err).NotTo(HaveOccurred())
+
+		viper.Reset()
+		viper.SetConfigFile(configFile)
+		Expect(viper.ReadInConfig()).To(Succeed())
+	})
+
+	It("should apply every step from v1 to v4", func() {
+		report, err := MigrateConfig("v1-trial", "v4")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Added).To(ConsistOf("backupconfig.enabled", "cloudprofiles"))
+		Expect(report.Dropped).To(ConsistOf("dashboard.clientsecret", "dashboard.sessionsecret", "kubeapiserver.basicauthpassword"))
+
+		Expect(viper.IsSet("dashboard")).To(BeFalse())
+		Expect(viper.IsSet("kubeApiServer.basicAuthPassword")).To(BeFalse())
+		Expect(viper.GetBool("backupConfig.enabled")).To(BeFalse())
+		Expect(viper.GetString("admin.email")).To(Equal("test@example.org"))
+	})
+
+	It("should only apply the steps in between", func() {
+		report, err := MigrateConfig("v2", "v3")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Added).To(BeEmpty())
+		Expect(report.Dropped).To(BeEmpty())
+		Expect(viper.IsSet("dashboard.clientSecret")).To(BeTrue())
+	})
+
+	It("should keep a backup of the original config file", func() {
+		original, err := os.ReadFile(configFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		report, err := MigrateConfig("v3", "v4")
+		Expect(err).NotTo(HaveOccurred())
+
+		backup, err := os.ReadFile(report.BackupFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backup).To(Equal(original))
+	})
+
+	It("should refuse to migrate backwards", func() {
+		_, err := MigrateConfig("v4", "v3")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("checkUpgradeAllowed", func() {
+	DescribeTable("install package transitions",
+		func(from string, to string, allowed bool) {
+			err := checkUpgradeAllowed(from, to)
+			if allowed {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("same package", "v4", "v4", true),
+		Entry("trial to full", "v2-trial", "v2", true),
+		Entry("next package", "v3", "v4", true),
+		Entry("skipping a package", "v2", "v4", false),
+		Entry("downgrade", "v4", "v3", false),
+		Entry("unknown package", "v4", "v5", false),
+	)
+})
@@ -0,0 +1,22 @@
+package install
+
+import (
+	"fmt"
+
+	installv4 "github.com/23technologies/23kectl/pkg/install/v4"
+)
+
+// EditSecret edits a sops encrypted secret in the config repo of an existing installation.
+func EditSecret(kubeconfig string, file string) error {
+	installPkgVersion, err := InstallPkgVersion()
+	if err != nil {
+		return err
+	}
+
+	switch installPkgVersion {
+	case "v4":
+		return installv4.EditSecret(kubeconfig, file)
+	default:
+		return fmt.Errorf("encrypted secrets aren't supported by install package '%s', please upgrade first", installPkgVersion)
+	}
+}
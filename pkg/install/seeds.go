@@ -0,0 +1,58 @@
+package install
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	installv4 "github.com/23technologies/23kectl/pkg/install/v4"
+)
+
+// AddSeed registers an additional seed cluster with an existing installation.
+func AddSeed(kubeconfig string, seedKubeconfig string, seedContext string, name string, gardenServer string) error {
+	installPkgVersion, err := InstallPkgVersion()
+	if err != nil {
+		return err
+	}
+
+	switch installPkgVersion {
+	case "v4":
+		return installv4.AddSeed(kubeconfig, seedKubeconfig, seedContext, name, gardenServer)
+	default:
+		return seedsNotSupported(installPkgVersion)
+	}
+}
+
+// ListSeeds prints the seeds of the config.
+func ListSeeds(w io.Writer) error {
+	installPkgVersion, err := InstallPkgVersion()
+	if err != nil {
+		return err
+	}
+
+	switch installPkgVersion {
+	case "v4":
+		return installv4.ListSeeds(w)
+	default:
+		return seedsNotSupported(installPkgVersion)
+	}
+}
+
+// RemoveSeed removes an additional seed cluster from an existing installation.
+func RemoveSeed(kubeconfig string, name string, timeout time.Duration) error {
+	installPkgVersion, err := InstallPkgVersion()
+	if err != nil {
+		return err
+	}
+
+	switch installPkgVersion {
+	case "v4":
+		return installv4.RemoveSeed(kubeconfig, name, timeout)
+	default:
+		return seedsNotSupported(installPkgVersion)
+	}
+}
+
+func seedsNotSupported(installPkgVersion string) error {
+	return fmt.Errorf("additional seeds aren't supported by install package '%s', please upgrade first", installPkgVersion)
+}
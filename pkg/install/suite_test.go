@@ -0,0 +1,18 @@
+package install
+
+import (
+	"testing"
+
+	"github.com/23technologies/23kectl/pkg/logger"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Suite")
+}
+
+var _ = BeforeSuite(func() {
+	DeferCleanup(logger.Init())
+})
@@ -0,0 +1,299 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	installv4 "github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/23technologies/23kectl/pkg/logger"
+	kustomizecontrollerv1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	sourcecontrollerv1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type UninstallOptions struct {
+	// KeepFlux leaves the flux controllers and CRDs in place.
+	KeepFlux bool
+	// KeepDeployKey leaves the deploy key of the config repo in place, so it doesn't have to be registered again.
+	KeepDeployKey bool
+	// Force skips the check for existing shoots.
+	Force bool
+	// Timeout limits the time to wait for each kustomization to be pruned.
+	Timeout time.Duration
+}
+
+// Uninstall removes a 23KE installation in reverse order of Install.
+func Uninstall(kubeconfig string, opts UninstallOptions) error {
+	log := logger.Get("Uninstall")
+
+	_, _, kubeClient, err := Container.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if !opts.Force {
+		err = checkNoShootsExist(kubeClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Sources are suspended, so no new revisions are applied while tearing down.
+	// Kustomizations mustn't be suspended, otherwise they wouldn't prune on deletion.
+	fmt.Println("Suspending 23KE sources")
+	err = suspendSource(kubeClient, &sourcecontrollerv1beta2.GitRepository{}, common.CONFIG_23KE_GITREPO_NAME)
+	if err != nil {
+		return err
+	}
+	err = suspendSource(kubeClient, &sourcecontrollerv1beta2.Bucket{}, common.BUCKET_NAME)
+	if err != nil {
+		return err
+	}
+
+	// the config kustomization applies the env kustomizations, which depend on the base kustomization
+	for _, name := range []string{common.CONFIG_KS_NAME, common.BASE_ADDONS_23KE_KS_NAME, common.BASE_23KE_KS_NAME} {
+		fmt.Printf("Deleting kustomization '%s' and waiting for it to be pruned\n", name)
+		err = deleteAndWait(kubeClient, &kustomizecontrollerv1beta2.Kustomization{}, name, opts.Timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Deleting 23KE sources")
+	err = deleteAndWait(kubeClient, &sourcecontrollerv1beta2.GitRepository{}, common.CONFIG_23KE_GITREPO_NAME, opts.Timeout)
+	if err != nil {
+		return err
+	}
+	err = deleteAndWait(kubeClient, &sourcecontrollerv1beta2.Bucket{}, common.BUCKET_NAME, opts.Timeout)
+	if err != nil {
+		return err
+	}
+
+	secrets := []string{common.BUCKET_SECRET_NAME, common.CONFIG_23KE_SECRET_NAME, common.SOPS_AGE_SECRET_NAME}
+	seedSecrets, err := listSeedSecrets(kubeClient)
+	if err != nil {
+		return err
+	}
+	secrets = append(secrets, seedSecrets...)
+	if !opts.KeepDeployKey {
+		secrets = append(secrets, common.CONFIG_23KE_GITREPO_KEY)
+	}
+	for _, name := range secrets {
+		fmt.Printf("Deleting secret '%s'\n", name)
+		err = deleteAndWait(kubeClient, &corev1.Secret{}, name, opts.Timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !opts.KeepFlux {
+		fmt.Println("Uninstalling flux")
+		err = uninstallFlux(kubeClient, opts.KeepDeployKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info("Uninstall finished")
+	fmt.Println("23KE was uninstalled.")
+
+	return nil
+}
+
+// checkNoShootsExist lists the shoots in the virtual garden. Deleting 23KE
+// would orphan their infrastructure.
+func checkNoShootsExist(kubeClient client.Client) error {
+	sec := corev1.Secret{}
+	err := kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.GARDENER_KUBECONFIG_SECRET_NAME,
+	}, &sec)
+	if apierrors.IsNotFound(err) {
+		// gardener was never deployed, so there can't be any shoots
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	kubeconfig, ok := sec.Data["value"]
+	if !ok {
+		kubeconfig = sec.Data["value.yaml"]
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("couldn't read the kubeconfig of the virtual garden: %w", err)
+	}
+
+	gardenClient, err := client.New(restConfig, client.Options{})
+	if err != nil {
+		return err
+	}
+
+	shoots := unstructured.UnstructuredList{}
+	shoots.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "core.gardener.cloud",
+		Version: "v1beta1",
+		Kind:    "ShootList",
+	})
+	err = gardenClient.List(context.Background(), &shoots)
+	if err != nil {
+		return fmt.Errorf("couldn't check for existing shoots, use --force to uninstall anyway: %w", err)
+	}
+
+	if len(shoots.Items) > 0 {
+		msg := fmt.Sprintf("there are still %d shoot(s), delete them first or use --force:", len(shoots.Items))
+		for _, shoot := range shoots.Items {
+			msg += fmt.Sprintf("\n  - %s/%s", shoot.GetNamespace(), shoot.GetName())
+		}
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// listSeedSecrets returns the names of the secrets of all additional seeds, including
+// the ones of seeds which aren't in the config anymore.
+func listSeedSecrets(kubeClient client.Client) ([]string, error) {
+	secrets := corev1.SecretList{}
+	err := kubeClient.List(context.Background(), &secrets, client.InNamespace(common.FLUX_NAMESPACE))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, sec := range secrets.Items {
+		if installv4.IsSeedSecretName(sec.Name) {
+			names = append(names, sec.Name)
+		}
+	}
+
+	return names, nil
+}
+
+func suspendSource(kubeClient client.Client, obj client.Object, name string) error {
+	err := kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      name,
+	}, obj)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	switch source := obj.(type) {
+	case *sourcecontrollerv1beta2.GitRepository:
+		source.Spec.Suspend = true
+	case *sourcecontrollerv1beta2.Bucket:
+		source.Spec.Suspend = true
+	}
+
+	return kubeClient.Patch(context.Background(), obj, patch)
+}
+
+// deleteAndWait deletes the object from the flux namespace and waits until its finalizers are done.
+func deleteAndWait(kubeClient client.Client, obj client.Object, name string, timeout time.Duration) error {
+	obj.SetNamespace(common.FLUX_NAMESPACE)
+	obj.SetName(name)
+
+	err := kubeClient.Delete(context.Background(), obj)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		err := kubeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("'%s' wasn't deleted in time: %w", name, err)
+	}
+
+	return nil
+}
+
+// uninstallFlux deletes all flux components. If the deploy key is kept, so is the flux namespace.
+func uninstallFlux(kubeClient client.Client, keepNamespace bool) error {
+	selector := client.MatchingLabels{"app.kubernetes.io/part-of": "flux"}
+
+	if keepNamespace {
+		for _, list := range []client.ObjectList{
+			&appsv1.DeploymentList{},
+			&corev1.ServiceList{},
+			&corev1.ServiceAccountList{},
+			&networkingv1.NetworkPolicyList{},
+		} {
+			err := deleteAll(kubeClient, list, selector, client.InNamespace(common.FLUX_NAMESPACE))
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		err := kubeClient.Delete(context.Background(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: common.FLUX_NAMESPACE},
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	for _, list := range []client.ObjectList{
+		&rbacv1.ClusterRoleBindingList{},
+		&rbacv1.ClusterRoleList{},
+		&apiextensionsv1.CustomResourceDefinitionList{},
+	} {
+		err := deleteAll(kubeClient, list, selector)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteAll deletes every listed object one by one, as not all resources support deletecollection.
+func deleteAll(kubeClient client.Client, list client.ObjectList, opts ...client.ListOption) error {
+	err := kubeClient.List(context.Background(), list, opts...)
+	if err != nil {
+		return err
+	}
+
+	objects, err := apimeta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		err = kubeClient.Delete(context.Background(), obj.(client.Object))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	kustomizecontrollerv1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	runclient "github.com/fluxcd/pkg/runtime/client"
+	sourcecontrollerv1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recordingClient records the kind and name of every deleted object.
+type recordingClient struct {
+	client.Client
+	deleted []string
+}
+
+func (c *recordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	err = c.Client.Delete(ctx, obj, opts...)
+	if err == nil {
+		c.deleted = append(c.deleted, fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName()))
+	}
+
+	return err
+}
+
+var _ = Describe("Uninstall", func() {
+	var kubeClient *recordingClient
+
+	inFluxNamespace := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: common.FLUX_NAMESPACE, Name: name}
+	}
+
+	get := func(obj client.Object, name string) error {
+		return kubeClient.Get(context.Background(), client.ObjectKey{Namespace: common.FLUX_NAMESPACE, Name: name}, obj)
+	}
+
+	BeforeEach(func() {
+		kubeClient = &recordingClient{Client: fake.NewClientBuilder().WithScheme(utils.NewScheme()).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: common.FLUX_NAMESPACE}},
+			&sourcecontrollerv1beta2.GitRepository{ObjectMeta: inFluxNamespace(common.CONFIG_23KE_GITREPO_NAME)},
+			&sourcecontrollerv1beta2.Bucket{ObjectMeta: inFluxNamespace(common.BUCKET_NAME)},
+			&kustomizecontrollerv1beta2.Kustomization{ObjectMeta: inFluxNamespace(common.CONFIG_KS_NAME)},
+			&kustomizecontrollerv1beta2.Kustomization{ObjectMeta: inFluxNamespace(common.BASE_ADDONS_23KE_KS_NAME)},
+			&kustomizecontrollerv1beta2.Kustomization{ObjectMeta: inFluxNamespace(common.BASE_23KE_KS_NAME)},
+			&corev1.Secret{ObjectMeta: inFluxNamespace(common.BUCKET_SECRET_NAME)},
+			&corev1.Secret{ObjectMeta: inFluxNamespace(common.CONFIG_23KE_SECRET_NAME)},
+			&corev1.Secret{ObjectMeta: inFluxNamespace(common.CONFIG_23KE_GITREPO_KEY)},
+			&corev1.Secret{ObjectMeta: inFluxNamespace(common.SOPS_AGE_SECRET_NAME)},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Namespace: common.FLUX_NAMESPACE,
+				Name:      "source-controller",
+				Labels:    map[string]string{"app.kubernetes.io/part-of": "flux"},
+			}},
+		).Build()}
+
+		original := Container
+		DeferCleanup(func() { Container = original })
+		Container.CreateKubeClient = func(_ string) (*genericclioptions.ConfigFlags, *runclient.Options, client.Client, error) {
+			return nil, nil, kubeClient, nil
+		}
+	})
+
+	It("should tear down in reverse order of the installation", func() {
+		Expect(Uninstall("", UninstallOptions{Timeout: time.Second})).To(Succeed())
+
+		Expect(kubeClient.deleted).To(Equal([]string{
+			"Kustomization/" + common.CONFIG_KS_NAME,
+			"Kustomization/" + common.BASE_ADDONS_23KE_KS_NAME,
+			"Kustomization/" + common.BASE_23KE_KS_NAME,
+			"GitRepository/" + common.CONFIG_23KE_GITREPO_NAME,
+			"Bucket/" + common.BUCKET_NAME,
+			"Secret/" + common.BUCKET_SECRET_NAME,
+			"Secret/" + common.CONFIG_23KE_SECRET_NAME,
+			"Secret/" + common.SOPS_AGE_SECRET_NAME,
+			"Secret/" + common.CONFIG_23KE_GITREPO_KEY,
+			"Namespace/" + common.FLUX_NAMESPACE,
+		}))
+	})
+
+	It("should remove the 23KE secrets but keep flux and the deploy key", func() {
+		Expect(Uninstall("", UninstallOptions{KeepFlux: true, KeepDeployKey: true, Timeout: time.Second})).To(Succeed())
+
+		for _, name := range []string{common.BUCKET_SECRET_NAME, common.CONFIG_23KE_SECRET_NAME, common.SOPS_AGE_SECRET_NAME} {
+			Expect(apierrors.IsNotFound(get(&corev1.Secret{}, name))).To(BeTrue(), name)
+		}
+		Expect(get(&corev1.Secret{}, common.CONFIG_23KE_GITREPO_KEY)).To(Succeed())
+		Expect(get(&appsv1.Deployment{}, "source-controller")).To(Succeed())
+	})
+
+	It("should remove the secrets of every additional seed", func() {
+		for _, name := range []string{"seed-aws-kubeconfig", "seed-aws-garden-connection", "seed-gcp-kubeconfig", "seed-notes"} {
+			Expect(kubeClient.Create(context.Background(), &corev1.Secret{ObjectMeta: inFluxNamespace(name)})).To(Succeed())
+		}
+
+		Expect(Uninstall("", UninstallOptions{KeepFlux: true, Timeout: time.Second})).To(Succeed())
+
+		for _, name := range []string{"seed-aws-kubeconfig", "seed-aws-garden-connection", "seed-gcp-kubeconfig"} {
+			Expect(apierrors.IsNotFound(get(&corev1.Secret{}, name))).To(BeTrue(), name)
+		}
+		Expect(get(&corev1.Secret{}, "seed-notes")).To(Succeed())
+	})
+
+	It("should keep the flux namespace with the deploy key, but remove the controllers", func() {
+		Expect(Uninstall("", UninstallOptions{KeepDeployKey: true, Timeout: time.Second})).To(Succeed())
+
+		Expect(get(&corev1.Secret{}, common.CONFIG_23KE_GITREPO_KEY)).To(Succeed())
+		Expect(apierrors.IsNotFound(get(&appsv1.Deployment{}, "source-controller"))).To(BeTrue())
+		Expect(kubeClient.deleted).NotTo(ContainElement("Namespace/" + common.FLUX_NAMESPACE))
+	})
+})
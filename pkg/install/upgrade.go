@@ -0,0 +1,214 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/logger"
+	kustomizecontrollerv1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	runclient "github.com/fluxcd/pkg/runtime/client"
+	sourcecontrollerv1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	installv1 "github.com/23technologies/23kectl/pkg/install/v1"
+	installv2 "github.com/23technologies/23kectl/pkg/install/v2"
+	installv3 "github.com/23technologies/23kectl/pkg/install/v3"
+	installv4 "github.com/23technologies/23kectl/pkg/install/v4"
+)
+
+// Container holds the functions Upgrade and Uninstall call out to, so tests can replace them.
+var Container = struct {
+	Fetch23kectlyaml           func() (map[string]string, error)
+	Fetch23kectlyamlFromBucket func(bucket string) (map[string]string, error)
+	CreateKubeClient           func(kubeconfig string) (*genericclioptions.ConfigFlags, *runclient.Options, client.Client, error)
+	Reconfigure                func(installPkgVersion string, kubeconfig string) error
+}{
+	Fetch23kectlyaml:           fetch23kectlyaml,
+	Fetch23kectlyamlFromBucket: fetch23kectlyamlFromBucket,
+	CreateKubeClient:           common.CreateKubeClient,
+	Reconfigure:                reconfigure,
+}
+
+// Upgrade moves an existing installation to another 23KE version.
+// The config file is only rewritten once the cluster runs the new version.
+func Upgrade(kubeconfig string, toVersion string, timeout time.Duration) error {
+	log := logger.Get("Upgrade")
+
+	fromYaml, err := Container.Fetch23kectlyaml()
+	if err != nil {
+		return err
+	}
+	fromVersion := viper.GetString("version")
+
+	toYaml, err := Container.Fetch23kectlyamlFromBucket(toVersion)
+	if err != nil {
+		return fmt.Errorf("couldn't fetch 23kectl.yaml of version %s: %w", toVersion, err)
+	}
+
+	fromPkgVersion := fromYaml["installPkgVersion"]
+	toPkgVersion := toYaml["installPkgVersion"]
+
+	err = checkUpgradeAllowed(fromPkgVersion, toPkgVersion)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Upgrading from %s (install package %s) to %s (install package %s)\n", fromVersion, fromPkgVersion, toVersion, toPkgVersion)
+
+	report, settings, err := migrateSettings(fromPkgVersion, toPkgVersion)
+	if err != nil {
+		return err
+	}
+	err = loadSettings(settings)
+	if err != nil {
+		return err
+	}
+	viper.Set("version", toVersion)
+
+	fmt.Println("Updating the config repo")
+	err = Container.Reconfigure(toPkgVersion, kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	_, _, kubeClient, err := Container.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Switching bucket source '%s' to '%s'\n", common.BUCKET_NAME, toVersion)
+	err = patchBucket(kubeClient, toVersion)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Waiting for kustomization '%s' to reconcile\n", common.BASE_23KE_KS_NAME)
+	err = waitForBaseKustomization(kubeClient, timeout)
+	if err != nil {
+		return err
+	}
+
+	report.BackupFile, err = backupConfigFile()
+	if err != nil {
+		return err
+	}
+	err = common.WriteConfig()
+	if err != nil {
+		return err
+	}
+	report.Print()
+
+	log.Info("Upgrade finished", "from", fromVersion, "to", toVersion)
+	fmt.Printf("Awesome. 23KE was upgraded to %s.\n", toVersion)
+
+	return nil
+}
+
+// reconfigure updates the config repo and the '23ke-config' secret with the install package's templates.
+func reconfigure(installPkgVersion string, kubeconfig string) error {
+	if installPkgVersion == "v4" {
+		return installv4.Reconfigure(kubeconfig)
+	}
+
+	_, _, kubeClient, err := common.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	sec := corev1.Secret{}
+	err = kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.CONFIG_23KE_GITREPO_KEY,
+	}, &sec)
+	if err != nil {
+		return fmt.Errorf("couldn't read the deploy key of the config repo: %w", err)
+	}
+
+	publicKeys, err := ssh.NewPublicKeys("git", sec.Data["identity"], "")
+	if err != nil {
+		return err
+	}
+
+	switch installPkgVersion {
+	case "v1-trial", "v1":
+		return installv1.Reconfigure(kubeClient, publicKeys)
+	case "v2-trial", "v2":
+		return installv2.Reconfigure(kubeClient, publicKeys)
+	case "v3":
+		return installv3.Reconfigure(kubeClient, publicKeys)
+	default:
+		return fmt.Errorf("unknown install package version '%s'", installPkgVersion)
+	}
+}
+
+func patchBucket(kubeClient client.Client, version string) error {
+	bucket := sourcecontrollerv1beta2.Bucket{}
+	err := kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.BUCKET_NAME,
+	}, &bucket)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(bucket.DeepCopy())
+	bucket.Spec.BucketName = version
+	utils.RequestReconcile(&bucket)
+
+	return kubeClient.Patch(context.Background(), &bucket, patch)
+}
+
+// waitForBaseKustomization blocks until the base kustomization has applied the
+// latest artifact of the 23ke bucket.
+func waitForBaseKustomization(kubeClient client.Client, timeout time.Duration) error {
+	ks := kustomizecontrollerv1beta2.Kustomization{}
+	bucket := sourcecontrollerv1beta2.Bucket{}
+
+	err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		err := kubeClient.Get(context.Background(), client.ObjectKey{
+			Namespace: common.FLUX_NAMESPACE,
+			Name:      common.BUCKET_NAME,
+		}, &bucket)
+		if err != nil {
+			return false, err
+		}
+
+		if bucket.Status.ObservedGeneration != bucket.Generation || bucket.Status.Artifact == nil {
+			return false, nil
+		}
+
+		err = kubeClient.Get(context.Background(), client.ObjectKey{
+			Namespace: common.FLUX_NAMESPACE,
+			Name:      common.BASE_23KE_KS_NAME,
+		}, &ks)
+		if err != nil {
+			return false, err
+		}
+
+		if ks.Status.LastAppliedRevision != bucket.Status.Artifact.Revision {
+			return false, nil
+		}
+
+		return apimeta.IsStatusConditionTrue(ks.Status.Conditions, meta.ReadyCondition), nil
+	})
+
+	if err != nil {
+		readyMessage := ""
+		if condition := apimeta.FindStatusCondition(ks.Status.Conditions, meta.ReadyCondition); condition != nil {
+			readyMessage = condition.Message
+		}
+		return fmt.Errorf("kustomization '%s' didn't reconcile: %w %s", common.BASE_23KE_KS_NAME, err, readyMessage)
+	}
+
+	return nil
+}
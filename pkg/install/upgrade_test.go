@@ -0,0 +1,126 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	kustomizecontrollerv1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	runclient "github.com/fluxcd/pkg/runtime/client"
+	sourcecontrollerv1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Upgrade", func() {
+	var (
+		configFile     string
+		originalConfig []byte
+		fakeClient     client.Client
+		reconfigureErr error
+		reconfiguredTo string
+	)
+
+	BeforeEach(func() {
+		tmpFolder, err := os.MkdirTemp("", "23kectl-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, tmpFolder)
+
+		configFile = path.Join(tmpFolder, "config.yaml")
+		originalConfig = []byte(`
+version: v3.1.0
+admin:
+  email: test@example.org
+dashboard:
+  clientSecret: my-client-secret
+`)
+		Expect(os.WriteFile(configFile, originalConfig, 0600)).To(Succeed())
+
+		viper.Reset()
+		viper.SetConfigFile(configFile)
+		Expect(viper.ReadInConfig()).To(Succeed())
+
+		fakeClient = fake.NewClientBuilder().WithScheme(utils.NewScheme()).WithObjects(
+			&sourcecontrollerv1beta2.Bucket{
+				ObjectMeta: metav1.ObjectMeta{Namespace: common.FLUX_NAMESPACE, Name: common.BUCKET_NAME},
+				Spec:       sourcecontrollerv1beta2.BucketSpec{BucketName: "v3.1.0"},
+				Status: sourcecontrollerv1beta2.BucketStatus{
+					Artifact: &sourcecontrollerv1beta2.Artifact{Revision: "my-revision"},
+				},
+			},
+			&kustomizecontrollerv1beta2.Kustomization{
+				ObjectMeta: metav1.ObjectMeta{Namespace: common.FLUX_NAMESPACE, Name: common.BASE_23KE_KS_NAME},
+				Status: kustomizecontrollerv1beta2.KustomizationStatus{
+					LastAppliedRevision: "my-revision",
+					Conditions:          []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: "Succeeded", LastTransitionTime: metav1.Now()}},
+				},
+			},
+		).Build()
+
+		reconfigureErr = nil
+		reconfiguredTo = ""
+
+		original := Container
+		DeferCleanup(func() { Container = original })
+		Container.Fetch23kectlyaml = func() (map[string]string, error) {
+			return map[string]string{"installPkgVersion": "v3"}, nil
+		}
+		Container.Fetch23kectlyamlFromBucket = func(_ string) (map[string]string, error) {
+			return map[string]string{"installPkgVersion": "v4"}, nil
+		}
+		Container.CreateKubeClient = func(_ string) (*genericclioptions.ConfigFlags, *runclient.Options, client.Client, error) {
+			return nil, nil, fakeClient, nil
+		}
+		Container.Reconfigure = func(installPkgVersion string, _ string) error {
+			reconfiguredTo = viper.GetString("version")
+			Expect(installPkgVersion).To(Equal("v4"))
+			Expect(viper.IsSet("dashboard.clientSecret")).To(BeFalse())
+			return reconfigureErr
+		}
+	})
+
+	It("should reconfigure with the migrated config and switch the bucket", func() {
+		Expect(Upgrade("", "v4.0.0", time.Second)).To(Succeed())
+		Expect(reconfiguredTo).To(Equal("v4.0.0"))
+
+		bucket := sourcecontrollerv1beta2.Bucket{}
+		Expect(fakeClient.Get(context.Background(), client.ObjectKey{
+			Namespace: common.FLUX_NAMESPACE,
+			Name:      common.BUCKET_NAME,
+		}, &bucket)).To(Succeed())
+		Expect(bucket.Spec.BucketName).To(Equal("v4.0.0"))
+
+		Expect(viper.ReadInConfig()).To(Succeed())
+		Expect(viper.GetString("version")).To(Equal("v4.0.0"))
+		Expect(viper.IsSet("dashboard")).To(BeFalse())
+
+		backups, err := filepath.Glob(configFile + ".*.bak")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backups).To(HaveLen(1))
+	})
+
+	It("should leave the config file alone if the cluster isn't upgraded", func() {
+		reconfigureErr = errors.New("couldn't push to the config repo")
+
+		Expect(Upgrade("", "v4.0.0", time.Second)).To(MatchError(reconfigureErr))
+
+		content, err := os.ReadFile(configFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(originalConfig))
+
+		backups, err := filepath.Glob(configFile + ".*.bak")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backups).To(BeEmpty())
+	})
+})
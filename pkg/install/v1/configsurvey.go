@@ -28,7 +28,7 @@ func queryConfig(kubeClient client.Client) error {
 		return err
 	}
 
-	err = viper.WriteConfig()
+	err = common.WriteConfig()
 	if err != nil {
 		log.Info("Viper couldn't write config file", "error", err)
 	}
@@ -43,10 +43,15 @@ func queryConfig(kubeClient client.Client) error {
 		return err
 	}
 
+	err = common.CheckMissingConfigKeys()
+	if err != nil {
+		return err
+	}
+
 	// enable the provider extensions needed for a minimal setup
 	viper.Set("extensionsConfig.provider-"+viper.GetString("baseCluster.provider")+".enabled", true)
 	viper.Set("extensionsConfig."+common.DNS_PROVIDER_TO_PROVIDER[viper.GetString("domainConfig.provider")]+".enabled", true)
-	err = viper.WriteConfig()
+	err = common.WriteConfig()
 	if err != nil {
 		return err
 	}
@@ -99,7 +104,7 @@ func completeKeConfig(kubeClient client.Client) error {
 			Message: "Please enter the pod CIDR of your base cluster in the form: x.x.x.x/y",
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,cidr"))
+		err = common.AskOne(prompt, &queryResult, "required,cidr")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -149,7 +154,7 @@ func queryAdminConfig() error {
 This will be the email address to use, when you want to login to the Gardener dashboard.`,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,email"))
+		err = common.AskOne(prompt, &queryResult, "required,email")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return "", err
@@ -164,7 +169,7 @@ This will be the email address to use, when you want to login to the Gardener da
 This will be the password to use, when you login to the Gardener dashboard.`,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return "", err
@@ -188,7 +193,7 @@ Flux will monitor these files to pick up configuration changes.
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,url,startswith=ssh://"))
+		err = common.AskOne(prompt, &queryResult, "required,url,startswith=ssh://")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -206,7 +211,7 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -220,7 +225,7 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 			Default: viper.GetString("admin.email"),
 		}
 		var queryResult string
-		err := survey.AskOne(prompt, &queryResult, common.WithValidator("required,email"))
+		err := common.AskOne(prompt, &queryResult, "required,email")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -255,7 +260,7 @@ If you feel like this list in incomplete, contact the 23T support.
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -273,7 +278,7 @@ For clusters hosted on Azure, this could be e.g. germanywestcentral or westeurop
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -291,7 +296,7 @@ Therefore, the node CIDR should match a network that comprises all ip addresses
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,cidr"))
+		err = common.AskOne(prompt, &queryResult, "required,cidr")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -322,7 +327,12 @@ Automatically detecting VPA from within the cluster isn't reliable, so if you ch
 
 		var queryResult string
 
-		err = survey.AskOne(prompt, &queryResult)
+		if common.IsNonInteractive() {
+			// the answer is stored as a bool rather than the selected option
+			return nil, &common.PromptError{Type: "bool", Message: "Does your base cluster provide vertical pod autoscaling (VPA)?"}
+		}
+
+		err = common.AskOne(prompt, &queryResult, "")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return false, err
@@ -359,7 +369,7 @@ func queryDomainConfig() (*domainConfiguration, error) {
 Gardener components will be available as subdomains of this (e.g dashboard.<gardener.my-company.io>).
 Note that it has to be delegated to the chosen DNS provider.`,
 	}
-	err = survey.AskOne(prompt, &domain, common.WithValidator("required,fqdn"))
+	err = common.AskConfigKey("domainConfig.domain", prompt, &domain, "required,fqdn")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -369,7 +379,7 @@ Note that it has to be delegated to the chosen DNS provider.`,
 		Message: "Define your DNS provider",
 		Options: []string{common.DNS_PROVIDER_AZURE_DNS, common.DNS_PROVIDER_OPENSTACK_DESIGNATE, common.DNS_PROVIDER_AWS_ROUTE_53},
 	}
-	err = survey.AskOne(prompt, &provider, common.WithValidator("required"))
+	err = common.AskConfigKey("domainConfig.provider", prompt, &provider, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -407,7 +417,7 @@ func (d *dnsCredentialsAzure) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -438,7 +448,7 @@ func (d *dnsCredentialsOSDesignate) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -462,7 +472,7 @@ func (d *dnsCredentialsAWS53) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
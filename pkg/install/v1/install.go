@@ -157,15 +157,7 @@ func UnmarshalKeConfig(config *KeConfig) error {
 
 	_, ok := (config.DomainConfig.Credentials).(map[string]interface{})
 	if ok {
-		var creds interface{}
-		switch config.DomainConfig.Provider {
-		case common.DNS_PROVIDER_AZURE_DNS:
-			creds = dnsCredentialsAzure{}
-		case common.DNS_PROVIDER_OPENSTACK_DESIGNATE:
-			creds = dnsCredentialsOSDesignate{}
-		case common.DNS_PROVIDER_AWS_ROUTE_53:
-			creds = dnsCredentialsAWS53{}
-		}
+		creds := newDNSCredentials(config.DomainConfig.Provider)
 		err = mapstructure.Decode(config.DomainConfig.Credentials, &creds)
 		if err != nil {
 			return err
@@ -0,0 +1,19 @@
+package install
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reconfigure renders the current configuration into the '23ke-config' secret
+// and the config repo of an existing installation.
+func Reconfigure(kubeClient client.Client, publicKeys *ssh.PublicKeys) error {
+	Container.Create = kubeClient.Create
+
+	err := create23keConfigSecret(kubeClient)
+	if err != nil {
+		return err
+	}
+
+	return updateConfigRepo(publicKeys)
+}
@@ -0,0 +1,66 @@
+package install
+
+import (
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// ValidateConfig checks the current configuration against the KeConfig schema
+// of this install package and returns all violations.
+func ValidateConfig() ([]common.ValidationError, error) {
+	keConfig := &KeConfig{}
+	err := viper.Unmarshal(keConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	violations, err := common.ValidateStruct(keConfig, "")
+	if err != nil {
+		return nil, err
+	}
+
+	credViolations, err := validateCredentials(keConfig.DomainConfig.Credentials, newDNSCredentials(keConfig.DomainConfig.Provider), "domainConfig.credentials")
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, credViolations...)
+
+	return violations, nil
+}
+
+// validateCredentials decodes raw into the credentials struct of the chosen provider and validates it.
+// Nothing is validated for unknown providers, as the provider itself is already reported.
+func validateCredentials(raw interface{}, creds interface{}, path string) ([]common.ValidationError, error) {
+	if raw == nil || creds == nil {
+		return nil, nil
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName: "yaml",
+		Result:  &creds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = decoder.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.ValidateStruct(creds, path)
+}
+
+func newDNSCredentials(provider string) interface{} {
+	switch provider {
+	case common.DNS_PROVIDER_AZURE_DNS:
+		return dnsCredentialsAzure{}
+	case common.DNS_PROVIDER_OPENSTACK_DESIGNATE:
+		return dnsCredentialsOSDesignate{}
+	case common.DNS_PROVIDER_AWS_ROUTE_53:
+		return dnsCredentialsAWS53{}
+	}
+
+	return nil
+}
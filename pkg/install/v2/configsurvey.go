@@ -28,7 +28,7 @@ func queryConfig(kubeClient client.Client) error {
 		return err
 	}
 
-	err = viper.WriteConfig()
+	err = common.WriteConfig()
 	if err != nil {
 		log.Info("Viper couldn't write config file", "error", err)
 	}
@@ -43,6 +43,11 @@ func queryConfig(kubeClient client.Client) error {
 		return err
 	}
 
+	err = common.CheckMissingConfigKeys()
+	if err != nil {
+		return err
+	}
+
 	if !viper.IsSet("cloudprofiles") {
 		viper.Set("cloudprofiles", []string{"alicloud", "aws", "azure", "gcp", "hcloud", "regiocloud", "wavestack"})
 	}
@@ -50,7 +55,7 @@ func queryConfig(kubeClient client.Client) error {
 	// enable the provider extensions needed for a minimal setup
 	viper.Set("extensionsConfig.provider-"+viper.GetString("baseCluster.provider")+".enabled", true)
 	viper.Set("extensionsConfig."+common.DNS_PROVIDER_TO_PROVIDER[viper.GetString("domainConfig.provider")]+".enabled", true)
-	err = viper.WriteConfig()
+	err = common.WriteConfig()
 	if err != nil {
 		return err
 	}
@@ -103,7 +108,7 @@ func completeKeConfig(kubeClient client.Client) error {
 			Message: "Please enter the pod CIDR of your base cluster in the form: x.x.x.x/y",
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,cidr"))
+		err = common.AskOne(prompt, &queryResult, "required,cidr")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -153,7 +158,7 @@ func queryAdminConfig() error {
 This will be the email address to use, when you want to login to the Gardener dashboard.`,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,email"))
+		err = common.AskOne(prompt, &queryResult, "required,email")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return "", err
@@ -168,7 +173,7 @@ This will be the email address to use, when you want to login to the Gardener da
 This will be the password to use, when you login to the Gardener dashboard.`,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return "", err
@@ -192,7 +197,7 @@ Flux will monitor these files to pick up configuration changes.
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,url,startswith=ssh://"))
+		err = common.AskOne(prompt, &queryResult, "required,url,startswith=ssh://")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -210,7 +215,7 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -224,7 +229,7 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 			Default: viper.GetString("admin.email"),
 		}
 		var queryResult string
-		err := survey.AskOne(prompt, &queryResult, common.WithValidator("required,email"))
+		err := common.AskOne(prompt, &queryResult, "required,email")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -267,7 +272,7 @@ If you feel like this list in incomplete, contact the 23T support.
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -285,7 +290,7 @@ For clusters hosted on Azure, this could be e.g. germanywestcentral or westeurop
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -303,7 +308,7 @@ Therefore, the node CIDR should match a network that comprises all ip addresses
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,cidr"))
+		err = common.AskOne(prompt, &queryResult, "required,cidr")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -334,7 +339,12 @@ Automatically detecting VPA from within the cluster isn't reliable, so if you ch
 
 		var queryResult string
 
-		err = survey.AskOne(prompt, &queryResult)
+		if common.IsNonInteractive() {
+			// the answer is stored as a bool rather than the selected option
+			return nil, &common.PromptError{Type: "bool", Message: "Does your base cluster provide vertical pod autoscaling (VPA)?"}
+		}
+
+		err = common.AskOne(prompt, &queryResult, "")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return false, err
@@ -371,7 +381,7 @@ func queryDomainConfig() (*domainConfiguration, error) {
 Gardener components will be available as subdomains of this (e.g dashboard.<gardener.my-company.io>).
 Note that it has to be delegated to the chosen DNS provider.`,
 	}
-	err = survey.AskOne(prompt, &domain, common.WithValidator("required,fqdn"))
+	err = common.AskConfigKey("domainConfig.domain", prompt, &domain, "required,fqdn")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -381,7 +391,7 @@ Note that it has to be delegated to the chosen DNS provider.`,
 		Message: "Define your DNS provider",
 		Options: []string{common.DNS_PROVIDER_AZURE_DNS, common.DNS_PROVIDER_OPENSTACK_DESIGNATE, common.DNS_PROVIDER_AWS_ROUTE_53},
 	}
-	err = survey.AskOne(prompt, &provider, common.WithValidator("required"))
+	err = common.AskConfigKey("domainConfig.provider", prompt, &provider, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -419,7 +429,7 @@ func (d *dnsCredentialsAzure) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -450,7 +460,7 @@ func (d *dnsCredentialsOSDesignate) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -474,7 +484,7 @@ func (d *dnsCredentialsAWS53) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -484,21 +494,15 @@ func (d *dnsCredentialsAWS53) parseCredentials() error {
 
 func queryBackupConfig() (*backupConfiguration, error) {
 	var err error
-	var region, provider, bucketName, configureBackups string
+	var region, provider, bucketName string
 	var enabled bool
 	var prompt survey.Prompt
 
-	prompt = &survey.Select{
+	prompt = &survey.Confirm{
 		Message: `Please tell me whether you want to configure the backup functionality.`,
-		Options: []string{"yes", "no"},
 	}
 
-	err = survey.AskOne(prompt, &configureBackups, common.WithValidator("required"))
-	if configureBackups == "yes" {
-		enabled = true
-	} else {
-		enabled = false
-	}
+	err = common.AskConfigKey("backupConfig.enabled", prompt, &enabled, "")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -513,14 +517,14 @@ func queryBackupConfig() (*backupConfiguration, error) {
 		Message: "Define your backup provider",
 		Options: []string{"azure"},
 	}
-	err = survey.AskOne(prompt, &provider, common.WithValidator("required"))
+	err = common.AskConfigKey("backupConfig.provider", prompt, &provider, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
 	}
 
 	prompt = &survey.Input{Message: `Define the region in which your backup bucket is hosted.`}
-	err = survey.AskOne(prompt, &region, common.WithValidator("required"))
+	err = common.AskConfigKey("backupConfig.region", prompt, &region, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -530,7 +534,7 @@ func queryBackupConfig() (*backupConfiguration, error) {
 	}
 
 	prompt = &survey.Input{Message: `Define the name for the backup bucket.`}
-	err = survey.AskOne(prompt, &bucketName, common.WithValidator("required"))
+	err = common.AskConfigKey("backupConfig.bucketName", prompt, &bucketName, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -585,7 +589,7 @@ func (d *backupCredentialsAzure) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("backupConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -1,10 +1,10 @@
 package install
 
 type KeConfig struct {
-	Version          string              `yaml:"version"`
+	Version          string              `yaml:"version" validate:"required"`
 	BaseCluster      baseClusterConfig   `yaml:"baseCluster"`
 	Admin            admin               `yaml:"admin"`
-	ClusterIdentity  string              `yaml:"clusterIdentity"`
+	ClusterIdentity  string              `yaml:"clusterIdentity" validate:"required"`
 	Gardener         gardenerConfig      `yaml:"gardener"`
 	Gardenlet        gardenletConfig     `yaml:"gardenlet"`
 	KubeApiServer    kubeApiServerConfig `yaml:"kubeApiServer"`
@@ -13,40 +13,40 @@ type KeConfig struct {
 	DomainConfig     domainConfiguration `yaml:"domainConfig,omitempty"`
 	BackupConfig     backupConfiguration `yaml:"backupConfig,omitempty"`
 	ExtensionsConfig extensionsConfig    `yaml:"extensions"`
-	CloudProfiles    []string            `yaml:"cloudprofiles"`
+	CloudProfiles    []string            `yaml:"cloudprofiles" validate:"dive,required"`
 }
 
 type admin struct {
-	Email         string `yaml:"email"`
-	Password      string `yaml:"password"`
-	GitRepoURL    string `yaml:"gitrepourl"`
-	GitRepoBranch string `yaml:"gitrepobranch"`
+	Email         string `yaml:"email" validate:"required,email"`
+	Password      string `yaml:"password" validate:"required"`
+	GitRepoURL    string `yaml:"gitrepourl" validate:"required,url,startswith=ssh://"`
+	GitRepoBranch string `yaml:"gitrepobranch" validate:"required"`
 }
 
 type baseClusterConfig struct {
-	HasVerticalPodAutoscaler *bool  `yaml:"hasVerticalPodAutoscaler"`
-	Provider                 string `yaml:"provider"`
-	Region                   string `yaml:"region"`
-	NodeCidr                 string `yaml:"nodeCidr"`
+	HasVerticalPodAutoscaler *bool  `yaml:"hasVerticalPodAutoscaler" validate:"required"`
+	Provider                 string `yaml:"provider" validate:"required,oneof=hcloud azure aws openstack"`
+	Region                   string `yaml:"region" validate:"required"`
+	NodeCidr                 string `yaml:"nodeCidr" validate:"required,cidr"`
 }
 
 type gardenerConfig struct {
-	ClusterIP string `yaml:"clusterIP"`
+	ClusterIP string `yaml:"clusterIP" validate:"required,ip"`
 }
 
 type gardenletConfig struct {
-	SeedNodeCidr    string `yaml:"seedNodeCidr"`
-	SeedPodCidr     string `yaml:"seedPodCidr"`
-	SeedServiceCidr string `yaml:"seedServiceCidr"`
+	SeedNodeCidr    string `yaml:"seedNodeCidr" validate:"required,cidr"`
+	SeedPodCidr     string `yaml:"seedPodCidr" validate:"required,cidr"`
+	SeedServiceCidr string `yaml:"seedServiceCidr" validate:"required,cidr"`
 }
 
 type dashboardConfig struct {
-	ClientSecret  string `yaml:"clientSecret"`
-	SessionSecret string `yaml:"sessionSecret"`
+	ClientSecret  string `yaml:"clientSecret" validate:"required"`
+	SessionSecret string `yaml:"sessionSecret" validate:"required"`
 }
 
 type kubeApiServerConfig struct {
-	BasicAuthPassword string `yaml:"basicAuthPassword"`
+	BasicAuthPassword string `yaml:"basicAuthPassword" validate:"required"`
 }
 
 type issuerConfig struct {
@@ -55,51 +55,51 @@ type issuerConfig struct {
 }
 
 type acmeConfig struct {
-	Email  string `yaml:"email"`
-	Server string `yaml:"server,omitempty"`
+	Email  string `yaml:"email" validate:"required,email"`
+	Server string `yaml:"server,omitempty" validate:"omitempty,url"`
 }
 
 type domainConfiguration struct {
-	Domain      string      `yaml:"domain"`
-	Provider    string      `yaml:"provider"`
-	Credentials interface{} `yaml:"credentials"`
+	Domain      string      `yaml:"domain" validate:"required,fqdn"`
+	Provider    string      `yaml:"provider" validate:"required,oneof=azure-dns openstack-designate aws-route53"`
+	Credentials interface{} `yaml:"credentials" validate:"required"`
 }
 
 type dnsCredentialsAzure struct {
-	TenantId       string `yaml:"tenantID"`
-	SubscriptionId string `yaml:"subscriptionID"`
-	ClientId       string `yaml:"clientID"`
-	ClientSecret   string `yaml:"clientSecret"`
+	TenantId       string `yaml:"tenantID" validate:"required"`
+	SubscriptionId string `yaml:"subscriptionID" validate:"required"`
+	ClientId       string `yaml:"clientID" validate:"required"`
+	ClientSecret   string `yaml:"clientSecret" validate:"required"`
 }
 
 // https://github.com/gardener/external-dns-management/blob/master/examples/20-secret-openstack-credentials.yaml
 type dnsCredentialsOSDesignate struct {
-	ApplicationCredentialID     string `yaml:"OS_APPLICATION_CREDENTIAL_ID"`
-	ApplicationCredentialSecret string `yaml:"OS_APPLICATION_CREDENTIAL_SECRET"`
-	AuthURL                     string `yaml:"OS_AUTH_URL"`
+	ApplicationCredentialID     string `yaml:"OS_APPLICATION_CREDENTIAL_ID" validate:"required"`
+	ApplicationCredentialSecret string `yaml:"OS_APPLICATION_CREDENTIAL_SECRET" validate:"required"`
+	AuthURL                     string `yaml:"OS_AUTH_URL" validate:"required,encodedurl"`
 }
 
 // https://github.com/gardener/external-dns-management/blob/master/examples/20-secret-aws-credentials.yaml
 type dnsCredentialsAWS53 struct {
-	AccessKeyID     string `yaml:"AWS_ACCESS_KEY_ID"`
-	SecretAccessKey string `yaml:"AWS_SECRET_ACCESS_KEY"`
+	AccessKeyID     string `yaml:"AWS_ACCESS_KEY_ID" validate:"required"`
+	SecretAccessKey string `yaml:"AWS_SECRET_ACCESS_KEY" validate:"required"`
 }
 
 type backupConfiguration struct {
 	Enabled     bool        `yaml:"enabled"`
-	Provider    string      `yaml:"provider,omitempty"`
-	Region      string      `yaml:"region,omitempty"`
-	BucketName  string      `yaml:"bucketName,omitempty"`
-	Credentials interface{} `yaml:"credentials,omitempty"`
+	Provider    string      `yaml:"provider,omitempty" validate:"required_if=Enabled true,omitempty,oneof=azure"`
+	Region      string      `yaml:"region,omitempty" validate:"required_if=Enabled true"`
+	BucketName  string      `yaml:"bucketName,omitempty" validate:"required_if=Enabled true"`
+	Credentials interface{} `yaml:"credentials,omitempty" validate:"required_if=Enabled true"`
 }
 
 type backupCredentialsAzure struct {
-	TenantId                string `yaml:"tenantID"`
-	SubscriptionId          string `yaml:"subscriptionID"`
-	ClientId                string `yaml:"clientID"`
-	ClientSecret            string `yaml:"clientSecret"`
-	StorageAccount          string `yaml:"storageAccount"`
-	StorageAccountAccessKey string `yaml:"storageAccountAccessKey"`
+	TenantId                string `yaml:"tenantID" validate:"required"`
+	SubscriptionId          string `yaml:"subscriptionID" validate:"required"`
+	ClientId                string `yaml:"clientID" validate:"required"`
+	ClientSecret            string `yaml:"clientSecret" validate:"required"`
+	StorageAccount          string `yaml:"storageAccount" validate:"required"`
+	StorageAccountAccessKey string `yaml:"storageAccountAccessKey" validate:"required"`
 }
 
 type extensionsConfig map[string]map[string]bool
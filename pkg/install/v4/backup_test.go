@@ -0,0 +1,46 @@
+package install_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backup providers", func() {
+	DescribeTable("renders the credentials into the '23ke-config' secret",
+		func(provider string, credentials map[string]interface{}, expectedCredentials map[string]string) {
+			// credentials read from a config file are plain maps with lowercased keys
+			setConfig("backupConfig", map[string]interface{}{
+				"enabled":     true,
+				"provider":    provider,
+				"region":      "my-region",
+				"bucketname":  "my-bucket",
+				"credentials": credentials,
+			})
+
+			values := renderConfigSecretValues()
+			Expect(values.Backups.Enabled).To(BeTrue())
+			Expect(values.Backups.Provider).To(Equal(provider))
+			Expect(values.Backups.Credentials).To(Equal(expectedCredentials))
+		},
+		Entry("aws",
+			"aws",
+			map[string]interface{}{"accesskeyid": "my-key-id", "secretaccesskey": "my-secret"},
+			map[string]string{"accessKeyID": "my-key-id", "secretAccessKey": "my-secret"},
+		),
+		Entry("gcp",
+			"gcp",
+			map[string]interface{}{"serviceaccountjson": "eyJ0eXBlIjoic2VydmljZV9hY2NvdW50In0="},
+			map[string]string{"serviceAccountJSON": "eyJ0eXBlIjoic2VydmljZV9hY2NvdW50In0="},
+		),
+		Entry("openstack",
+			"openstack",
+			map[string]interface{}{"applicationcredentialid": "my-id", "applicationcredentialsecret": "my-secret", "authurl": "https://keystone.example.org/v3"},
+			map[string]string{"applicationCredentialID": "my-id", "applicationCredentialSecret": "my-secret", "authURL": "https://keystone.example.org/v3"},
+		),
+		Entry("s3",
+			"s3",
+			map[string]interface{}{"accesskeyid": "my-key-id", "secretaccesskey": "my-secret", "endpoint": "https://minio.example.org"},
+			map[string]string{"accessKeyID": "my-key-id", "secretAccessKey": "my-secret", "endpoint": "https://minio.example.org"},
+		),
+	)
+})
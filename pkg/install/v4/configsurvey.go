@@ -2,15 +2,16 @@ package install
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
-	"strings"
+	"os"
+	"reflect"
 
+	"github.com/23technologies/23kectl/pkg/clusterdetect"
 	"github.com/23technologies/23kectl/pkg/common"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/netdetect"
 
 	"github.com/23technologies/23kectl/pkg/logger"
 	"github.com/AlecAivazis/survey/v2"
@@ -28,7 +29,7 @@ func queryConfig(kubeClient client.Client) error {
 		return err
 	}
 
-	err = viper.WriteConfig()
+	err = common.WriteConfig()
 	if err != nil {
 		log.Info("Viper couldn't write config file", "error", err)
 	}
@@ -38,7 +39,12 @@ func queryConfig(kubeClient client.Client) error {
 		return err
 	}
 
-	err = queryBaseClusterConfig()
+	err = queryBaseClusterConfig(kubeClient)
+	if err != nil {
+		return err
+	}
+
+	err = common.CheckMissingConfigKeys()
 	if err != nil {
 		return err
 	}
@@ -47,10 +53,8 @@ func queryConfig(kubeClient client.Client) error {
 		viper.Set("cloudprofiles", []string{"alicloud", "aws", "azure", "gcp", "hcloud", "regiocloud", "wavestack"})
 	}
 
-	// enable the provider extensions needed for a minimal setup
-	viper.Set("extensionsConfig.provider-"+viper.GetString("baseCluster.provider")+".enabled", true)
-	viper.Set("extensionsConfig."+common.DNS_PROVIDER_TO_PROVIDER[viper.GetString("domainConfig.provider")]+".enabled", true)
-	err = viper.WriteConfig()
+	enableProviderExtensions()
+	err = common.WriteConfig()
 	if err != nil {
 		return err
 	}
@@ -59,84 +63,100 @@ func queryConfig(kubeClient client.Client) error {
 
 }
 
+// enableProviderExtensions enables the provider extensions needed for a minimal
+// setup: the ones of the base cluster, the additional seeds, the DNS provider
+// and the backup bucket.
+func enableProviderExtensions() {
+	viper.Set("extensionsConfig."+common.BASE_CLUSTER_PROVIDER_TO_PROVIDER[viper.GetString("baseCluster.provider")]+".enabled", true)
+	var seeds []seedConfig
+	_ = viper.UnmarshalKey("seeds", &seeds)
+	for _, seed := range seeds {
+		if provider, ok := common.BASE_CLUSTER_PROVIDER_TO_PROVIDER[seed.Provider]; ok {
+			viper.Set("extensionsConfig."+provider+".enabled", true)
+		}
+	}
+	viper.Set("extensionsConfig."+common.DNS_PROVIDER_TO_PROVIDER[viper.GetString("domainConfig.provider")]+".enabled", true)
+	if viper.GetBool("backupConfig.enabled") {
+		viper.Set("extensionsConfig."+common.BUCKET_PROVIDER_TO_PROVIDER[viper.GetString("backupConfig.provider")]+".enabled", true)
+	}
+}
+
 func completeKeConfig(kubeClient client.Client) error {
 	viper.SetDefault("clusterIdentity", "garden-cluster-"+common.RandHex(5)+"-identity")
 
-	Container.QueryConfigKey("gardenlet.seedPodCidr", func() (any, error) {
-		// If either calico or cilium are used as CNI we can pull the needed info from the cluster
-		// Otherwise prompt the user
-
-		// CALICO
-		// If calico's installed there's an ippool with name "default-ipv4-ippool".
-		ipPool := unstructured.Unstructured{}
-		gvk := schema.GroupVersionKind{
-			Group:   "crd.projectcalico.org",
-			Version: "v1",
-			Kind:    "ippool",
-		}
-		ipPool.SetGroupVersionKind(gvk)
-		err := kubeClient.Get(context.Background(), client.ObjectKey{
-			Namespace: "",
-			Name:      "default-ipv4-ippool",
-		}, &ipPool)
-		if err == nil {
-			return ipPool.Object["spec"].(map[string]interface{})["cidr"].(string), nil
+	// detect lazily, the probes aren't needed once the config is complete
+	var networks *netdetect.Networks
+	detectNetworks := func() netdetect.Networks {
+		if networks == nil {
+			detected := netdetect.Detect(context.Background(), kubeClient, netdetect.Detectors())
+			networks = &detected
 		}
+		return *networks
+	}
 
-		// CILIUM
-		// cilium uses a configmap "cilium-config" in the kube-system namespace
-		ciliumConfig := corev1.ConfigMap{}
-		err = kubeClient.Get(context.Background(), client.ObjectKey{
-			Namespace: "kube-system",
-			Name:      "cilium-config",
-		}, &ciliumConfig)
-		if err == nil {
-			return ciliumConfig.Data["cluster-pool-ipv4-cidr"], nil
-		}
+	Container.QueryConfigKey("gardenlet.seedPodCidr", func() (any, error) {
+		return queryDetectedCidr("pod CIDR", detectNetworks().PodCidr)
+	})
 
-		// UNKNOWN
-		// let's prompt for the Pod CIDR
-		prompt := &survey.Input{
-			Message: "Please enter the pod CIDR of your base cluster in the form: x.x.x.x/y",
-		}
-		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,cidr"))
-		common.ExitOnCtrlC(err)
-		if err != nil {
-			return nil, err
-		}
-		return queryResult, nil
+	Container.QueryConfigKey("gardenlet.seedServiceCidr", func() (any, error) {
+		return queryDetectedCidr("service CIDR", detectNetworks().ServiceCidr)
 	})
 
-	if !viper.IsSet("gardenlet.seedServiceCidr") {
-		dummySvc := &corev1.Service{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      "dummy",
-				Namespace: "default",
-			},
-			Spec: corev1.ServiceSpec{
-				Ports:     []corev1.ServicePort{{Name: "port", Port: 443}},
-				ClusterIP: "1.1.1.1",
-			},
+	Container.QueryConfigKey("gardener.clusterIP", func() (any, error) {
+		return clusterIPOf(viper.GetString("gardenlet.seedServiceCidr"))
+	})
+
+	return nil
+}
+
+// queryDetectedCidr asks to confirm a CIDR detected in the base cluster, or to enter
+// it if none was detected. In non-interactive mode detected CIDRs are used as they are.
+func queryDetectedCidr(name string, detected *netdetect.Finding) (any, error) {
+	prompt := &survey.Input{
+		Message: fmt.Sprintf("Please enter the %s of your base cluster in the form: x.x.x.x/y", name),
+	}
+
+	if detected != nil {
+		if common.IsNonInteractive() {
+			fmt.Printf("Using the %s %s detected from %s\n", name, detected.Cidr, detected.Source)
+			return detected.Cidr, nil
+		}
+
+		prompt = &survey.Input{
+			Message: fmt.Sprintf("Please confirm the %s of your base cluster in the form: x.x.x.x/y", name),
+			Default: detected.Cidr,
+			Help: fmt.Sprintf(`
+I detected %s from %s.
+Detection is best effort, correct it if it doesn't match your cluster.
+`, detected.Cidr, detected.Source),
 		}
-		dummyErr := Container.Create(context.Background(), dummySvc)
-		viper.Set("gardenlet.seedServiceCidr", strings.SplitAfter(dummyErr.Error(), "The range of valid IPs is ")[1])
 	}
 
-	if !viper.IsSet("gardener.clusterIP") {
-		seedServiceCidr := viper.GetString("gardenlet.seedServiceCidr")
-		clusterIp, ipnet, _ := net.ParseCIDR(seedServiceCidr)
+	var queryResult string
+	err := common.AskOne(prompt, &queryResult, "required,cidr")
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return nil, err
+	}
+	return queryResult, nil
+}
 
-		// clusterIp[len(clusterIp)-2] += 1
-		clusterIp[len(clusterIp)-1] += 100
+// clusterIPOf returns the ClusterIP of the gardener apiserver, the 100th address of the
+// service CIDR.
+func clusterIPOf(serviceCidr string) (string, error) {
+	clusterIp, ipnet, err := net.ParseCIDR(serviceCidr)
+	if err != nil {
+		return "", fmt.Errorf("the service CIDR '%s' is invalid: %w", serviceCidr, err)
+	}
 
-		if !ipnet.Contains(clusterIp) {
-			panic(fmt.Sprintf("Your cluster ip (%s) is out of the service IP range: %s", clusterIp, ipnet.String()))
-		}
-		viper.Set("gardener.clusterIP", clusterIp.String())
+	clusterIp = clusterIp.Mask(ipnet.Mask)
+	clusterIp[len(clusterIp)-1] += 100
+
+	if !ipnet.Contains(clusterIp) {
+		return "", fmt.Errorf("your cluster ip (%s) is out of the service IP range: %s", clusterIp, ipnet.String())
 	}
 
-	return nil
+	return clusterIp.String(), nil
 }
 
 // queryAdminConfig ...
@@ -150,7 +170,7 @@ func queryAdminConfig() error {
 This will be the email address to use, when you want to login to the Gardener dashboard.`,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,email"))
+		err = common.AskOne(prompt, &queryResult, "required,email")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return "", err
@@ -165,7 +185,7 @@ This will be the email address to use, when you want to login to the Gardener da
 This will be the password to use, when you login to the Gardener dashboard.`,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return "", err
@@ -182,14 +202,15 @@ This will be the password to use, when you login to the Gardener dashboard.`,
 
 	Container.QueryConfigKey("admin.gitrepourl", func() (any, error) {
 		prompt = &survey.Input{
-			Message: "Please enter an ssh git remote in URL form. e.g. ssh://git@github.com/User/Repo.git",
+			Message: "Please enter an ssh or https git remote in URL form. e.g. ssh://git@github.com/User/Repo.git",
 			Help: `
 Configuration files are to be stored in this repo.
 Flux will monitor these files to pick up configuration changes.
+https remotes are authenticated with an access token instead of a deploy key.
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,url,startswith=ssh://"))
+		err = common.AskOne(prompt, &queryResult, "required,url,gitremote")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -197,6 +218,38 @@ Flux will monitor these files to pick up configuration changes.
 		return queryResult, nil
 	})
 
+	if isHTTPSRemote(viper.GetString("admin.gitrepourl")) {
+		Container.QueryConfigKey("admin.gitUsername", func() (any, error) {
+			prompt = &survey.Input{
+				Message: "Please enter the username for the https git remote.",
+				Default: DEFAULT_GIT_USERNAME,
+				Help: `
+Most git providers accept any username in combination with an access token.
+`,
+			}
+			var queryResult string
+			err = common.AskOne(prompt, &queryResult, "required")
+			common.ExitOnCtrlC(err)
+			if err != nil {
+				return nil, err
+			}
+			return queryResult, nil
+		})
+
+		Container.QueryConfigKey("admin.gitToken", func() (any, error) {
+			prompt = &survey.Password{
+				Message: "Please enter an access token for the https git remote. It needs write access.",
+			}
+			var queryResult string
+			err = common.AskOne(prompt, &queryResult, "required")
+			common.ExitOnCtrlC(err)
+			if err != nil {
+				return nil, err
+			}
+			return queryResult, nil
+		})
+	}
+
 	Container.QueryConfigKey("admin.gitrepobranch", func() (any, error) {
 		prompt = &survey.Input{
 			Message: "Please enter the git branch to use. Will be created if it doesn't exist.",
@@ -207,7 +260,7 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 `,
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(prompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -221,7 +274,7 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 			Default: viper.GetString("admin.email"),
 		}
 		var queryResult string
-		err := survey.AskOne(prompt, &queryResult, common.WithValidator("required,email"))
+		err := common.AskOne(prompt, &queryResult, "required,email")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -230,13 +283,12 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 		return queryResult, nil
 	})
 
-	Container.QueryConfigKey("domainConfig", func() (any, error) {
-		domainConfig, err := queryDomainConfig()
-		if err != nil {
-			return nil, err
-		}
-		return domainConfig, nil
+	err = Container.QueryConfigKey("domainConfig", func() (any, error) {
+		return queryVerifiedDomainConfig()
 	})
+	if err != nil {
+		return err
+	}
 
 	Container.QueryConfigKey("backupConfig", func() (any, error) {
 		backupConfig, err := queryBackupConfig()
@@ -249,22 +301,58 @@ You can store configuration files for multiple gardeners (e.g. prod, staging, de
 	return nil
 }
 
-func queryBaseClusterConfig() error {
+// examples shown in the help of the region prompt
+var regionExamples = map[string]string{
+	common.BASE_CLUSTER_PROVIDER_HCLOUD:    "fsn1 or hel1",
+	common.BASE_CLUSTER_PROVIDER_AZURE:     "germanywestcentral or westeurope",
+	common.BASE_CLUSTER_PROVIDER_AWS:       "eu-central-1 or us-east-2",
+	common.BASE_CLUSTER_PROVIDER_OPENSTACK: "RegionOne",
+	common.BASE_CLUSTER_PROVIDER_GCP:       "europe-west3 or us-central1",
+	common.BASE_CLUSTER_PROVIDER_ALICLOUD:  "eu-central-1 or cn-hangzhou",
+}
+
+func queryBaseClusterConfig(kubeClient client.Client) error {
 	var err error
-	var prompt survey.Prompt
+
+	// detect lazily, nothing is needed once the config is complete
+	var detected *clusterdetect.BaseCluster
+	detect := func() clusterdetect.BaseCluster {
+		if detected == nil {
+			baseCluster, err := clusterdetect.Detect(context.Background(), kubeClient)
+			if err != nil {
+				logger.Get("queryBaseClusterConfig").Info("Couldn't detect the base cluster config", "error", err)
+			}
+			detected = &baseCluster
+		}
+		return *detected
+	}
+
+	providers := common.BASE_CLUSTER_PROVIDERS
 
 	// todo explain to user. what's this for?
 	Container.QueryConfigKey("baseCluster.provider", func() (any, error) {
-		prompt = &survey.Select{
+		provider := detect().Provider
+		if provider != nil && !utils.ContainsItemString(providers, provider.Value) {
+			common.PrintWarn(fmt.Sprintf("Your base cluster runs on %s, which isn't supported as a base cluster provider yet.", provider.Value))
+			provider = nil
+		}
+		if useDetected("provider", provider) {
+			return provider.Value, nil
+		}
+
+		selectPrompt := &survey.Select{
 			Message: "Select the provider of your base cluster",
-			Options: []string{"hcloud", "azure", "aws", "openstack"},
+			Options: providers,
 			Help: `
 Currently, this tools supports the listed providers for base clusters.
 If you feel like this list in incomplete, contact the 23T support.
-`,
+` + detectedHelp(provider),
+		}
+		if provider != nil {
+			selectPrompt.Default = provider.Value
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(selectPrompt, &queryResult, "required")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -273,16 +361,29 @@ If you feel like this list in incomplete, contact the 23T support.
 	})
 
 	Container.QueryConfigKey("baseCluster.Region", func() (any, error) {
-		prompt = &survey.Input{
+		provider := viper.GetString("baseCluster.provider")
+		region := detect().Region
+		if region != nil && !common.IsValidRegion(provider, region.Value) {
+			common.PrintWarn(fmt.Sprintf("The detected region %s isn't a region of %s.", region.Value, provider))
+			region = nil
+		}
+		if useDetected("region", region) {
+			return region.Value, nil
+		}
+
+		inputPrompt := &survey.Input{
 			Message: "Please enter the region of your base cluster",
 			Help: `
 This is the region your base cluster runs in.
 Generally this is dependent on the provider of your base cluster.
-For clusters hosted on Azure, this could be e.g. germanywestcentral or westeurope.
-`,
+For clusters hosted on ` + provider + `, this could be e.g. ` + regionExamples[provider] + `.
+` + detectedHelp(region),
+		}
+		if region != nil {
+			inputPrompt.Default = region.Value
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required"))
+		err = common.AskOne(inputPrompt, &queryResult, "required,region="+provider)
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -290,17 +391,24 @@ For clusters hosted on Azure, this could be e.g. germanywestcentral or westeurop
 		return queryResult, nil
 	})
 
-	// todo explain to user. document where to find it on supported providers
 	Container.QueryConfigKey("baseCluster.nodeCidr", func() (any, error) {
-		prompt = &survey.Input{
+		nodeCidr := detect().NodeCidr
+		if useDetected("node CIDR", nodeCidr) {
+			return nodeCidr.Value, nil
+		}
+
+		inputPrompt := &survey.Input{
 			Message: "Please enter the node CIDR of your base cluster in the form: x.x.x.x/y",
 			Help: `
 Gardener will check whether the nodes' ip addresses of your base cluster lie in the specified network.
 Therefore, the node CIDR should match a network that comprises all ip addresses of your nodes.
-`,
+` + detectedHelp(nodeCidr),
+		}
+		if nodeCidr != nil {
+			inputPrompt.Default = nodeCidr.Value
 		}
 		var queryResult string
-		err = survey.AskOne(prompt, &queryResult, common.WithValidator("required,cidr"))
+		err = common.AskOne(inputPrompt, &queryResult, "required,cidr")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return nil, err
@@ -319,19 +427,39 @@ Therefore, the node CIDR should match a network that comprises all ip addresses
 			iDontKnow = "I don't know"
 		)
 
-		prompt = &survey.Select{
+		var vpa *clusterdetect.Finding
+		if hasVpa := detect().HasVerticalPodAutoscaler; hasVpa != nil {
+			vpa = &clusterdetect.Finding{Value: no, Source: hasVpa.Source}
+			if hasVpa.Present {
+				vpa.Value = yes
+			}
+		}
+		if useDetected("answer to whether the base cluster provides a VPA", vpa) {
+			return vpa.Value == yes, nil
+		}
+
+		selectPrompt := &survey.Select{
 			Message: "Does your base cluster provide vertical pod autoscaling (VPA)?",
 			Options: []string{yes, no, iDontKnow},
 			Help: `
 Depending on your provider and setup, your base cluster may or may not provide this functionality.
 If it doesn't, we'll install everything necessary for gardener to work.
-Automatically detecting VPA from within the cluster isn't reliable, so if you choose "I don't know" a VPA is installed just in case. You might end up with two autoscalers, which will generally work for evaluation but causes unexpected behavior like very frequent pod restarts
-`,
+The VPA is detected by its CRD and a running recommender. If the CRD exists, but the recommender runs outside of the cluster, it can't be detected.
+If you choose "I don't know" a VPA is installed just in case. You might end up with two autoscalers, which will generally work for evaluation but causes unexpected behavior like very frequent pod restarts
+` + detectedHelp(vpa),
+		}
+		if vpa != nil {
+			selectPrompt.Default = vpa.Value
 		}
 
 		var queryResult string
 
-		err = survey.AskOne(prompt, &queryResult)
+		if common.IsNonInteractive() {
+			// the answer is stored as a bool rather than the selected option
+			return nil, &common.PromptError{Type: "bool", Message: "Does your base cluster provide vertical pod autoscaling (VPA)?"}
+		}
+
+		err = common.AskOne(selectPrompt, &queryResult, "")
 		common.ExitOnCtrlC(err)
 		if err != nil {
 			return false, err
@@ -358,6 +486,59 @@ Gardener will work but you'll see lots of pod restarts. Not recommended for prod
 	return nil
 }
 
+// useDetected reports whether a detected value is used without asking, which is the
+// case in non-interactive mode. Otherwise it's offered as the default.
+func useDetected(name string, detected *clusterdetect.Finding) bool {
+	if detected == nil || !common.IsNonInteractive() {
+		return false
+	}
+
+	fmt.Printf("Using the %s %s detected from %s\n", name, detected.Value, detected.Source)
+	return true
+}
+
+// detectedHelp explains where the default of a prompt was detected.
+func detectedHelp(detected *clusterdetect.Finding) string {
+	if detected == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+I detected %s from %s.
+`, detected.Value, detected.Source)
+}
+
+// queryVerifiedDomainConfig asks for the domain config until it passes Container.VerifyDomainConfig,
+// so that DNS credentials which don't work are never written to the config file.
+func queryVerifiedDomainConfig() (any, error) {
+	var previous *domainConfiguration
+
+	for {
+		domainConfig, err := queryDomainConfig()
+		if common.CheckMissingConfigKeys() != nil {
+			// reported together with all other missing keys
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = Container.VerifyDomainConfig(&KeConfig{DomainConfig: *domainConfig})
+		if err == nil {
+			return domainConfig, nil
+		}
+
+		// asking again doesn't help if the answers are set by environment variables
+		if common.IsNonInteractive() || reflect.DeepEqual(domainConfig, previous) {
+			return nil, err
+		}
+		previous = domainConfig
+
+		common.PrintWarn(err.Error())
+		fmt.Println("Please enter the domain config again.")
+	}
+}
+
 func queryDomainConfig() (*domainConfiguration, error) {
 	var err error
 	var domain, provider string
@@ -368,7 +549,7 @@ func queryDomainConfig() (*domainConfiguration, error) {
 Gardener components will be available as subdomains of this (e.g dashboard.<gardener.my-company.io>).
 Note that it has to be delegated to the chosen DNS provider.`,
 	}
-	err = survey.AskOne(prompt, &domain, common.WithValidator("required,fqdn"))
+	err = common.AskConfigKey("domainConfig.domain", prompt, &domain, "required,fqdn")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -376,15 +557,25 @@ Note that it has to be delegated to the chosen DNS provider.`,
 
 	prompt = &survey.Select{
 		Message: "Define your DNS provider",
-		Options: []string{common.DNS_PROVIDER_AZURE_DNS, common.DNS_PROVIDER_OPENSTACK_DESIGNATE, common.DNS_PROVIDER_AWS_ROUTE_53},
+		Options: []string{
+			common.DNS_PROVIDER_AZURE_DNS,
+			common.DNS_PROVIDER_AZURE_PRIVATE_DNS,
+			common.DNS_PROVIDER_OPENSTACK_DESIGNATE,
+			common.DNS_PROVIDER_AWS_ROUTE_53,
+			common.DNS_PROVIDER_GOOGLE_CLOUDDNS,
+			common.DNS_PROVIDER_ALICLOUD_DNS,
+		},
 	}
-	err = survey.AskOne(prompt, &provider, common.WithValidator("required"))
+	err = common.AskConfigKey("domainConfig.provider", prompt, &provider, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
 	}
 
-	domainConfig, _ := createDomainConfiguration(domain, provider)
+	domainConfig, err := createDomainConfiguration(domain, provider)
+	if err != nil {
+		return nil, err
+	}
 	return &domainConfig, nil
 }
 
@@ -416,7 +607,7 @@ func (d *dnsCredentialsAzure) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -441,13 +632,13 @@ func (d *dnsCredentialsOSDesignate) parseCredentials() error {
 		},
 		{
 			Name:      "AuthURL",
-			Prompt:    &survey.Input{Message: "AuthURL? (plain or base64)"},
+			Prompt:    &survey.Input{Message: "AuthURL? e.g. https://keystone.example.org:5000/v3"},
 			Validate:  common.MakeValidatorFn("required,url"),
 			Transform: survey.TransformString(common.CoerceBase64String),
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -471,7 +662,37 @@ func (d *dnsCredentialsAWS53) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("domainConfig.credentials", qs, d)
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *dnsCredentialsGCP) parseCredentials() error {
+	return common.QueryNestedConfigKey("domainConfig.credentials.serviceAccountJSON", &d.ServiceAccountJSON, func() error {
+		return queryServiceAccountJSON(&d.ServiceAccountJSON, "DNS Administrator", "Cloud DNS zone")
+	})
+}
+
+func (d *dnsCredentialsAlicloud) parseCredentials() error {
+	qs := []*survey.Question{
+		{
+			Name:      "AccessKeyID",
+			Prompt:    &survey.Input{Message: "Alicloud Access Key ID? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+		{
+			Name:      "AccessKeySecret",
+			Prompt:    &survey.Input{Message: "Alicloud Access Key Secret? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+	}
+
+	err := common.Ask("domainConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
@@ -479,23 +700,51 @@ func (d *dnsCredentialsAWS53) parseCredentials() error {
 	return nil
 }
 
+// queryServiceAccountJSON asks for the JSON key file of a GCP service account with the
+// given role and stores its base64 encoded content in serviceAccountJSON.
+func queryServiceAccountJSON(serviceAccountJSON *string, role string, what string) error {
+	if common.IsNonInteractive() {
+		// the config holds the key itself rather than the path of the key file
+		return &common.PromptError{Type: "string", Validator: "required", Message: "base64 encoded JSON key of the GCP service account"}
+	}
+
+	prompt := &survey.Input{
+		Message: "Path to the JSON key file of the GCP service account?",
+		Help:    fmt.Sprintf("The service account needs the role '%s' in the project of your %s.", role, what),
+	}
+
+	var keyFile string
+	err := common.AskOne(prompt, &keyFile, "required,file")
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return err
+	}
+
+	*serviceAccountJSON, err = readFileBase64(keyFile)
+	return err
+}
+
+// readFileBase64 returns the base64 encoded content of the file at path.
+func readFileBase64(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read %s: %w", path, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(content), nil
+}
+
 func queryBackupConfig() (*backupConfiguration, error) {
 	var err error
-	var region, provider, bucketName, configureBackups string
+	var region, provider, bucketName string
 	var enabled bool
 	var prompt survey.Prompt
 
-	prompt = &survey.Select{
+	prompt = &survey.Confirm{
 		Message: `Please tell me whether you want to configure the backup functionality.`,
-		Options: []string{"yes", "no"},
 	}
 
-	err = survey.AskOne(prompt, &configureBackups, common.WithValidator("required"))
-	if configureBackups == "yes" {
-		enabled = true
-	} else {
-		enabled = false
-	}
+	err = common.AskConfigKey("backupConfig.enabled", prompt, &enabled, "")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -508,16 +757,25 @@ func queryBackupConfig() (*backupConfiguration, error) {
 
 	prompt = &survey.Select{
 		Message: "Define your backup provider",
-		Options: []string{"azure"},
+		Options: []string{
+			common.BUCKET_PROVIDER_AZURE,
+			common.BUCKET_PROVIDER_AWS,
+			common.BUCKET_PROVIDER_GCP,
+			common.BUCKET_PROVIDER_OPENSTACK,
+			common.BUCKET_PROVIDER_S3,
+		},
+		Help: `
+Choose s3 for any other S3 compatible object storage, e.g. MinIO.
+`,
 	}
-	err = survey.AskOne(prompt, &provider, common.WithValidator("required"))
+	err = common.AskConfigKey("backupConfig.provider", prompt, &provider, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
 	}
 
 	prompt = &survey.Input{Message: `Define the region in which your backup bucket is hosted.`}
-	err = survey.AskOne(prompt, &region, common.WithValidator("required"))
+	err = common.AskConfigKey("backupConfig.region", prompt, &region, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -527,7 +785,7 @@ func queryBackupConfig() (*backupConfiguration, error) {
 	}
 
 	prompt = &survey.Input{Message: `Define the name for the backup bucket.`}
-	err = survey.AskOne(prompt, &bucketName, common.WithValidator("required"))
+	err = common.AskConfigKey("backupConfig.bucketName", prompt, &bucketName, "required")
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return nil, err
@@ -536,7 +794,10 @@ func queryBackupConfig() (*backupConfiguration, error) {
 		return nil, nil
 	}
 
-	backupConfig, _ := createBackupConfiguration(provider)
+	backupConfig, err := createBackupConfiguration(provider)
+	if err != nil {
+		return nil, err
+	}
 	backupConfig.Region = region
 	backupConfig.BucketName = bucketName
 	return &backupConfig, nil
@@ -582,7 +843,100 @@ func (d *backupCredentialsAzure) parseCredentials() error {
 		},
 	}
 
-	err := survey.Ask(qs, d)
+	err := common.Ask("backupConfig.credentials", qs, d)
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *backupCredentialsAWS) parseCredentials() error {
+	qs := []*survey.Question{
+		{
+			Name:      "AccessKeyID",
+			Prompt:    &survey.Input{Message: "AWS Access Key ID? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+		{
+			Name:      "SecretAccessKey",
+			Prompt:    &survey.Input{Message: "AWS Secret Access Key? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+	}
+
+	err := common.Ask("backupConfig.credentials", qs, d)
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *backupCredentialsGCP) parseCredentials() error {
+	return common.QueryNestedConfigKey("backupConfig.credentials.serviceAccountJSON", &d.ServiceAccountJSON, func() error {
+		return queryServiceAccountJSON(&d.ServiceAccountJSON, "Storage Admin", "backup bucket")
+	})
+}
+
+func (d *backupCredentialsOpenStack) parseCredentials() error {
+	qs := []*survey.Question{
+		{
+			Name:      "ApplicationCredentialID",
+			Prompt:    &survey.Input{Message: "Application Credential ID? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+		{
+			Name:      "ApplicationCredentialSecret",
+			Prompt:    &survey.Input{Message: "Application Credential Secret? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+		{
+			Name:      "AuthURL",
+			Prompt:    &survey.Input{Message: "AuthURL? e.g. https://keystone.example.org:5000/v3"},
+			Validate:  common.MakeValidatorFn("required,url"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+	}
+
+	err := common.Ask("backupConfig.credentials", qs, d)
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *backupCredentialsS3) parseCredentials() error {
+	qs := []*survey.Question{
+		{
+			Name:      "Endpoint",
+			Prompt:    &survey.Input{Message: "S3 endpoint? e.g. https://minio.example.org"},
+			Validate:  common.MakeValidatorFn("required,url"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+		{
+			Name:      "AccessKeyID",
+			Prompt:    &survey.Input{Message: "Access Key ID? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+		{
+			Name:      "SecretAccessKey",
+			Prompt:    &survey.Input{Message: "Secret Access Key? (plain or base64)"},
+			Validate:  common.MakeValidatorFn("required"),
+			Transform: survey.TransformString(common.CoerceBase64String),
+		},
+	}
+
+	err := common.Ask("backupConfig.credentials", qs, d)
 	common.ExitOnCtrlC(err)
 	if err != nil {
 		return err
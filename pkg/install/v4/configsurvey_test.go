@@ -0,0 +1,202 @@
+package install_test
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/clusterdetect"
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/23technologies/23kectl/pkg/netdetect"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("queryConfig in non-interactive mode", func() {
+	var configFile string
+
+	BeforeEach(func() {
+		common.SetNonInteractive(true)
+		DeferCleanup(common.SetNonInteractive, false)
+
+		// set up like the root command
+		viper.SetEnvPrefix(common.ENV_PREFIX)
+		viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		viper.AutomaticEnv()
+
+		previousConfigFile := viper.ConfigFileUsed()
+		DeferCleanup(func() {
+			if previousConfigFile != "" {
+				viper.SetConfigFile(previousConfigFile)
+			}
+		})
+		configFile = path.Join(GinkgoT().TempDir(), "config.yaml")
+		viper.SetConfigFile(configFile)
+
+		queryConfigKey := install.Container.QueryConfigKey
+		DeferCleanup(func() { install.Container.QueryConfigKey = queryConfigKey })
+		install.Container.QueryConfigKey = common.QueryConfigKey
+
+		verifyDomainConfig := install.Container.VerifyDomainConfig
+		DeferCleanup(func() { install.Container.VerifyDomainConfig = verifyDomainConfig })
+		install.Container.VerifyDomainConfig = func(_ *install.KeConfig) error { return nil }
+
+		// the keys stored by queryConfig
+		for _, key := range []string{"domainConfig", "backupConfig", "cloudprofiles", "extensionsConfig", "gardener.clusterIP", "gardenlet.seedNodeCidr"} {
+			setConfig(key, nil)
+		}
+	})
+
+	setEnv := func(env map[string]string) {
+		for key, value := range env {
+			GinkgoT().Setenv(common.ENV_PREFIX+"_"+key, value)
+		}
+	}
+
+	completeEnv := func() {
+		setEnv(map[string]string{
+			"ADMIN_EMAIL":                                    "admin@example.org",
+			"ADMIN_PASSWORD":                                 "my-password-hash",
+			"ADMIN_GITREPOURL":                               "ssh://git@github.com/User/Repo.git",
+			"ADMIN_GITREPOBRANCH":                            "main",
+			"ISSUER_ACME_EMAIL":                              "admin@example.org",
+			"DOMAINCONFIG_DOMAIN":                            "gardener.example.org",
+			"DOMAINCONFIG_PROVIDER":                          "aws-route53",
+			"DOMAINCONFIG_CREDENTIALS_AWS_ACCESS_KEY_ID":     "bXktYWNjZXNzLWtleS1pZA==",
+			"DOMAINCONFIG_CREDENTIALS_AWS_SECRET_ACCESS_KEY": "bXktc2VjcmV0LWFjY2Vzcy1rZXk=",
+			"BACKUPCONFIG_ENABLED":                           "false",
+			"BASECLUSTER_PROVIDER":                           "hcloud",
+			"BASECLUSTER_REGION":                             "hel1",
+			"BASECLUSTER_NODECIDR":                           "10.250.0.0/16",
+			"BASECLUSTER_HASVERTICALPODAUTOSCALER":           "false",
+			"GARDENLET_SEEDPODCIDR":                          "100.73.0.0/16",
+			"GARDENLET_SEEDSERVICECIDR":                      "100.88.0.0/13",
+		})
+	}
+
+	It("takes a complete config from environment variables", func() {
+		completeEnv()
+
+		kubeClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).Build()
+		Expect(install.QueryConfig(kubeClient)).To(Succeed())
+
+		var config install.KeConfig
+		Expect(install.UnmarshalKeConfig(&config)).To(Succeed())
+		Expect(config.Admin.Email).To(Equal("admin@example.org"))
+		Expect(config.DomainConfig.Domain).To(Equal("gardener.example.org"))
+		Expect(config.DomainConfig.Credentials).To(HaveField("AccessKeyID", "bXktYWNjZXNzLWtleS1pZA=="))
+		Expect(config.BackupConfig.Enabled).To(BeFalse())
+		Expect(config.Gardener.ClusterIP).To(Equal("100.88.0.100"))
+
+		content, err := os.ReadFile(configFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("gardener.example.org"))
+	})
+
+	It("doesn't write a domain config which fails verification", func() {
+		completeEnv()
+		install.Container.VerifyDomainConfig = func(_ *install.KeConfig) error { return errors.New("invalid credentials") }
+
+		kubeClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).Build()
+		Expect(install.QueryConfig(kubeClient)).To(MatchError("invalid credentials"))
+
+		content, err := os.ReadFile(configFile)
+		if err == nil {
+			Expect(string(content)).NotTo(ContainSubstring("gardener.example.org"))
+		}
+	})
+
+	It("doesn't ask again for a domain config set by environment variables", func() {
+		common.SetNonInteractive(false)
+		completeEnv()
+		verifications := 0
+		install.Container.VerifyDomainConfig = func(_ *install.KeConfig) error {
+			verifications++
+			return errors.New("invalid credentials")
+		}
+
+		kubeClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).Build()
+		Expect(install.QueryConfig(kubeClient)).To(MatchError("invalid credentials"))
+		Expect(verifications).To(Equal(2))
+	})
+
+	It("reports every missing key nested in domainConfig and backupConfig", func() {
+		setEnv(map[string]string{
+			"DOMAINCONFIG_PROVIDER": "aws-route53",
+			"BACKUPCONFIG_ENABLED":  "true",
+			"BACKUPCONFIG_PROVIDER": "azure",
+		})
+
+		kubeClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).Build()
+		err := install.QueryConfig(kubeClient)
+
+		var missingErr *common.MissingConfigKeysError
+		Expect(err).To(BeAssignableToTypeOf(missingErr))
+		Expect(err.(*common.MissingConfigKeysError).Keys).To(ContainElements(
+			common.MissingConfigKey{Key: "domainConfig.domain", Type: "string", Validator: "required,fqdn", Message: "Please enter the base (sub)domain of your gardener setup."},
+			HaveField("Key", "domainConfig.credentials.AWS_ACCESS_KEY_ID"),
+			HaveField("Key", "domainConfig.credentials.AWS_SECRET_ACCESS_KEY"),
+			HaveField("Key", "backupConfig.region"),
+			HaveField("Key", "backupConfig.bucketName"),
+			HaveField("Key", "backupConfig.credentials.storageAccountAccessKey"),
+		))
+		Expect(err.(*common.MissingConfigKeysError).Keys).NotTo(ContainElement(HaveField("Key", "domainConfig")))
+		Expect(err.(*common.MissingConfigKeysError).Keys).NotTo(ContainElement(HaveField("Key", "domainConfig.provider")))
+	})
+})
+
+var _ = Describe("queryDetectedCidr", func() {
+	BeforeEach(func() {
+		common.SetNonInteractive(true)
+		DeferCleanup(common.SetNonInteractive, false)
+	})
+
+	It("uses the detected CIDR in non-interactive mode", func() {
+		cidr, err := install.QueryDetectedCidr("pod CIDR", &netdetect.Finding{Cidr: "10.244.0.0/16", Source: "calico IPPool default-ipv4-ippool"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cidr).To(Equal("10.244.0.0/16"))
+	})
+
+	It("reports the CIDR as missing if nothing was detected", func() {
+		_, err := install.QueryDetectedCidr("pod CIDR", nil)
+		var promptErr *common.PromptError
+		Expect(err).To(BeAssignableToTypeOf(promptErr))
+		Expect(err.(*common.PromptError).Validator).To(Equal("required,cidr"))
+	})
+})
+
+var _ = Describe("clusterIPOf", func() {
+	It("returns the 100th address of the service CIDR", func() {
+		Expect(install.ClusterIPOf("10.96.0.0/12")).To(Equal("10.96.0.100"))
+		Expect(install.ClusterIPOf("100.88.0.1/13")).To(Equal("100.88.0.100"))
+	})
+
+	It("fails instead of panicking", func() {
+		_, err := install.ClusterIPOf("10.96.0.0/26")
+		Expect(err).To(MatchError(ContainSubstring("out of the service IP range")))
+
+		_, err = install.ClusterIPOf("")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("useDetected", func() {
+	detected := &clusterdetect.Finding{Value: "hel1", Source: "the label topology.kubernetes.io/region of the nodes"}
+
+	It("uses detected values in non-interactive mode only", func() {
+		common.SetNonInteractive(true)
+		DeferCleanup(common.SetNonInteractive, false)
+
+		Expect(install.UseDetected("region", detected)).To(BeTrue())
+		Expect(install.UseDetected("region", nil)).To(BeFalse())
+	})
+
+	It("offers them as default otherwise", func() {
+		Expect(install.UseDetected("region", detected)).To(BeFalse())
+	})
+})
@@ -6,9 +6,11 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/23technologies/23kectl/pkg/common"
-	"golang.org/x/oauth2"
+	"github.com/23technologies/23kectl/pkg/deploykey"
+	"github.com/spf13/viper"
 
 	"github.com/fluxcd/flux2/pkg/manifestgen/sourcesecret"
 	"github.com/go-git/go-git/v5"
@@ -16,15 +18,17 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/storage/memory"
+	gossh "golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	k8syaml "sigs.k8s.io/yaml"
-
-	gh "github.com/google/go-github/v36/github"
 )
 
+// bounds of the backoff when polling for the deploy key to be granted access
+var keyPollInterval = 2 * time.Second
+var maxKeyPollInterval = 30 * time.Second
+
 func generateDeployKey(kubeClient client.Client, secretName string, repoUrl string) (*ssh.PublicKeys, error) {
 	sec := corev1.Secret{}
 	err := kubeClient.Get(context.Background(), client.ObjectKey{
@@ -43,7 +47,28 @@ func generateDeployKey(kubeClient client.Client, secretName string, repoUrl stri
 
 		fmt.Println(`A key was already deployed to your cluster and I did not change it.`)
 
-		Container.BlockUntilKeyCanRead(repoUrl, keys, string(sec.Data["identity.pub"]))
+		repourl, err := url.Parse(repoUrl)
+		if err != nil {
+			return nil, err
+		}
+		err = verifyHostKeys(Container.GetSSHHostname(repourl), sec.Data["known_hosts"])
+		if err != nil {
+			return nil, err
+		}
+
+		knownHosts := knownHostsForSecret(sec.Data["known_hosts"])
+		if string(knownHosts) != string(sec.Data["known_hosts"]) {
+			sec.Data["known_hosts"] = knownHosts
+			err = kubeClient.Update(context.Background(), &sec)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = Container.BlockUntilKeyCanRead(repoUrl, keys, string(sec.Data["identity.pub"]))
+		if err != nil {
+			return nil, err
+		}
 
 		return keys, nil
 	} else {
@@ -73,6 +98,13 @@ func generateDeployKey(kubeClient client.Client, secretName string, repoUrl stri
 		}
 		fluxRepoSecret.SetNamespace(common.FLUX_NAMESPACE)
 
+		// flux trusts the host key scanned right now, unless it is pinned
+		err = verifyHostKeys(sourceSecOpts.SSHHostname, []byte(fluxRepoSecret.StringData["known_hosts"]))
+		if err != nil {
+			return nil, err
+		}
+		fluxRepoSecret.StringData["known_hosts"] = string(knownHostsForSecret([]byte(fluxRepoSecret.StringData["known_hosts"])))
+
 		fmt.Println(`I created an ssh key for you.`)
 
 		keys, err = ssh.NewPublicKeys("git", []byte(fluxRepoSecret.StringData["identity"]), "")
@@ -80,7 +112,10 @@ func generateDeployKey(kubeClient client.Client, secretName string, repoUrl stri
 			return nil, err
 		}
 
-		Container.BlockUntilKeyCanRead(repoUrl, keys, string(fluxRepoSecret.StringData["identity.pub"]))
+		err = Container.BlockUntilKeyCanRead(repoUrl, keys, string(fluxRepoSecret.StringData["identity.pub"]))
+		if err != nil {
+			return nil, err
+		}
 
 		err = Container.Create(context.Background(), &fluxRepoSecret)
 		if err != nil {
@@ -91,47 +126,66 @@ func generateDeployKey(kubeClient client.Client, secretName string, repoUrl stri
 	}
 }
 
-func registerDeployKeyWithGithub(repoUrl, pubkey, token string) {
-
-	// get a *github.Client	for the github token
-	// this client will be used for interacting with the github api
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tokenClient := oauth2.NewClient(context.Background(), ts)
-	client := gh.NewClient(tokenClient)
-
-	owner := strings.Split(repoUrl, "/")[3]
-	repo := strings.Split(repoUrl, "/")[4]
-	repo = strings.Replace(repo, ".git", "", 1)
+// registerDeployKey registers pubkey with the git provider hosting repoUrl.
+// The provider is detected from the host unless admin.gitProvider is set and
+// the API token is taken from admin.gitProviderToken or the environment.
+// Nothing happens if no token is available.
+func registerDeployKey(repoUrl, pubkey string) {
+	registrar, repo, err := newDeployKeyRegistrar(repoUrl)
+	if err != nil {
+		return
+	}
 
-	key := gh.Key{
-		Key:      pointer.String(pubkey),
-		ReadOnly: pointer.Bool(false),
+	err = registrar.Register(context.Background(), repo, deploykey.Key{
+		Title:     common.CONFIG_23KE_GITREPO_KEY,
+		PublicKey: strings.TrimSpace(pubkey),
+		ReadOnly:  false,
+	})
+	if err != nil {
+		fmt.Println("Tried to add the deploy key to your git provider, but failed.", err)
 	}
-	_, _, err := client.Repositories.CreateKey(context.Background(), owner, repo, &key)
+}
 
+// newDeployKeyRegistrar returns a registrar for the git provider hosting repoUrl.
+// It fails if the provider is unknown or no API token is available.
+func newDeployKeyRegistrar(repoUrl string) (deploykey.Registrar, *deploykey.Repo, error) {
+	repo, err := deploykey.ParseRepoURL(repoUrl)
 	if err != nil {
-		msg := err.Error()
+		return nil, nil, err
+	}
 
-		if !strings.Contains(msg, "key is already in use") {
-			fmt.Println("Tried to add deploy key to github, but failed.", msg)
-		}
+	registrar, err := deploykey.New(repo, deploykey.Options{
+		Provider: viper.GetString("admin.gitProvider"),
+		APIURL:   viper.GetString("admin.gitProviderURL"),
+		Token:    viper.GetString("admin.gitProviderToken"),
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+
+	return registrar, repo, nil
 }
 
-func blockUntilKeyCanRead(repoUrl string, keys *ssh.PublicKeys, pubkey string) {
-	// add github deploy key automatically, when GH_TOKEN is provided
-	if token, exists := os.LookupEnv("GH_TOKEN"); exists && keyCanRead(repoUrl, keys) != nil {
-		registerDeployKeyWithGithub(repoUrl, pubkey, token)
+func blockUntilKeyCanRead(repoUrl string, keys *ssh.PublicKeys, pubkey string) error {
+	// add the deploy key automatically, when an API token is provided
+	if keyCanRead(repoUrl, keys) != nil {
+		registerDeployKey(repoUrl, pubkey)
+	}
+
+	err := writePublicKey(pubkey)
+	if err != nil {
+		return err
+	}
+
+	if timeout := common.KeyWaitTimeout(); timeout > 0 {
+		return pollUntilKeyCanRead(repoUrl, keys, pubkey, timeout)
 	}
 
-	var err error
 	for {
 		err = keyCanRead(repoUrl, keys)
 		if err == nil {
 			fmt.Println(`Read access granted.`)
-			break
+			return nil
 		}
 
 		err := fmt.Errorf("make sure that %s can be accessed by this key:\n%s", repoUrl, err)
@@ -142,14 +196,71 @@ func blockUntilKeyCanRead(repoUrl string, keys *ssh.PublicKeys, pubkey string) {
 	}
 }
 
-func keyCanRead(url string, publicKeys *ssh.PublicKeys) error {
+// pollUntilKeyCanRead checks the access of the deploy key with an exponential
+// backoff instead of asking for confirmation, so unattended runs don't hang.
+func pollUntilKeyCanRead(repoUrl string, keys *ssh.PublicKeys, pubkey string, timeout time.Duration) error {
+	fingerprint := gossh.FingerprintSHA256(keys.Signer.PublicKey())
+	deadline := time.Now().Add(timeout)
+	interval := keyPollInterval
+
+	err := keyCanRead(repoUrl, keys)
+	if err == nil {
+		fmt.Println(`Read access granted.`)
+		return nil
+	}
+
+	fmt.Printf("Waiting up to %s for %s to be readable with the deploy key %s:\n", timeout, repoUrl, fingerprint)
+	common.PrintHighlight(strings.TrimSpace(pubkey))
+
+	for time.Now().Add(interval).Before(deadline) {
+		time.Sleep(interval)
+
+		err = keyCanRead(repoUrl, keys)
+		if err == nil {
+			fmt.Println(`Read access granted.`)
+			return nil
+		}
+
+		interval *= 2
+		if interval > maxKeyPollInterval {
+			interval = maxKeyPollInterval
+		}
+	}
+
+	return fmt.Errorf("%s couldn't be read with the deploy key %s within %s: %w", repoUrl, fingerprint, timeout, err)
+}
+
+// writePublicKey writes the public deploy key in authorized_keys format to the
+// file configured with common.SetPublicKeyFile. On stdout it is prefixed with
+// common.PUBLIC_KEY_LINE_PREFIX, as it is mixed with the progress output.
+func writePublicKey(pubkey string) error {
+	path := common.PublicKeyFile()
+	line := strings.TrimSpace(pubkey) + "\n"
+
+	switch path {
+	case "":
+		return nil
+	case "-":
+		fmt.Print(common.PUBLIC_KEY_LINE_PREFIX + line)
+		return nil
+	}
+
+	err := os.WriteFile(path, []byte(line), 0644)
+	if err != nil {
+		return fmt.Errorf("couldn't write the public deploy key to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func keyCanRead(url string, auth transport.AuthMethod) error {
 	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
 		Name: "origin",
 		URLs: []string{url},
 	})
 
 	_, err := remote.List(&git.ListOptions{
-		Auth: publicKeys,
+		Auth: auth,
 	})
 
 	if err != nil && err != transport.ErrEmptyRemoteRepository {
@@ -0,0 +1,86 @@
+package install_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var _ = Describe("BlockUntilKeyCanRead", func() {
+	var keys *ssh.PublicKeys
+	var pubkey string
+
+	BeforeEach(func() {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		signer, err := gossh.NewSignerFromKey(privateKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		keys = &ssh.PublicKeys{User: "git", Signer: signer}
+		pubkey = string(gossh.MarshalAuthorizedKey(signer.PublicKey()))
+
+		install.SetKeyPollInterval(10 * time.Millisecond)
+		common.SetKeyWaitTimeout(100 * time.Millisecond)
+		DeferCleanup(func() {
+			install.SetKeyPollInterval(2 * time.Second)
+			common.SetKeyWaitTimeout(0)
+			common.SetPublicKeyFile("")
+		})
+	})
+
+	It("writes the public key to a file and returns once the repo can be read", func() {
+		repoPath := filepath.Join(GinkgoT().TempDir(), "repo.git")
+		_, err := git.PlainInit(repoPath, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		keyFile := filepath.Join(GinkgoT().TempDir(), "identity.pub")
+		common.SetPublicKeyFile(keyFile)
+
+		Expect(install.BlockUntilKeyCanRead("file://"+repoPath, keys, pubkey)).To(Succeed())
+
+		content, err := os.ReadFile(keyFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal(pubkey))
+	})
+
+	It("marks the public key among the other output on stdout", func() {
+		repoPath := filepath.Join(GinkgoT().TempDir(), "repo.git")
+		_, err := git.PlainInit(repoPath, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		common.SetPublicKeyFile("-")
+
+		reader, writer, err := os.Pipe()
+		Expect(err).NotTo(HaveOccurred())
+		stdout := os.Stdout
+		os.Stdout = writer
+		err = install.BlockUntilKeyCanRead("file://"+repoPath, keys, pubkey)
+		os.Stdout = stdout
+		Expect(writer.Close()).To(Succeed())
+		Expect(err).NotTo(HaveOccurred())
+
+		output, err := io.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Split(string(output), "\n")).To(ContainElement(common.PUBLIC_KEY_LINE_PREFIX + strings.TrimSpace(pubkey)))
+	})
+
+	It("fails naming the repo and the key fingerprint when access isn't granted in time", func() {
+		repoUrl := "file://" + filepath.Join(GinkgoT().TempDir(), "missing.git")
+
+		err := install.BlockUntilKeyCanRead(repoUrl, keys, pubkey)
+		Expect(err).To(MatchError(ContainSubstring(repoUrl)))
+		Expect(err).To(MatchError(ContainSubstring(gossh.FingerprintSHA256(keys.Signer.PublicKey()))))
+	})
+})
@@ -0,0 +1,75 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/dnscheck"
+)
+
+// verifyDomainConfig checks the DNS credentials and the delegation of the
+// domain before anything is applied to the cluster.
+func verifyDomainConfig(config *KeConfig) error {
+	lister, err := newZoneLister(config.DomainConfig)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Verifying the DNS configuration of %s\n", config.DomainConfig.Domain)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err = dnscheck.Verify(ctx, config.DomainConfig.Domain, lister, dnscheck.NewResolver(""))
+	if err != nil {
+		return fmt.Errorf("the DNS configuration won't work: %w", err)
+	}
+
+	return nil
+}
+
+func newZoneLister(domainConfig domainConfiguration) (dnscheck.ZoneLister, error) {
+	if domainConfig.Credentials == nil {
+		return nil, fmt.Errorf("there are no DNS credentials in the domain config")
+	}
+
+	// credentials from the wizard are pointers, the ones decoded from the config file aren't
+	creds := reflect.Indirect(reflect.ValueOf(domainConfig.Credentials)).Interface()
+	decode := dnscheck.DecodeCredential
+
+	switch c := creds.(type) {
+	case dnsCredentialsAzure:
+		return &dnscheck.Azure{
+			TenantID:       decode(c.TenantId),
+			SubscriptionID: decode(c.SubscriptionId),
+			ClientID:       decode(c.ClientId),
+			ClientSecret:   decode(c.ClientSecret),
+			Private:        domainConfig.Provider == common.DNS_PROVIDER_AZURE_PRIVATE_DNS,
+		}, nil
+	case dnsCredentialsAWS53:
+		return &dnscheck.Route53{
+			AccessKeyID:     decode(c.AccessKeyID),
+			SecretAccessKey: decode(c.SecretAccessKey),
+		}, nil
+	case dnsCredentialsOSDesignate:
+		return &dnscheck.Designate{
+			AuthURL:                     decode(c.AuthURL),
+			ApplicationCredentialID:     decode(c.ApplicationCredentialID),
+			ApplicationCredentialSecret: decode(c.ApplicationCredentialSecret),
+		}, nil
+	case dnsCredentialsGCP:
+		return &dnscheck.CloudDNS{
+			ServiceAccountJSON: []byte(decode(c.ServiceAccountJSON)),
+		}, nil
+	case dnsCredentialsAlicloud:
+		return &dnscheck.Alicloud{
+			AccessKeyID:     decode(c.AccessKeyID),
+			AccessKeySecret: decode(c.AccessKeySecret),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("DNS provider %s isn't supported", domainConfig.Provider)
+}
@@ -0,0 +1,59 @@
+package install_test
+
+import (
+	"os"
+	"path"
+
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNS providers", func() {
+	DescribeTable("renders the credentials into the '23ke-config' secret",
+		func(provider string, credentials map[string]interface{}, expectedCredentials map[string]string) {
+			// credentials read from a config file are plain maps with lowercased keys
+			setConfig("domainConfig", map[string]interface{}{
+				"domain":      "my-domain.example.org",
+				"provider":    provider,
+				"credentials": credentials,
+			})
+
+			keConfig := &install.KeConfig{}
+			Expect(install.UnmarshalKeConfig(keConfig)).To(Succeed())
+			Expect(keConfig.DomainConfig.Credentials).NotTo(BeAssignableToTypeOf(map[string]interface{}{}))
+
+			values := renderConfigSecretValues()
+			Expect(values.Domains.Global.Provider).To(Equal(provider))
+			Expect(values.Domains.Global.Credentials).To(Equal(expectedCredentials))
+		},
+		Entry("google-clouddns",
+			"google-clouddns",
+			map[string]interface{}{"serviceaccountjson": "eyJ0eXBlIjoic2VydmljZV9hY2NvdW50In0="},
+			map[string]string{"serviceaccount.json": "eyJ0eXBlIjoic2VydmljZV9hY2NvdW50In0="},
+		),
+		Entry("alicloud-dns",
+			"alicloud-dns",
+			map[string]interface{}{"access_key_id": "my-key-id", "access_key_secret": "my-key-secret"},
+			map[string]string{"ACCESS_KEY_ID": "my-key-id", "ACCESS_KEY_SECRET": "my-key-secret"},
+		),
+		Entry("azure-private-dns",
+			"azure-private-dns",
+			map[string]interface{}{"tenantid": "my-tenant", "subscriptionid": "my-subscription", "clientid": "my-client-id", "clientsecret": "my-client-secret"},
+			map[string]string{"tenantID": "my-tenant", "subscriptionID": "my-subscription", "clientID": "my-client-id", "clientSecret": "my-client-secret"},
+		),
+	)
+
+	It("reports a key file which can't be read", func() {
+		keyFile := path.Join(GinkgoT().TempDir(), "key.json")
+		Expect(os.WriteFile(keyFile, []byte(`{"type":"service_account"}`), 0600)).To(Succeed())
+
+		encoded, err := install.ReadFileBase64(keyFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encoded).To(Equal("eyJ0eXBlIjoic2VydmljZV9hY2NvdW50In0="))
+
+		Expect(os.Remove(keyFile)).To(Succeed())
+		_, err = install.ReadFileBase64(keyFile)
+		Expect(err).To(MatchError(ContainSubstring("couldn't read " + keyFile)))
+	})
+})
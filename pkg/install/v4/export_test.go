@@ -0,0 +1,49 @@
+package install
+
+import "time"
+
+// exported for tests in install_test
+var Create23keConfigSecret = create23keConfigSecret
+var BlockUntilKeyCanRead = blockUntilKeyCanRead
+
+func SetKeyPollInterval(interval time.Duration) {
+	keyPollInterval, maxKeyPollInterval = interval, interval
+}
+
+var CreateGitCredentials = createGitCredentials
+var UpdateConfigRepo = updateConfigRepo
+var GitAuthFromSecret = gitAuthFromSecret
+var VerifyHostKeys = verifyHostKeys
+var KnownHostsForSecret = knownHostsForSecret
+var UsePinnedHostKeys = usePinnedHostKeys
+var WaitForConfigRepoFetch = waitForConfigRepoFetch
+
+func SetFluxPollInterval(interval time.Duration) {
+	fluxPollInterval = interval
+}
+
+var EncryptSecretManifest = encryptSecretManifest
+var EditConfigRepoSecret = editConfigRepoSecret
+
+type SopsConfig = sopsConfig
+
+var QueryConfig = queryConfig
+var QueryDetectedCidr = queryDetectedCidr
+var ClusterIPOf = clusterIPOf
+var UseDetected = useDetected
+var EnableProviderExtensions = enableProviderExtensions
+var RenderConfigTemplate = renderConfigTemplate
+var WriteConfigDir = writeConfigDir
+
+var ReadFileBase64 = readFileBase64
+
+var RenderSeedTemplate = renderSeedTemplate
+var SeedFilePath = seedFilePath
+var LoadSeedKubeconfig = loadSeedKubeconfig
+
+type SeedConfig = seedConfig
+type GardenletChart = gardenletChart
+
+var GetGardenletChart = getGardenletChart
+var CreateGardenConnection = createGardenConnection
+var DeleteSeedCredentials = deleteSeedCredentials
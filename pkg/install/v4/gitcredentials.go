@@ -0,0 +1,91 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/fluxcd/flux2/pkg/manifestgen/sourcesecret"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// DEFAULT_GIT_USERNAME is used for https remotes if no username is configured.
+// Most providers accept any username in combination with an access token.
+const DEFAULT_GIT_USERNAME = "git"
+
+func isHTTPSRemote(repoUrl string) bool {
+	return strings.HasPrefix(repoUrl, "https://")
+}
+
+// createGitCredentials creates a flux source secret with the username and
+// token of an https remote and checks that the repo can be read with them.
+func createGitCredentials(kubeClient client.Client, secretName string, repoUrl string) (*http.BasicAuth, error) {
+	auth := &http.BasicAuth{
+		Username: viper.GetString("admin.gitUsername"),
+		Password: viper.GetString("admin.gitToken"),
+	}
+	if auth.Username == "" {
+		auth.Username = DEFAULT_GIT_USERNAME
+	}
+
+	err := keyCanRead(repoUrl, auth)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %s with the configured git credentials: %w", repoUrl, err)
+	}
+	fmt.Println(`Read access granted.`)
+
+	sec := corev1.Secret{}
+	err = kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      secretName,
+	}, &sec)
+	if err == nil {
+		fmt.Println(`Git credentials were already deployed to your cluster and I did not change them.`)
+		return auth, nil
+	}
+
+	sourceSecOpts := sourcesecret.MakeDefaultOptions()
+	sourceSecOpts.Name = secretName
+	sourceSecOpts.Namespace = common.FLUX_NAMESPACE
+	sourceSecOpts.Username = auth.Username
+	sourceSecOpts.Password = auth.Password
+
+	secManifest, err := sourcesecret.Generate(sourceSecOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	fluxRepoSecret := corev1.Secret{}
+	err = k8syaml.Unmarshal([]byte(secManifest.Content), &fluxRepoSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = Container.Create(context.Background(), &fluxRepoSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+// gitAuthFromSecret returns the auth method stored in the flux source secret of the config repo,
+// which holds either an ssh key or the username and token of an https remote.
+func gitAuthFromSecret(sec *corev1.Secret) (transport.AuthMethod, error) {
+	if identity, ok := sec.Data["identity"]; ok {
+		return ssh.NewPublicKeys("git", identity, "")
+	}
+
+	if password, ok := sec.Data["password"]; ok {
+		return &http.BasicAuth{Username: string(sec.Data["username"]), Password: string(password)}, nil
+	}
+
+	return nil, fmt.Errorf("secret %s/%s contains neither an ssh key nor git credentials", sec.Namespace, sec.Name)
+}
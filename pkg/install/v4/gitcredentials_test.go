@@ -0,0 +1,145 @@
+package install_test
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// startGitHTTPServer serves the bare repos in root via the smart HTTP protocol of
+// git http-backend. Only the user "git" with the token "my-token" is let in.
+func startGitHTTPServer(root string) *httptest.Server {
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	Expect(err).NotTo(HaveOccurred())
+
+	backend := &cgi.Handler{
+		Path: path.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1", "REMOTE_USER=git"},
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "git" || password != "my-token" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	DeferCleanup(server.Close)
+
+	return server
+}
+
+var _ = Describe("https config repos", Ordered, func() {
+	var repoUrl string
+	const branch = "https-test"
+	const secretName = "23ke-config-https-test"
+
+	BeforeAll(func() {
+		root := GinkgoT().TempDir()
+		repo, err := git.PlainInit(path.Join(root, "config.git"), true)
+		Expect(err).NotTo(HaveOccurred())
+
+		// http-backend refuses pushes unless enabled explicitly
+		cfg, err := repo.Config()
+		Expect(err).NotTo(HaveOccurred())
+		cfg.Raw.Section("http").SetOption("receivepack", "true")
+		Expect(repo.SetConfig(cfg)).To(Succeed())
+
+		server := startGitHTTPServer(root)
+		repoUrl = server.URL + "/config.git"
+
+		// go-git has to trust the certificate of the test server
+		gitclient.InstallProtocol("https", githttp.NewClient(server.Client()))
+		DeferCleanup(gitclient.InstallProtocol, "https", githttp.DefaultClient)
+
+		create := install.Container.Create
+		install.Container.Create = k8sClient.Create
+		DeferCleanup(func() {
+			install.Container.Create = create
+		})
+
+		// the rendered config files need a complete base cluster config
+		viper.Set("baseCluster.hasVerticalPodAutoscaler", false)
+		viper.Set("baseCluster.provider", "hcloud")
+		viper.Set("baseCluster.region", "hel1")
+
+		viper.Set("admin.gitrepourl", repoUrl)
+		viper.Set("admin.gitrepobranch", branch)
+		viper.Set("admin.gitUsername", "git")
+	})
+
+	It("refuses invalid tokens", func() {
+		viper.Set("admin.gitToken", "wrong-token")
+
+		_, err := install.CreateGitCredentials(k8sClient, secretName, repoUrl)
+		Expect(err).To(MatchError(ContainSubstring("configured git credentials")))
+	})
+
+	It("creates a flux source secret with username and password", func(ctx SpecContext) {
+		viper.Set("admin.gitToken", "my-token")
+
+		auth, err := install.CreateGitCredentials(k8sClient, secretName, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(auth).To(Equal(&githttp.BasicAuth{Username: "git", Password: "my-token"}))
+
+		secret := corev1.Secret{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "flux-system", Name: secretName}, &secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data).To(HaveKeyWithValue("username", []byte("git")))
+		Expect(secret.Data).To(HaveKeyWithValue("password", []byte("my-token")))
+		Expect(secret.Data).NotTo(HaveKey("identity"))
+	})
+
+	It("pushes the config to the https remote", func() {
+		auth := &githttp.BasicAuth{Username: "git", Password: "my-token"}
+		Expect(install.UpdateConfigRepo(auth)).To(Succeed())
+
+		clone, err := git.PlainClone(GinkgoT().TempDir(), false, &git.CloneOptions{
+			URL:           repoUrl,
+			Auth:          auth,
+			ReferenceName: plumbing.NewBranchReferenceName(branch),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		head, err := clone.Head()
+		Expect(err).NotTo(HaveOccurred())
+		commit, err := clone.CommitObject(head.Hash())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commit.Message).To(Equal("Config update through 23kectl"))
+	})
+
+	It("validates https remotes without token", func() {
+		viper.Set("admin.gitToken", "")
+		DeferCleanup(viper.Set, "admin.gitToken", "my-token")
+
+		violations, err := install.ValidateConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(ContainElement(HaveField("Path", "admin.gitToken")))
+	})
+
+	It("uses the credentials when reading the secret back", func(ctx SpecContext) {
+		secret := corev1.Secret{}
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "flux-system", Name: secretName}, &secret)
+		Expect(err).NotTo(HaveOccurred())
+
+		auth, err := install.GitAuthFromSecret(&secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(auth).To(Equal(&githttp.BasicAuth{Username: "git", Password: "my-token"}))
+	})
+})
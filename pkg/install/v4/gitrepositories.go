@@ -11,12 +11,12 @@ import (
 
 	"github.com/fluxcd/pkg/apis/meta"
 	sourcecontrollerv1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/spf13/viper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -90,8 +90,35 @@ func createGitRepositories(kubeClient client.Client) error {
 	return nil
 }
 
-func updateConfigRepo(publicKeys *ssh.PublicKeys) error {
+func updateConfigRepo(auth transport.AuthMethod) error {
 	log := logger.Get("updateConfigRepo")
+
+	repository, worktree, workTreeFs, err := cloneConfigRepo(auth)
+	if err != nil {
+		return err
+	}
+
+	_, _ = worktree.Remove(".")
+
+	fmt.Printf("Writing new config\n")
+
+	err = writeConfigDir(workTreeFs, ".")
+	if err != nil {
+		return err
+	}
+
+	err = keepUnchangedSecrets(repository, workTreeFs)
+	if err != nil {
+		log.Info("Couldn't compare the encrypted secrets to the committed ones", "error", err)
+	}
+
+	return commitConfigRepo(repository, worktree, auth, "Config update through 23kectl")
+}
+
+// cloneConfigRepo clones the config repo to memory and checks out the configured branch.
+// If the branch doesn't exist yet, the worktree is empty.
+func cloneConfigRepo(auth transport.AuthMethod) (*git.Repository, *git.Worktree, billy.Filesystem, error) {
+	log := logger.Get("cloneConfigRepo")
 	gitRepo := viper.GetString("admin.gitrepourl")
 
 	var err error
@@ -100,11 +127,11 @@ func updateConfigRepo(publicKeys *ssh.PublicKeys) error {
 	fmt.Printf("Cloning config repo to memory\n")
 	repository, err := git.Clone(memory.NewStorage(), workTreeFs, &git.CloneOptions{
 		URL:        gitRepo,
-		Auth:       publicKeys,
+		Auth:       auth,
 		NoCheckout: true,
 	})
 	if err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
-		return err
+		return nil, nil, nil, err
 	}
 
 	branchName := viper.GetString("admin.gitRepoBranch")
@@ -133,26 +160,24 @@ func updateConfigRepo(publicKeys *ssh.PublicKeys) error {
 				Create: true,
 			})
 			if err != nil {
-				return err
+				return nil, nil, nil, err
 			}
 		} else {
 			err = worktree.Checkout(&git.CheckoutOptions{
 				Branch: plumbing.NewBranchReferenceName(branchName),
 			})
 			if err != nil {
-				return err
+				return nil, nil, nil, err
 			}
 		}
 	}
 
-	_, _ = worktree.Remove(".")
-
-	fmt.Printf("Writing new config\n")
+	return repository, worktree, workTreeFs, nil
+}
 
-	err = writeConfigDir(workTreeFs, ".")
-	if err != nil {
-		return err
-	}
+// commitConfigRepo commits every change in the worktree and pushes it.
+func commitConfigRepo(repository *git.Repository, worktree *git.Worktree, auth transport.AuthMethod, message string) error {
+	log := logger.Get("commitConfigRepo")
 
 	_, _ = worktree.Add(".")
 	status, _ := worktree.Status()
@@ -161,7 +186,7 @@ func updateConfigRepo(publicKeys *ssh.PublicKeys) error {
 		log.Info("Worktree is clean. Not committing anything.")
 	} else {
 		log.Info("Commiting to config repo")
-		_, err = worktree.Commit("Config update through 23kectl", &git.CommitOptions{
+		_, err := worktree.Commit(message, &git.CommitOptions{
 			Author: &object.Signature{
 				Name:  "23ke Ctl",
 				Email: "23kectl@23technologies.cloud",
@@ -174,7 +199,7 @@ func updateConfigRepo(publicKeys *ssh.PublicKeys) error {
 
 		log.Info("Pushing to config repo")
 		err = repository.Push(&git.PushOptions{
-			Auth: publicKeys,
+			Auth: auth,
 		})
 		if err != nil {
 			return err
@@ -11,12 +11,13 @@ import (
 	"github.com/fluxcd/flux2/pkg/manifestgen"
 	runclient "github.com/fluxcd/pkg/runtime/client"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 
 	"github.com/23technologies/23kectl/pkg/common"
 	"github.com/23technologies/23kectl/pkg/logger"
+	"github.com/23technologies/23kectl/pkg/preflight"
 	"github.com/fatih/color"
-	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,18 +27,25 @@ import (
 )
 
 var Container = struct {
-	BlockUntilKeyCanRead func(string, *ssh.PublicKeys, string)
+	BlockUntilKeyCanRead func(string, *ssh.PublicKeys, string) error
 	GetSSHHostname       func(_ *url.URL) string
 	QueryConfigKey       func(configKey string, _ func() (any, error)) error
 	CreateFluxManifest   func() (*manifestgen.Manifest, error)
 	Apply                func(ctx context.Context, rcg genericclioptions.RESTClientGetter, opts *runclient.Options, root, manifestPath string) (string, error)
 	Create               func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error
+	List                 func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error
+	VerifyDomainConfig   func(config *KeConfig) error
+	OpenEditor           func(file string) error
+	Preflight            func(kubeconfigArgs *genericclioptions.ConfigFlags, checks []preflight.Check, config *KeConfig) error
 }{
 	BlockUntilKeyCanRead: blockUntilKeyCanRead,
 	GetSSHHostname:       getSSHHostname,
 	QueryConfigKey:       common.QueryConfigKey,
 	CreateFluxManifest:   createFluxManifest,
 	Apply:                utils.Apply,
+	VerifyDomainConfig:   verifyDomainConfig,
+	OpenEditor:           openEditor,
+	Preflight:            runPreflight,
 }
 
 func Install(kubeconfig string, isDryRun bool) error {
@@ -52,12 +60,7 @@ func Install(kubeconfig string, isDryRun bool) error {
 		return err
 	}
 	Container.Create = kubeClient.Create
-
-	err = queryConfig(kubeClient)
-	if err != nil {
-		return err
-	}
-	UnmarshalKeConfig(keConfiguration)
+	Container.List = kubeClient.List
 
 	// initialize container
 	// This is espcially important when running in dry run mode
@@ -65,8 +68,29 @@ func Install(kubeconfig string, isDryRun bool) error {
 		Container.Apply = applyDryRun
 		Container.Create = create
 		Container.GetSSHHostname = func(_ *url.URL) string { return "github.com" }
-		Container.BlockUntilKeyCanRead = func(_ string, _ *ssh.PublicKeys, _ string) {}
+		Container.BlockUntilKeyCanRead = func(_ string, _ *ssh.PublicKeys, _ string) error { return nil }
+		Container.VerifyDomainConfig = func(_ *KeConfig) error { return nil }
+		Container.Preflight = func(_ *genericclioptions.ConfigFlags, _ []preflight.Check, _ *KeConfig) error { return nil }
+	}
+
+	// the cluster is checked before the wizard, so an unsupported one is reported
+	// before all questions were answered
+	fmt.Println("Running preflight checks")
+	err = Container.Preflight(kubeconfigArgs, preflight.ClusterChecks(), keConfiguration)
+	if err != nil {
+		return err
+	}
+
+	// a new domain config is verified while it's asked for
+	isDomainConfigured := viper.IsSet("domainConfig")
+
+	err = queryConfig(kubeClient)
+	if err != nil {
+		return err
+	}
+	UnmarshalKeConfig(keConfiguration)
 
+	if isDryRun {
 		gitRepoUrl := viper.GetString("admin.gitrepourl")
 		if !strings.Contains(gitRepoUrl, "file://") {
 			return fmt.Errorf("dry run mode only supports local git repositories. I have written a config file for you. If you just wanted to craft an inital config file, you can ignore this error")
@@ -78,6 +102,25 @@ func Install(kubeconfig string, isDryRun bool) error {
 		}
 	}
 
+	if isDomainConfigured {
+		err = Container.VerifyDomainConfig(keConfiguration)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Checking the config")
+	err = Container.Preflight(kubeconfigArgs, preflight.ConfigChecks(), keConfiguration)
+	if err != nil {
+		return err
+	}
+
+	restoreHostKeys, err := usePinnedHostKeys()
+	if err != nil {
+		return err
+	}
+	defer restoreHostKeys()
+
 	fmt.Println("Installing flux")
 	err = installFlux(kubeconfigArgs, kubeclientOptions)
 	if err != nil {
@@ -90,14 +133,30 @@ func Install(kubeconfig string, isDryRun bool) error {
 		return err
 	}
 
-	fmt.Println("Generating 23ke-config deploy key")
-	fmt.Println(`You will need to add this key to your git remote git repository.`)
-	common.PrintWarn("This key needs write access!")
-	publicKeysConfig, err := generateDeployKey(kubeClient, common.CONFIG_23KE_GITREPO_KEY, viper.GetString("admin.gitrepourl"))
+	var gitAuth transport.AuthMethod
+	gitRepoUrl := viper.GetString("admin.gitrepourl")
+	if isHTTPSRemote(gitRepoUrl) {
+		fmt.Println("Creating 23ke-config git credentials")
+		common.PrintWarn("The token needs write access!")
+		gitAuth, err = createGitCredentials(kubeClient, common.CONFIG_23KE_GITREPO_KEY, gitRepoUrl)
+	} else {
+		fmt.Println("Generating 23ke-config deploy key")
+		fmt.Println(`You will need to add this key to your git remote git repository.`)
+		common.PrintWarn("This key needs write access!")
+		gitAuth, err = generateDeployKey(kubeClient, common.CONFIG_23KE_GITREPO_KEY, gitRepoUrl)
+	}
 	if err != nil {
 		return err
 	}
 
+	if keConfiguration.Sops.Enabled {
+		fmt.Println("Setting up the age key to encrypt secrets with")
+		err = ensureSopsAgeKey(kubeClient)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = create23keConfigSecret(kubeClient)
 	if err != nil {
 		return err
@@ -118,7 +177,7 @@ func Install(kubeconfig string, isDryRun bool) error {
 		return err
 	}
 
-	err = updateConfigRepo(publicKeysConfig)
+	err = updateConfigRepo(gitAuth)
 	if err != nil {
 		return err
 	}
@@ -137,7 +196,7 @@ func getKeConfig() (*KeConfig, error) {
 	keConfig := new(KeConfig)
 	err := UnmarshalKeConfig(keConfig)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
 	return keConfig, nil
@@ -152,16 +211,7 @@ func UnmarshalKeConfig(config *KeConfig) error {
 
 	_, ok := (config.DomainConfig.Credentials).(map[string]interface{})
 	if ok {
-		var creds interface{}
-		switch config.DomainConfig.Provider {
-		case common.DNS_PROVIDER_AZURE_DNS:
-			creds = dnsCredentialsAzure{}
-		case common.DNS_PROVIDER_OPENSTACK_DESIGNATE:
-			creds = dnsCredentialsOSDesignate{}
-		case common.DNS_PROVIDER_AWS_ROUTE_53:
-			creds = dnsCredentialsAWS53{}
-		}
-		err = mapstructure.Decode(config.DomainConfig.Credentials, &creds)
+		creds, err := decodeCredentials(config.DomainConfig.Credentials, newDNSCredentials(config.DomainConfig.Provider))
 		if err != nil {
 			return err
 		}
@@ -170,12 +220,7 @@ func UnmarshalKeConfig(config *KeConfig) error {
 
 	_, ok = (config.BackupConfig.Credentials).(map[string]interface{})
 	if ok {
-		var creds interface{}
-		switch config.BackupConfig.Provider {
-		case common.BUCKET_PROVIDER_AZURE:
-			creds = backupCredentialsAzure{}
-		}
-		err = mapstructure.Decode(config.BackupConfig.Credentials, &creds)
+		creds, err := decodeCredentials(config.BackupConfig.Credentials, newBackupCredentials(config.BackupConfig.Provider))
 		if err != nil {
 			return err
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/23technologies/23kectl/pkg/preflight"
 	"github.com/fluxcd/flux2/pkg/manifestgen"
 	fluxInstall "github.com/fluxcd/flux2/pkg/manifestgen/install"
 	"github.com/go-git/go-git/v5"
@@ -12,6 +13,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/viper"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"net/url"
 	"os"
 	"os/exec"
@@ -76,15 +78,17 @@ func init() {
 		"gardener.clusterip":        "10.0.0.100",
 		"gardenlet.seednodecidr":    "10.250.0.0/16",
 		"gardenlet.seedpodcidr":     "100.73.0.0/16",
-		"gardenlet.seedservicecidr": "100.88.0.0/13",
+		"gardenlet.seedservicecidr": "10.0.0.0/24",
 		"issuer.acme.email":         "test@example.org",
 		"issuer.acme.server":        "example.acme.server",
 		"issuer.ca":                 "my-great-ca",
 		"version":                   "test",
 	}
 
-	install.Container.BlockUntilKeyCanRead = func(_ string, _ *ssh.PublicKeys, _ string) {}
+	install.Container.BlockUntilKeyCanRead = func(_ string, _ *ssh.PublicKeys, _ string) error { return nil }
 	install.Container.GetSSHHostname = func(_ *url.URL) string { return "github.com" }
+	install.Container.VerifyDomainConfig = func(_ *install.KeConfig) error { return nil }
+	install.Container.Preflight = func(_ *genericclioptions.ConfigFlags, _ []preflight.Check, _ *install.KeConfig) error { return nil }
 	install.Container.QueryConfigKey = func(configKey string, _ func() (any, error)) error {
 		lc := strings.ToLower(configKey)
 
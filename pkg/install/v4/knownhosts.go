@@ -0,0 +1,139 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// writePinnedKnownHosts writes the host keys pinned in admin.knownHosts to a
+// temporary file and returns its path. It returns "" if no host keys are pinned.
+func writePinnedKnownHosts() (string, error) {
+	pinned := viper.GetString("admin.knownHosts")
+	if strings.TrimSpace(pinned) == "" {
+		return "", nil
+	}
+
+	file, err := os.CreateTemp("", "23kectl-known-hosts-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(pinned)
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// usePinnedHostKeys makes go-git verify ssh host keys against the pinned host
+// keys instead of ~/.ssh/known_hosts. The returned function restores the
+// previous behaviour.
+func usePinnedHostKeys() (func(), error) {
+	path, err := writePinnedKnownHosts()
+	if err != nil || path == "" {
+		return func() {}, err
+	}
+
+	// go-git reads the known hosts files from SSH_KNOWN_HOSTS on every connect
+	previous, isSet := os.LookupEnv("SSH_KNOWN_HOSTS")
+	err = os.Setenv("SSH_KNOWN_HOSTS", path)
+	if err != nil {
+		os.Remove(path)
+		return func() {}, err
+	}
+
+	return func() {
+		if isSet {
+			os.Setenv("SSH_KNOWN_HOSTS", previous)
+		} else {
+			os.Unsetenv("SSH_KNOWN_HOSTS")
+		}
+		os.Remove(path)
+	}, nil
+}
+
+// pinnedHostKeyCallback returns a host key callback accepting only the pinned
+// host keys. It returns nil if no host keys are pinned.
+func pinnedHostKeyCallback() (gossh.HostKeyCallback, error) {
+	path, err := writePinnedKnownHosts()
+	if err != nil || path == "" {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the pinned known hosts: %w", err)
+	}
+
+	return callback, nil
+}
+
+// verifyHostKeys checks the host keys in known_hosts format, which flux scanned
+// at install time, against the pinned host keys. At least one scanned key has
+// to match, as usually only the keys of one type are pinned. Nothing is checked
+// if no host keys are pinned.
+func verifyHostKeys(hostname string, scanned []byte) error {
+	callback, err := pinnedHostKeyCallback()
+	if err != nil || callback == nil {
+		return err
+	}
+
+	host, port := hostname, 22
+	if h, p, err := net.SplitHostPort(hostname); err == nil {
+		host = h
+		port, _ = strconv.Atoi(p)
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	remote := &net.TCPAddr{IP: net.IPv4zero, Port: port}
+
+	var mismatches []string
+	var mismatchErr error
+	rest := scanned
+	for {
+		var key gossh.PublicKey
+		_, _, key, _, rest, err = gossh.ParseKnownHosts(rest)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't parse the scanned host keys of %s: %w", hostname, err)
+		}
+
+		err = callback(address, remote, key)
+		if err == nil {
+			return nil
+		}
+		mismatches = append(mismatches, gossh.FingerprintSHA256(key))
+		mismatchErr = err
+	}
+
+	if len(mismatches) == 0 {
+		return fmt.Errorf("no host key of %s was found", hostname)
+	}
+
+	return fmt.Errorf("none of the host keys %s of %s matches the pinned known hosts, refusing to trust them: %w", strings.Join(mismatches, ", "), hostname, mismatchErr)
+}
+
+// knownHostsForSecret returns the known_hosts flux trusts: the pinned host keys
+// if any, the scanned ones otherwise.
+func knownHostsForSecret(scanned []byte) []byte {
+	pinned := viper.GetString("admin.knownHosts")
+	if strings.TrimSpace(pinned) == "" {
+		return scanned
+	}
+
+	return []byte(pinned)
+}
@@ -0,0 +1,122 @@
+package install_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newRSAHostKey() gossh.PublicKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+	key, err := gossh.NewPublicKey(&privateKey.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+	return key
+}
+
+func newECDSAHostKey() gossh.PublicKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	key, err := gossh.NewPublicKey(&privateKey.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+	return key
+}
+
+func newHostKey() gossh.PublicKey {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	key, err := gossh.NewPublicKey(publicKey)
+	Expect(err).NotTo(HaveOccurred())
+	return key
+}
+
+var _ = Describe("Known hosts pinning", func() {
+	var hostKey gossh.PublicKey
+
+	BeforeEach(func() {
+		hostKey = newHostKey()
+		DeferCleanup(viper.Set, "admin.knownHosts", "")
+	})
+
+	It("trusts the scanned host key if nothing is pinned", func() {
+		viper.Set("admin.knownHosts", "")
+
+		scanned := knownhosts.Line([]string{"git.example.com"}, newHostKey())
+		Expect(install.VerifyHostKeys("git.example.com", []byte(scanned))).To(Succeed())
+	})
+
+	It("accepts a scanned host key matching the pinned one", func() {
+		viper.Set("admin.knownHosts", knownhosts.Line([]string{"git.example.com"}, hostKey)+"\n")
+
+		scanned := knownhosts.Line([]string{"git.example.com"}, hostKey)
+		Expect(install.VerifyHostKeys("git.example.com", []byte(scanned))).To(Succeed())
+	})
+
+	It("accepts the scanned host keys if the pinned ed25519 key is among them", func() {
+		pinned := knownhosts.Line([]string{"git.example.com"}, hostKey) + "\n"
+		viper.Set("admin.knownHosts", pinned)
+
+		scanned := strings.Join([]string{
+			knownhosts.Line([]string{"git.example.com"}, newRSAHostKey()),
+			knownhosts.Line([]string{"git.example.com"}, newECDSAHostKey()),
+			knownhosts.Line([]string{"git.example.com"}, hostKey),
+		}, "\n")
+		Expect(install.VerifyHostKeys("git.example.com", []byte(scanned))).To(Succeed())
+		Expect(string(install.KnownHostsForSecret([]byte(scanned)))).To(Equal(pinned))
+	})
+
+	It("stores the scanned host keys if nothing is pinned", func() {
+		viper.Set("admin.knownHosts", "")
+
+		scanned := knownhosts.Line([]string{"git.example.com"}, hostKey)
+		Expect(string(install.KnownHostsForSecret([]byte(scanned)))).To(Equal(scanned))
+	})
+
+	It("matches hosts with non-default ports", func() {
+		viper.Set("admin.knownHosts", knownhosts.Line([]string{"[git.example.com]:2222"}, hostKey)+"\n")
+
+		scanned := knownhosts.Line([]string{"[git.example.com]:2222"}, hostKey)
+		Expect(install.VerifyHostKeys("git.example.com:2222", []byte(scanned))).To(Succeed())
+		Expect(install.VerifyHostKeys("git.example.com", []byte(scanned))).NotTo(Succeed())
+	})
+
+	It("refuses a scanned host key not matching the pinned one", func() {
+		viper.Set("admin.knownHosts", knownhosts.Line([]string{"git.example.com"}, hostKey)+"\n")
+
+		otherKey := newHostKey()
+		scanned := knownhosts.Line([]string{"git.example.com"}, otherKey)
+
+		err := install.VerifyHostKeys("git.example.com", []byte(scanned))
+		Expect(err).To(MatchError(ContainSubstring("matches the pinned known hosts")))
+		Expect(err).To(MatchError(ContainSubstring(gossh.FingerprintSHA256(otherKey))))
+	})
+
+	It("makes go-git use the pinned host keys", func() {
+		pinned := knownhosts.Line([]string{"git.example.com"}, hostKey) + "\n"
+		viper.Set("admin.knownHosts", pinned)
+		GinkgoT().Setenv("SSH_KNOWN_HOSTS", "/previous/known_hosts")
+
+		restore, err := install.UsePinnedHostKeys()
+		Expect(err).NotTo(HaveOccurred())
+
+		path := os.Getenv("SSH_KNOWN_HOSTS")
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal(pinned))
+
+		restore()
+		Expect(os.Getenv("SSH_KNOWN_HOSTS")).To(Equal("/previous/known_hosts"))
+		Expect(path).NotTo(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,24 @@
+package install
+
+import (
+	"context"
+	"os"
+
+	"github.com/23technologies/23kectl/pkg/preflight"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// runPreflight checks whether the base cluster is able to run 23KE before anything is
+// installed. Warnings are printed, failures stop the installation.
+func runPreflight(kubeconfigArgs *genericclioptions.ConfigFlags, checks []preflight.Check, config *KeConfig) error {
+	report, err := preflight.Execute(context.Background(), kubeconfigArgs, preflight.Config{
+		SeedNodeCidr:    config.Gardenlet.SeedNodeCidr,
+		SeedPodCidr:     config.Gardenlet.SeedPodCidr,
+		SeedServiceCidr: config.Gardenlet.SeedServiceCidr,
+	}, checks, os.Stdout, "")
+	if err != nil {
+		return err
+	}
+
+	return report.Err()
+}
@@ -0,0 +1,164 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/fluxcd/pkg/apis/meta"
+	fluxssh "github.com/fluxcd/pkg/ssh"
+	sourcecontrollerv1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/spf13/viper"
+	gossh "golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// how often to check whether flux fetched the config repo
+var fluxPollInterval = 2 * time.Second
+
+// RotateDeployKey replaces the deploy key of the config repo without an outage.
+// The new key is registered and verified before it is swapped into the cluster,
+// and the old key is only deregistered once flux fetched the config repo with
+// the new one. If flux can't, the old key is restored.
+func RotateDeployKey(kubeconfig string, timeout time.Duration) error {
+	repoUrl := viper.GetString("admin.gitrepourl")
+	if isHTTPSRemote(repoUrl) {
+		return fmt.Errorf("the config repo %s is accessed with an access token, there is no deploy key to rotate", repoUrl)
+	}
+
+	_, _, kubeClient, err := common.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	restoreHostKeys, err := usePinnedHostKeys()
+	if err != nil {
+		return err
+	}
+	defer restoreHostKeys()
+
+	sec := corev1.Secret{}
+	err = kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.CONFIG_23KE_GITREPO_KEY,
+	}, &sec)
+	if err != nil {
+		return fmt.Errorf("couldn't read the deploy key of the config repo: %w", err)
+	}
+	oldData := sec.Data
+
+	fmt.Println("Generating a new 23ke-config deploy key")
+	fmt.Println(`You will need to add this key to your git remote git repository.`)
+	common.PrintWarn("This key needs write access!")
+	keyPair, err := fluxssh.NewEd25519Generator().Generate()
+	if err != nil {
+		return err
+	}
+
+	keys, err := ssh.NewPublicKeys("git", keyPair.PrivateKey, "")
+	if err != nil {
+		return err
+	}
+
+	err = Container.BlockUntilKeyCanRead(repoUrl, keys, string(keyPair.PublicKey))
+	if err != nil {
+		return err
+	}
+
+	// the host key was already verified when the old key was deployed
+	fmt.Println("Swapping the deploy key in the cluster")
+	sec.Data = map[string][]byte{
+		"identity":     keyPair.PrivateKey,
+		"identity.pub": keyPair.PublicKey,
+		"known_hosts":  oldData["known_hosts"],
+	}
+	err = kubeClient.Update(context.Background(), &sec)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Waiting for flux to fetch the config repo with the new key\n")
+	err = waitForConfigRepoFetch(kubeClient, timeout)
+	if err != nil {
+		sec.Data = oldData
+		restoreErr := kubeClient.Update(context.Background(), &sec)
+		if restoreErr != nil {
+			return fmt.Errorf("flux couldn't fetch the config repo with the new deploy key: %w, restoring the old key failed as well: %s", err, restoreErr)
+		}
+		return fmt.Errorf("flux couldn't fetch the config repo with the new deploy key, the old key was restored: %w", err)
+	}
+
+	deregisterDeployKey(repoUrl, string(oldData["identity.pub"]))
+
+	fmt.Println("The deploy key was rotated.")
+	return nil
+}
+
+// waitForConfigRepoFetch requests a reconciliation of the config repo's
+// GitRepository and blocks until flux handled it successfully.
+func waitForConfigRepoFetch(kubeClient client.Client, timeout time.Duration) error {
+	gitRepo := sourcecontrollerv1beta2.GitRepository{}
+	key := client.ObjectKey{Namespace: common.FLUX_NAMESPACE, Name: common.CONFIG_23KE_GITREPO_NAME}
+
+	err := kubeClient.Get(context.Background(), key, &gitRepo)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(gitRepo.DeepCopy())
+	requestedAt := utils.RequestReconcile(&gitRepo)
+
+	err = kubeClient.Patch(context.Background(), &gitRepo, patch)
+	if err != nil {
+		return err
+	}
+
+	err = wait.PollImmediate(fluxPollInterval, timeout, func() (bool, error) {
+		err := kubeClient.Get(context.Background(), key, &gitRepo)
+		if err != nil {
+			return false, err
+		}
+
+		if gitRepo.Status.LastHandledReconcileAt != requestedAt {
+			return false, nil
+		}
+
+		return apimeta.IsStatusConditionTrue(gitRepo.Status.Conditions, meta.ReadyCondition), nil
+	})
+	if err != nil {
+		if condition := apimeta.FindStatusCondition(gitRepo.Status.Conditions, meta.ReadyCondition); condition != nil {
+			return fmt.Errorf("%w: %s", err, condition.Message)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// deregisterDeployKey removes the old deploy key via the API of the git
+// provider or asks for it to be removed by hand.
+func deregisterDeployKey(repoUrl, pubkey string) {
+	fingerprint := strings.TrimSpace(pubkey)
+	if key, _, _, _, err := gossh.ParseAuthorizedKey([]byte(pubkey)); err == nil {
+		fingerprint = gossh.FingerprintSHA256(key)
+	}
+
+	registrar, repo, err := newDeployKeyRegistrar(repoUrl)
+	if err == nil {
+		err = registrar.Deregister(context.Background(), repo, strings.TrimSpace(pubkey))
+		if err == nil {
+			fmt.Printf("Removed the old deploy key %s from %s.\n", fingerprint, repoUrl)
+			return
+		}
+		fmt.Println("Tried to remove the old deploy key from your git provider, but failed.", err)
+	}
+
+	common.PrintWarn(fmt.Sprintf("Please remove the old deploy key %s from %s.", fingerprint, repoUrl))
+}
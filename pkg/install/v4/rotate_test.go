@@ -0,0 +1,72 @@
+package install_test
+
+import (
+	"context"
+	"time"
+
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcecontrollerv1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("WaitForConfigRepoFetch", func() {
+	var fakeClient client.Client
+	key := client.ObjectKey{Namespace: "flux-system", Name: "23ke-config"}
+
+	// fakeSourceController handles the next reconcile request with the given Ready condition
+	fakeSourceController := func(status metav1.ConditionStatus, message string) {
+		go func() {
+			defer GinkgoRecover()
+
+			Eventually(func(g Gomega) {
+				gitRepo := sourcecontrollerv1beta2.GitRepository{}
+				g.Expect(fakeClient.Get(context.Background(), key, &gitRepo)).To(Succeed())
+
+				requestedAt := gitRepo.GetAnnotations()[meta.ReconcileRequestAnnotation]
+				g.Expect(requestedAt).NotTo(BeEmpty())
+
+				gitRepo.Status.LastHandledReconcileAt = requestedAt
+				gitRepo.Status.Conditions = []metav1.Condition{{
+					Type:               meta.ReadyCondition,
+					Status:             status,
+					Reason:             "Reconciled",
+					Message:            message,
+					LastTransitionTime: metav1.Now(),
+				}}
+				g.Expect(fakeClient.Status().Update(context.Background(), &gitRepo)).To(Succeed())
+			}).Should(Succeed())
+		}()
+	}
+
+	BeforeEach(func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(utils.NewScheme()).WithObjects(&sourcecontrollerv1beta2.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Status: sourcecontrollerv1beta2.GitRepositoryStatus{
+				// ready with the old key, which doesn't count
+				Conditions: []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: "Succeeded", LastTransitionTime: metav1.Now()}},
+			},
+		}).Build()
+
+		install.SetFluxPollInterval(10 * time.Millisecond)
+		DeferCleanup(install.SetFluxPollInterval, 2*time.Second)
+	})
+
+	It("returns once flux fetched the config repo after the request", func() {
+		fakeSourceController(metav1.ConditionTrue, "stored artifact for revision 'main/1234'")
+
+		Expect(install.WaitForConfigRepoFetch(fakeClient, 5*time.Second)).To(Succeed())
+	})
+
+	It("reports the Ready message if the fetch fails", func() {
+		fakeSourceController(metav1.ConditionFalse, "ssh: handshake failed: ssh: unable to authenticate")
+
+		err := install.WaitForConfigRepoFetch(fakeClient, 200*time.Millisecond)
+		Expect(err).To(MatchError(ContainSubstring("unable to authenticate")))
+	})
+})
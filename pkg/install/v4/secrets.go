@@ -78,6 +78,10 @@ stringData:
 		return err
 	}
 
+	if creds, ok := keConfig.DomainConfig.Credentials.(dnsSecretData); ok {
+		keConfig.DomainConfig.Credentials = creds.secretData()
+	}
+
 	buffer := bytes.Buffer{}
 	err = tpl.Execute(&buffer, keConfig)
 	if err != nil {
@@ -0,0 +1,49 @@
+package install_test
+
+import (
+	"context"
+
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// configSecretValues is the part of the '23ke-config' secret's values.yaml the provider tests check.
+type configSecretValues struct {
+	Domains struct {
+		Global struct {
+			Provider    string            `yaml:"provider"`
+			Credentials map[string]string `yaml:"credentials"`
+		} `yaml:"global"`
+	} `yaml:"domains"`
+	Backups struct {
+		Enabled     bool              `yaml:"enabled"`
+		Provider    string            `yaml:"provider"`
+		Credentials map[string]string `yaml:"credentials"`
+	} `yaml:"backups"`
+}
+
+// renderConfigSecretValues renders the '23ke-config' secret from the current config
+// without a cluster and returns its values.yaml.
+func renderConfigSecretValues() configSecretValues {
+	create := install.Container.Create
+	defer func() {
+		install.Container.Create = create
+	}()
+
+	var createdSecret *corev1.Secret
+	install.Container.Create = func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+		createdSecret = obj.(*corev1.Secret)
+		return nil
+	}
+
+	ExpectWithOffset(1, install.Create23keConfigSecret(nil)).To(Succeed())
+	ExpectWithOffset(1, createdSecret).NotTo(BeNil())
+
+	values := configSecretValues{}
+	ExpectWithOffset(1, yaml.Unmarshal([]byte(createdSecret.StringData["values.yaml"]), &values)).To(Succeed())
+
+	return values
+}
@@ -0,0 +1,683 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/clusterdetect"
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/logger"
+	"github.com/23technologies/23kectl/pkg/netdetect"
+	"github.com/AlecAivazis/survey/v2"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizecontrollerv1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// INITIAL_SEED_NAME is the seed running in the base cluster, see gardenlet-values.yaml.
+const INITIAL_SEED_NAME = "initial-seed"
+
+// BASE_GARDENLET_RELEASE_NAME is the HelmRelease of the initial seed's gardenlet,
+// created by the 23KE base. The gardenlets of additional seeds use its chart.
+const BASE_GARDENLET_RELEASE_NAME = "internal-gardenlet"
+
+// BOOTSTRAP_TOKEN_TTL is how long a gardenlet can register its seed with the
+// bootstrap token created by `23kectl seed add`.
+const BOOTSTRAP_TOKEN_TTL = 24 * time.Hour
+
+// SEED_KUBECONFIG_SECRET_KEY is the key flux reads the kubeconfig of a remote cluster from.
+const SEED_KUBECONFIG_SECRET_KEY = "value"
+
+// seedKubeconfigSecretName is the secret in the base cluster holding the kubeconfig
+// of an additional seed. It's never committed to the config repo.
+func seedKubeconfigSecretName(name string) string {
+	return "seed-" + name + "-kubeconfig"
+}
+
+// seedGardenConnectionSecretName is the secret in the base cluster holding the
+// gardenlet values connecting a seed to the garden. Like the kubeconfig, it
+// contains credentials and is never committed to the config repo.
+func seedGardenConnectionSecretName(name string) string {
+	return "seed-" + name + "-garden-connection"
+}
+
+// IsSeedSecretName reports whether name is one of the secrets of an additional seed
+// in the base cluster, i.e. its kubeconfig or garden connection.
+func IsSeedSecretName(name string) bool {
+	return strings.HasPrefix(name, "seed-") &&
+		(strings.HasSuffix(name, "-kubeconfig") || strings.HasSuffix(name, "-garden-connection"))
+}
+
+// seedKustomizationName is the flux Kustomization deploying the gardenlet of a seed.
+func seedKustomizationName(name string) string {
+	return "23ke-env-seed-" + name
+}
+
+// AddSeed registers the cluster of seedKubeconfig as an additional seed. Its
+// networks are detected like the ones of the base cluster, its kubeconfig is
+// stored in the base cluster and its gardenlet is deployed by flux from the
+// config repo.
+func AddSeed(kubeconfig string, seedKubeconfig string, seedContext string, name string, gardenServer string) error {
+	seeds, err := getSeeds()
+	if err != nil {
+		return err
+	}
+
+	rawKubeconfig, err := loadSeedKubeconfig(seedKubeconfig, seedContext)
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(rawKubeconfig)
+	if err != nil {
+		return err
+	}
+
+	seedClient, err := client.New(restConfig, client.Options{Scheme: utils.NewScheme()})
+	if err != nil {
+		return err
+	}
+
+	seed, err := querySeedConfig(seedClient, name, seeds)
+	if err != nil {
+		return err
+	}
+
+	_, _, kubeClient, err := common.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	gardenConfig, err := getGardenRESTConfig(kubeClient)
+	if err != nil {
+		return err
+	}
+
+	gardenServer, err = queryGardenServer(gardenServer, gardenConfig.Host)
+	if err != nil {
+		return err
+	}
+
+	gardenClient, err := client.New(gardenConfig, client.Options{Scheme: utils.NewScheme()})
+	if err != nil {
+		return err
+	}
+
+	// nothing refers to the seed's credentials until it's pushed to the config repo,
+	// so they're deleted again if adding it fails
+	var tokenName string
+	cleanUp := func() {
+		err := deleteSeedCredentials(kubeClient, gardenClient, seed.Name, tokenName)
+		if err != nil {
+			common.PrintWarn(fmt.Sprintf("Couldn't delete the credentials of seed %s: %s", seed.Name, err))
+		}
+	}
+
+	fmt.Printf("Storing the kubeconfig of seed %s in the secret '%s'\n", seed.Name, seedKubeconfigSecretName(seed.Name))
+	err = applySecret(kubeClient, seedKubeconfigSecretName(seed.Name), SEED_KUBECONFIG_SECRET_KEY, rawKubeconfig)
+	if err != nil {
+		cleanUp()
+		return err
+	}
+
+	fmt.Printf("Creating a bootstrap token for the gardenlet of seed %s in the secret '%s'\n", seed.Name, seedGardenConnectionSecretName(seed.Name))
+	gardenConnection, tokenName, err := createGardenConnection(gardenClient, seed.Name, gardenServer, time.Now())
+	if err != nil {
+		cleanUp()
+		return err
+	}
+	err = applySecret(kubeClient, seedGardenConnectionSecretName(seed.Name), "values.yaml", gardenConnection)
+	if err != nil {
+		cleanUp()
+		return err
+	}
+
+	setSeeds(append(seeds, seed))
+	enableProviderExtensions()
+
+	err = Reconfigure(kubeconfig)
+	if err != nil {
+		cleanUp()
+		return err
+	}
+
+	return common.WriteConfig()
+}
+
+// ListSeeds prints the initial seed and the additional ones of the config.
+func ListSeeds(w io.Writer) error {
+	seeds, err := getSeeds()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPROVIDER\tREGION\tNODES\tPODS\tSERVICES")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		INITIAL_SEED_NAME,
+		viper.GetString("baseCluster.provider"),
+		viper.GetString("baseCluster.region"),
+		viper.GetString("gardenlet.seedNodeCidr"),
+		viper.GetString("gardenlet.seedPodCidr"),
+		viper.GetString("gardenlet.seedServiceCidr"),
+	)
+	for _, seed := range seeds {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", seed.Name, seed.Provider, seed.Region, seed.NodeCidr, seed.PodCidr, seed.ServiceCidr)
+	}
+
+	return tw.Flush()
+}
+
+// RemoveSeed removes an additional seed from the config repo. Once flux
+// uninstalled its gardenlet, the seed's kubeconfig and garden connection are
+// deleted from the base cluster. The shoots of the seed have to be moved or
+// deleted before. The Seed object stays in the garden, as only its gardenlet
+// can clean up the seed cluster, see printSeedDeletionHint.
+func RemoveSeed(kubeconfig string, name string, timeout time.Duration) error {
+	if name == INITIAL_SEED_NAME {
+		return fmt.Errorf("the seed %s runs in the base cluster and can't be removed", INITIAL_SEED_NAME)
+	}
+
+	seeds, err := getSeeds()
+	if err != nil {
+		return err
+	}
+
+	var remaining []seedConfig
+	for _, seed := range seeds {
+		if seed.Name != name {
+			remaining = append(remaining, seed)
+		}
+	}
+	if len(remaining) == len(seeds) {
+		return fmt.Errorf("there's no seed named %s in the config", name)
+	}
+
+	_, _, kubeClient, err := common.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	setSeeds(remaining)
+
+	err = Reconfigure(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	err = common.WriteConfig()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Waiting for flux to uninstall the gardenlet of seed %s\n", name)
+	err = waitForSeedRemoval(kubeClient, name, timeout)
+	if err != nil {
+		return fmt.Errorf("flux didn't remove the seed %s, its kubeconfig is kept in the secret '%s': %w", name, seedKubeconfigSecretName(name), err)
+	}
+
+	err = deleteSeedCredentials(kubeClient, nil, name, "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed seed %s from the config repository\n", name)
+	printSeedDeletionHint(os.Stdout, name)
+	return nil
+}
+
+// deleteSeedCredentials deletes the secrets of a seed from the base cluster and,
+// if tokenName is set, its bootstrap token from the garden.
+func deleteSeedCredentials(kubeClient client.Client, gardenClient client.Client, name string, tokenName string) error {
+	for _, secretName := range []string{seedKubeconfigSecretName(name), seedGardenConnectionSecretName(name)} {
+		err := kubeClient.Delete(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: common.FLUX_NAMESPACE, Name: secretName},
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if tokenName == "" {
+		return nil
+	}
+
+	err := gardenClient.Delete(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: tokenName},
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// printSeedDeletionHint explains how to delete the Seed object, which stays
+// in the garden once its gardenlet is uninstalled.
+func printSeedDeletionHint(w io.Writer, name string) {
+	fmt.Fprintf(w, `The Seed %[1]s is still registered in the garden. Without its gardenlet, nothing cleans up
+the seed cluster and the Seed's finalizer isn't removed. Delete it with the garden's kubeconfig:
+  kubectl delete seed %[1]s --wait=false
+  kubectl patch seed %[1]s --type merge -p '{"metadata":{"finalizers":null}}'
+`, name)
+}
+
+// waitForSeedRemoval waits until flux fetched the config repo without the seed
+// and pruned its Kustomization, which uninstalls the gardenlet.
+func waitForSeedRemoval(kubeClient client.Client, name string, timeout time.Duration) error {
+	err := waitForConfigRepoFetch(kubeClient, timeout)
+	if err != nil {
+		return err
+	}
+
+	key := client.ObjectKey{Namespace: common.FLUX_NAMESPACE, Name: seedKustomizationName(name)}
+	return wait.PollImmediate(fluxPollInterval, timeout, func() (bool, error) {
+		err := kubeClient.Get(context.Background(), key, &kustomizecontrollerv1beta2.Kustomization{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	})
+}
+
+func getSeeds() ([]seedConfig, error) {
+	var seeds []seedConfig
+	err := viper.UnmarshalKey("seeds", &seeds)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the seeds of the config: %w", err)
+	}
+
+	return seeds, nil
+}
+
+// setSeeds stores seeds in the config, keyed like the yaml tags of seedConfig.
+func setSeeds(seeds []seedConfig) {
+	settings := []map[string]interface{}{}
+	for _, seed := range seeds {
+		settings = append(settings, map[string]interface{}{
+			"name":                     seed.Name,
+			"provider":                 seed.Provider,
+			"region":                   seed.Region,
+			"hasVerticalPodAutoscaler": *seed.HasVerticalPodAutoscaler,
+			"nodeCidr":                 seed.NodeCidr,
+			"podCidr":                  seed.PodCidr,
+			"serviceCidr":              seed.ServiceCidr,
+		})
+	}
+
+	viper.Set("seeds", settings)
+}
+
+// loadSeedKubeconfig reads the given context of a kubeconfig file, with every
+// certificate inlined, so flux can use it from within the base cluster.
+func loadSeedKubeconfig(kubeconfig string, context string) ([]byte, error) {
+	config, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if context != "" {
+		config.CurrentContext = context
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("there's no context '%s' in %s", config.CurrentContext, kubeconfig)
+	}
+
+	authInfo := config.AuthInfos[kubeContext.AuthInfo]
+	if authInfo != nil && (authInfo.Exec != nil || authInfo.AuthProvider != nil) {
+		return nil, fmt.Errorf("the user '%s' in %s authenticates with a plugin, which flux can't run. Use a token or client certificate instead", kubeContext.AuthInfo, kubeconfig)
+	}
+
+	err = clientcmdapi.MinifyConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = clientcmdapi.FlattenConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientcmd.Write(*config)
+}
+
+// applySecret creates or updates a secret with a single key in the flux namespace of the base cluster.
+func applySecret(kubeClient client.Client, name string, key string, value []byte) error {
+	sec := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: common.FLUX_NAMESPACE,
+		},
+		Data: map[string][]byte{
+			key: value,
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+
+	err := kubeClient.Create(context.Background(), &sec)
+	if apierrors.IsAlreadyExists(err) {
+		err = kubeClient.Update(context.Background(), &sec)
+	}
+
+	return err
+}
+
+// getGardenletChart returns the chart of the initial seed's gardenlet, so the
+// gardenlets of all seeds run the same version.
+func getGardenletChart() (gardenletChart, error) {
+	releases := helmv2.HelmReleaseList{}
+	err := Container.List(context.Background(), &releases)
+	if err != nil {
+		return gardenletChart{}, err
+	}
+
+	for _, release := range releases.Items {
+		if release.Name != BASE_GARDENLET_RELEASE_NAME || release.Spec.Chart.Spec.Chart == "" {
+			continue
+		}
+
+		spec := release.Spec.Chart.Spec
+		namespace := spec.SourceRef.Namespace
+		if namespace == "" {
+			namespace = release.Namespace
+		}
+
+		return gardenletChart{
+			Chart:           spec.Chart,
+			Version:         spec.Version,
+			SourceKind:      spec.SourceRef.Kind,
+			SourceName:      spec.SourceRef.Name,
+			SourceNamespace: namespace,
+		}, nil
+	}
+
+	return gardenletChart{}, fmt.Errorf("there's no HelmRelease %s in the base cluster, wait until 23KE is installed before adding seeds", BASE_GARDENLET_RELEASE_NAME)
+}
+
+// getGardenRESTConfig returns the config of the garden's API server flux applies
+// the garden content with.
+func getGardenRESTConfig(kubeClient client.Client) (*rest.Config, error) {
+	sec := corev1.Secret{}
+	err := kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.GARDENER_KUBECONFIG_SECRET_NAME,
+	}, &sec)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the kubeconfig of the garden: %w", err)
+	}
+
+	// flux reads the key value or value.yaml
+	kubeconfig := sec.Data["value"]
+	if len(kubeconfig) == 0 {
+		kubeconfig = sec.Data["value.yaml"]
+	}
+
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+}
+
+// queryGardenServer asks for the address of the garden's API server, which the
+// seed's gardenlet connects to. Flux uses the cluster internal one, which is
+// offered as default.
+func queryGardenServer(gardenServer string, internalServer string) (string, error) {
+	if gardenServer != "" {
+		return gardenServer, common.MakeValidatorFn("url")(gardenServer)
+	}
+
+	prompt := &survey.Input{
+		Message: "Please enter the address of the garden's API server",
+		Default: internalServer,
+		Help: `
+The gardenlet of the seed registers the seed with this address, so it has to be
+reachable from the seed cluster, e.g. https://api.gardener.example.org.
+`,
+	}
+
+	err := common.AskOne(prompt, &gardenServer, "required,url")
+	common.ExitOnCtrlC(err)
+	return gardenServer, err
+}
+
+// createGardenConnection creates a bootstrap token in the garden and returns
+// the gardenlet values connecting to the garden with it, plus the name of the
+// token's secret. The gardenlet exchanges the token for a client certificate
+// when it registers the seed.
+func createGardenConnection(gardenClient client.Client, seedName string, gardenServer string, now time.Time) ([]byte, string, error) {
+	tokenID, tokenSecret := common.RandHex(3), common.RandHex(8)
+	tokenName := "bootstrap-token-" + tokenID
+
+	err := gardenClient.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tokenName,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Type: corev1.SecretTypeBootstrapToken,
+		StringData: map[string]string{
+			"description":                    "Used by the gardenlet of seed " + seedName + " to register with the garden, created by 23kectl",
+			"expiration":                     now.Add(BOOTSTRAP_TOKEN_TTL).UTC().Format(time.RFC3339),
+			"token-id":                       tokenID,
+			"token-secret":                   tokenSecret,
+			"usage-bootstrap-authentication": "true",
+			"usage-bootstrap-signing":        "true",
+		},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't create a bootstrap token in the garden: %w", err)
+	}
+
+	bootstrapKubeconfig, err := clientcmd.Write(clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{"garden": {Server: gardenServer}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{"gardenlet-bootstrap": {Token: tokenID + "." + tokenSecret}},
+		Contexts:       map[string]*clientcmdapi.Context{"garden": {Cluster: "garden", AuthInfo: "gardenlet-bootstrap"}},
+		CurrentContext: "garden",
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	values, err := yaml.Marshal(map[string]interface{}{
+		"config": map[string]interface{}{
+			"gardenClientConnection": map[string]interface{}{
+				"bootstrapKubeconfig": map[string]interface{}{
+					"kubeconfig": string(bootstrapKubeconfig),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return values, tokenName, nil
+}
+
+// querySeedConfig detects the provider, region and networks of a seed cluster
+// like the ones of the base cluster and asks to confirm them.
+func querySeedConfig(seedClient client.Client, name string, seeds []seedConfig) (seedConfig, error) {
+	seed := seedConfig{Name: name}
+	var err error
+
+	if seed.Name == "" {
+		prompt := &survey.Input{
+			Message: "Please enter the name of the seed",
+			Help: `
+The seed is registered in the garden under this name, e.g. gcp-europe-west3.
+`,
+		}
+		err = common.AskOne(prompt, &seed.Name, "required,dns_rfc1035_label")
+		common.ExitOnCtrlC(err)
+		if err != nil {
+			return seed, err
+		}
+	}
+
+	err = common.MakeValidatorFn("required,dns_rfc1035_label")(seed.Name)
+	if err != nil {
+		return seed, fmt.Errorf("the seed name '%s' isn't a DNS label: %w", seed.Name, err)
+	}
+	if seed.Name == INITIAL_SEED_NAME {
+		return seed, fmt.Errorf("the seed name %s is taken by the seed of the base cluster", INITIAL_SEED_NAME)
+	}
+	for _, existing := range seeds {
+		if existing.Name == seed.Name {
+			return seed, fmt.Errorf("there's already a seed named %s", seed.Name)
+		}
+	}
+
+	detected, err := clusterdetect.Detect(context.Background(), seedClient)
+	if err != nil {
+		logger.Get("querySeedConfig").Info("Couldn't detect the seed cluster config", "error", err)
+	}
+	networks := netdetect.Detect(context.Background(), seedClient, netdetect.Detectors())
+
+	provider := detected.Provider
+	if provider != nil && !utils.ContainsItemString(common.BASE_CLUSTER_PROVIDERS, provider.Value) {
+		common.PrintWarn(fmt.Sprintf("Your seed cluster runs on %s, which isn't supported as a seed provider yet.", provider.Value))
+		provider = nil
+	}
+	if useDetected("provider", provider) {
+		seed.Provider = provider.Value
+	} else {
+		selectPrompt := &survey.Select{
+			Message: "Select the provider of your seed cluster",
+			Options: common.BASE_CLUSTER_PROVIDERS,
+			Help:    detectedHelp(provider),
+		}
+		if provider != nil {
+			selectPrompt.Default = provider.Value
+		}
+		err = common.AskOne(selectPrompt, &seed.Provider, "required")
+		common.ExitOnCtrlC(err)
+		if err != nil {
+			return seed, err
+		}
+	}
+
+	region := detected.Region
+	if region != nil && !common.IsValidRegion(seed.Provider, region.Value) {
+		common.PrintWarn(fmt.Sprintf("The detected region %s isn't a region of %s.", region.Value, seed.Provider))
+		region = nil
+	}
+	seed.Region, err = querySeedValue("region", "required,region="+seed.Provider, region, `
+This is the region your seed cluster runs in, e.g. `+regionExamples[seed.Provider]+`.
+`)
+	if err != nil {
+		return seed, err
+	}
+
+	seed.NodeCidr, err = querySeedValue("node CIDR", "required,cidr", detected.NodeCidr, `
+Gardener will check whether the nodes' ip addresses of your seed cluster lie in the specified network.
+`)
+	if err != nil {
+		return seed, err
+	}
+
+	seed.PodCidr, err = querySeedValue("pod CIDR", "required,cidr", networkFinding(networks.PodCidr), "")
+	if err != nil {
+		return seed, err
+	}
+
+	seed.ServiceCidr, err = querySeedValue("service CIDR", "required,cidr", networkFinding(networks.ServiceCidr), "")
+	if err != nil {
+		return seed, err
+	}
+
+	hasVerticalPodAutoscaler, err := querySeedVerticalPodAutoscaler(detected.HasVerticalPodAutoscaler)
+	if err != nil {
+		return seed, err
+	}
+	seed.HasVerticalPodAutoscaler = &hasVerticalPodAutoscaler
+
+	return seed, nil
+}
+
+// querySeedValue asks for a value of the seed cluster, offering the detected one as default.
+func querySeedValue(name string, validator string, detected *clusterdetect.Finding, help string) (string, error) {
+	if useDetected(name, detected) {
+		return detected.Value, nil
+	}
+
+	prompt := &survey.Input{
+		Message: fmt.Sprintf("Please enter the %s of your seed cluster", name),
+		Help:    help + detectedHelp(detected),
+	}
+	if detected != nil {
+		prompt.Default = detected.Value
+	}
+
+	var queryResult string
+	err := common.AskOne(prompt, &queryResult, validator)
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return "", err
+	}
+	return queryResult, nil
+}
+
+func querySeedVerticalPodAutoscaler(detected *clusterdetect.VPAFinding) (bool, error) {
+	const (
+		yes = "Yes"
+		no  = "No"
+	)
+
+	var vpa *clusterdetect.Finding
+	if detected != nil {
+		vpa = &clusterdetect.Finding{Value: no, Source: detected.Source}
+		if detected.Present {
+			vpa.Value = yes
+		}
+	}
+	if useDetected("answer to whether the seed cluster provides a VPA", vpa) {
+		return vpa.Value == yes, nil
+	}
+
+	if common.IsNonInteractive() {
+		// the answer is stored as a bool rather than the selected option
+		return false, &common.PromptError{Type: "bool", Message: "Does your seed cluster provide vertical pod autoscaling (VPA)?"}
+	}
+
+	selectPrompt := &survey.Select{
+		Message: "Does your seed cluster provide vertical pod autoscaling (VPA)?",
+		Options: []string{yes, no},
+		Help: `
+If it doesn't, the gardenlet deploys one.
+` + detectedHelp(vpa),
+	}
+	if vpa != nil {
+		selectPrompt.Default = vpa.Value
+	}
+
+	var queryResult string
+	err := common.AskOne(selectPrompt, &queryResult, "")
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return false, err
+	}
+
+	return queryResult == yes, nil
+}
+
+// networkFinding converts a detected network, so it can be confirmed like the other values.
+func networkFinding(finding *netdetect.Finding) *clusterdetect.Finding {
+	if finding == nil {
+		return nil
+	}
+
+	return &clusterdetect.Finding{Value: finding.Cidr, Source: finding.Source}
+}
@@ -0,0 +1,270 @@
+package install_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"time"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("additional seeds", func() {
+	hasVerticalPodAutoscaler := true
+	seed := install.SeedConfig{
+		Name:                     "gcp-europe-west3",
+		Provider:                 common.BASE_CLUSTER_PROVIDER_GCP,
+		Region:                   "europe-west3",
+		HasVerticalPodAutoscaler: &hasVerticalPodAutoscaler,
+		NodeCidr:                 "10.156.0.0/20",
+		PodCidr:                  "10.8.0.0/14",
+		ServiceCidr:              "10.12.0.0/20",
+	}
+	chart := install.GardenletChart{
+		Chart:           "./charts/gardenlet",
+		SourceKind:      "Bucket",
+		SourceName:      "23ke",
+		SourceNamespace: "flux-system",
+	}
+
+	setSeedConfig := func() {
+		setConfig("extensionsConfig", map[string]interface{}{})
+		setConfig("baseCluster.provider", common.BASE_CLUSTER_PROVIDER_HCLOUD)
+		setConfig("baseCluster.region", "hel1")
+		setConfig("baseCluster.hasVerticalPodAutoscaler", false)
+		setConfig("gardenlet.seedNodeCidr", "10.250.0.0/16")
+		setConfig("gardenlet.seedPodCidr", "100.96.0.0/11")
+		setConfig("gardenlet.seedServiceCidr", "100.64.0.0/13")
+		setConfig("cloudprofiles", []string{"gcp", "hcloud"})
+		setConfig("domainConfig.provider", common.DNS_PROVIDER_AZURE_DNS)
+		setConfig("seeds", []map[string]interface{}{{
+			"name":                     seed.Name,
+			"provider":                 seed.Provider,
+			"region":                   seed.Region,
+			"hasVerticalPodAutoscaler": true,
+			"nodeCidr":                 seed.NodeCidr,
+			"podCidr":                  seed.PodCidr,
+			"serviceCidr":              seed.ServiceCidr,
+		}})
+	}
+
+	It("renders the gardenlet values and the flux Kustomization of a seed", func() {
+		for _, name := range []string{"flux.yaml", "kustomization.yaml", "gardenlet.yaml", "gardenlet-values.yaml"} {
+			manifest, err := install.RenderSeedTemplate(name, seed, chart, install.SopsConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			expected, err := os.ReadFile(path.Join(cwd, "__fixture__", "seeds", install.SeedFilePath(name, seed.Name)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).To(Equal(string(expected)), name)
+		}
+	})
+
+	It("decrypts the seed's values with sops", func() {
+		manifest, err := install.RenderSeedTemplate("flux.yaml", seed, chart, install.SopsConfig{Enabled: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(manifest)).To(ContainSubstring("name: sops-age"))
+	})
+
+	It("lists the seeds' Kustomizations and providers in the config", func() {
+		setSeedConfig()
+		install.EnableProviderExtensions()
+		Expect(viper.GetBool("extensionsConfig.provider-gcp.enabled")).To(BeTrue())
+		Expect(viper.GetBool("extensionsConfig.provider-hcloud.enabled")).To(BeTrue())
+
+		keConfig := &install.KeConfig{}
+		Expect(install.UnmarshalKeConfig(keConfig)).To(Succeed())
+		Expect(keConfig.Seeds).To(ConsistOf(seed))
+
+		manifest, err := install.RenderConfigTemplate("kustomization.yaml", keConfig)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(manifest)).To(HaveSuffix("  - flux/23ke-env-garden-content.yaml\n  - flux/23ke-env-seed-gcp-europe-west3.yaml\n"))
+
+		manifest, err = install.RenderConfigTemplate("config/cloudprofiles-values.yaml", keConfig)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(manifest)).To(ContainSubstring("providerTypes:\n            - hcloud\n            - gcp\n"))
+	})
+
+	It("lists the initial seed and the additional ones", func() {
+		setSeedConfig()
+
+		var out bytes.Buffer
+		Expect(install.ListSeeds(&out)).To(Succeed())
+		Expect(out.String()).To(Equal(`NAME              PROVIDER  REGION        NODES          PODS           SERVICES
+initial-seed      hcloud    hel1          10.250.0.0/16  100.96.0.0/11  100.64.0.0/13
+gcp-europe-west3  gcp       europe-west3  10.156.0.0/20  10.8.0.0/14    10.12.0.0/20
+`))
+	})
+
+	It("reports seeds with the same name or a region of another provider", func() {
+		setSeedConfig()
+		setConfig("seeds", []map[string]interface{}{
+			{"name": "a", "provider": "gcp", "region": "europe-west3-a"},
+			{"name": "a", "provider": "gcp", "region": "europe-west3"},
+		})
+
+		violations, err := install.ValidateConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(ContainElements(
+			common.ValidationError{Path: "seeds[0].region", Tag: "region=gcp", Value: "europe-west3-a"},
+			common.ValidationError{Path: "seeds[1].name", Tag: "unique", Value: "a"},
+			common.ValidationError{Path: "seeds[0].nodeCidr", Tag: "required", Value: ""},
+		))
+	})
+
+	It("uses the chart of the initial seed's gardenlet", func() {
+		kubeClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).WithObjects(
+			&helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: "gardener", Namespace: "flux-system"},
+			},
+			&helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: install.BASE_GARDENLET_RELEASE_NAME, Namespace: "flux-system"},
+				Spec: helmv2.HelmReleaseSpec{Chart: helmv2.HelmChartTemplate{Spec: helmv2.HelmChartTemplateSpec{
+					Chart:     "./charts/gardenlet",
+					SourceRef: helmv2.CrossNamespaceObjectReference{Kind: "Bucket", Name: "23ke"},
+				}}},
+			},
+		).Build()
+
+		list := install.Container.List
+		DeferCleanup(func() { install.Container.List = list })
+
+		install.Container.List = kubeClient.List
+		Expect(install.GetGardenletChart()).To(Equal(chart))
+
+		install.Container.List = fake.NewClientBuilder().WithScheme(utils.NewScheme()).Build().List
+		_, err := install.GetGardenletChart()
+		Expect(err).To(MatchError(ContainSubstring("there's no HelmRelease internal-gardenlet")))
+	})
+
+	It("connects the gardenlet to the garden with a bootstrap token", func() {
+		gardenClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).Build()
+		now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		values, tokenName, err := install.CreateGardenConnection(gardenClient, seed.Name, "https://api.garden.example.org", now)
+		Expect(err).NotTo(HaveOccurred())
+
+		secrets := corev1.SecretList{}
+		Expect(gardenClient.List(context.Background(), &secrets, client.InNamespace("kube-system"))).To(Succeed())
+		Expect(secrets.Items).To(HaveLen(1))
+		token := secrets.Items[0]
+		Expect(token.Type).To(Equal(corev1.SecretTypeBootstrapToken))
+		Expect(token.Name).To(Equal("bootstrap-token-" + token.StringData["token-id"]))
+		Expect(tokenName).To(Equal(token.Name))
+		Expect(token.StringData).To(HaveKeyWithValue("expiration", "2023-03-02T12:00:00Z"))
+		Expect(token.StringData).To(HaveKeyWithValue("usage-bootstrap-authentication", "true"))
+
+		connection := struct {
+			Config struct {
+				GardenClientConnection struct {
+					BootstrapKubeconfig struct {
+						Kubeconfig string `json:"kubeconfig"`
+					} `json:"bootstrapKubeconfig"`
+				} `json:"gardenClientConnection"`
+			} `json:"config"`
+		}{}
+		Expect(yaml.Unmarshal(values, &connection)).To(Succeed())
+
+		kubeconfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(connection.Config.GardenClientConnection.BootstrapKubeconfig.Kubeconfig))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kubeconfig.Host).To(Equal("https://api.garden.example.org"))
+		Expect(kubeconfig.BearerToken).To(Equal(token.StringData["token-id"] + "." + token.StringData["token-secret"]))
+	})
+
+	It("deletes the seed's secrets and bootstrap token", func() {
+		inFluxNamespace := func(name string) metav1.ObjectMeta {
+			return metav1.ObjectMeta{Namespace: common.FLUX_NAMESPACE, Name: name}
+		}
+		kubeClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).WithObjects(
+			&corev1.Secret{ObjectMeta: inFluxNamespace("seed-" + seed.Name + "-kubeconfig")},
+			&corev1.Secret{ObjectMeta: inFluxNamespace("seed-" + seed.Name + "-garden-connection")},
+			&corev1.Secret{ObjectMeta: inFluxNamespace("seed-other-kubeconfig")},
+		).Build()
+		gardenClient := fake.NewClientBuilder().WithScheme(utils.NewScheme()).Build()
+		_, tokenName, err := install.CreateGardenConnection(gardenClient, seed.Name, "https://api.garden.example.org", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(install.DeleteSeedCredentials(kubeClient, gardenClient, seed.Name, tokenName)).To(Succeed())
+
+		secrets := corev1.SecretList{}
+		Expect(kubeClient.List(context.Background(), &secrets)).To(Succeed())
+		Expect(secrets.Items).To(ConsistOf(HaveField("Name", "seed-other-kubeconfig")))
+		Expect(gardenClient.List(context.Background(), &secrets)).To(Succeed())
+		Expect(secrets.Items).To(BeEmpty())
+
+		// deleting them again, e.g. after a partial failure, isn't an error
+		Expect(install.DeleteSeedCredentials(kubeClient, gardenClient, seed.Name, tokenName)).To(Succeed())
+	})
+
+	Describe("the seed's kubeconfig", func() {
+		var kubeconfig string
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(path.Join(dir, "ca.crt"), []byte("my-ca"), 0600)).To(Succeed())
+
+			kubeconfig = path.Join(dir, "kubeconfig")
+			Expect(os.WriteFile(kubeconfig, []byte(`
+apiVersion: v1
+kind: Config
+current-context: base
+clusters:
+  - name: base
+    cluster:
+      server: https://base.example.org
+  - name: seed
+    cluster:
+      server: https://seed.example.org
+      certificate-authority: ca.crt
+contexts:
+  - name: base
+    context: {cluster: base, user: sso}
+  - name: seed
+    context: {cluster: seed, user: seed}
+users:
+  - name: sso
+    user:
+      exec:
+        apiVersion: client.authentication.k8s.io/v1beta1
+        command: kubectl-oidc_login
+  - name: seed
+    user:
+      token: my-token
+`), 0600)).To(Succeed())
+		})
+
+		It("keeps only the chosen context and inlines its certificates", func() {
+			raw, err := install.LoadSeedKubeconfig(kubeconfig, "seed")
+			Expect(err).NotTo(HaveOccurred())
+
+			config, err := clientcmd.Load(raw)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.CurrentContext).To(Equal("seed"))
+			Expect(config.Clusters).To(HaveLen(1))
+			Expect(config.Clusters["seed"].CertificateAuthorityData).To(BeEquivalentTo("my-ca"))
+			Expect(config.Clusters["seed"].CertificateAuthority).To(BeEmpty())
+			Expect(config.AuthInfos).To(HaveKey("seed"))
+			Expect(config.AuthInfos).NotTo(HaveKey("sso"))
+		})
+
+		It("rejects authentication plugins and unknown contexts", func() {
+			_, err := install.LoadSeedKubeconfig(kubeconfig, "")
+			Expect(err).To(MatchError(ContainSubstring("authenticates with a plugin")))
+
+			_, err = install.LoadSeedKubeconfig(kubeconfig, "other")
+			Expect(err).To(MatchError(ContainSubstring("there's no context 'other'")))
+		})
+	})
+})
@@ -0,0 +1,330 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/sops"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// sopsAgeIdentity is the age identity of the sops-age secret, set by ensureSopsAgeKey.
+var sopsAgeIdentity string
+
+// ensureSopsAgeKey reads the age key flux decrypts the config repo with, or generates it if
+// the secret doesn't exist yet. Its recipient is stored as sops.ageRecipient.
+func ensureSopsAgeKey(kubeClient client.Client) error {
+	sec := corev1.Secret{}
+	err := kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.SOPS_AGE_SECRET_NAME,
+	}, &sec)
+	exists := err == nil
+
+	var identity string
+	if exists {
+		identity = string(sec.Data[sops.AGE_KEY_FILE])
+		fmt.Println(`An age key was already deployed to your cluster and I did not change it.`)
+	} else {
+		identity, _, err = sops.GenerateAgeKey()
+		if err != nil {
+			return err
+		}
+
+		sec = corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      common.SOPS_AGE_SECRET_NAME,
+				Namespace: common.FLUX_NAMESPACE,
+			},
+			StringData: map[string]string{
+				sops.AGE_KEY_FILE: identity,
+			},
+			Type: "Opaque",
+		}
+
+		err = Container.Create(context.Background(), &sec)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(`I created an age key for you. Secrets in the config repo are encrypted with it.`)
+		common.PrintWarn(fmt.Sprintf(`Back it up! Without it the secrets in the config repo can't be decrypted:
+kubectl -n %s get secret %s -o jsonpath='{.data.age\.agekey}' | base64 -d`, common.FLUX_NAMESPACE, common.SOPS_AGE_SECRET_NAME))
+	}
+
+	recipient, err := sops.RecipientOf(identity)
+	if err != nil {
+		return fmt.Errorf("couldn't read the age key in secret %s: %w", common.SOPS_AGE_SECRET_NAME, err)
+	}
+
+	sopsAgeIdentity = identity
+	viper.Set("sops.ageRecipient", recipient)
+
+	return nil
+}
+
+// encryptSecretManifest encrypts the given manifest if sops is enabled and it is a Secret.
+func encryptSecretManifest(manifest []byte, config sopsConfig) ([]byte, error) {
+	if !config.Enabled || !sops.IsSecret(manifest) {
+		return manifest, nil
+	}
+
+	if config.AgeRecipient == "" {
+		return nil, errors.New("sops is enabled, but there is no age recipient to encrypt secrets for")
+	}
+
+	return sops.Encrypt(manifest, config.AgeRecipient)
+}
+
+// keepUnchangedSecrets restores the committed version of encrypted secrets whose content
+// didn't change. sops encrypts with a new data key every time, so they'd change with
+// every update of the config repo otherwise.
+func keepUnchangedSecrets(repository *git.Repository, filesystem billy.Filesystem) error {
+	if sopsAgeIdentity == "" {
+		return nil
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		// nothing committed yet
+		return nil
+	}
+
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	files, err := commit.Files()
+	if err != nil {
+		return err
+	}
+
+	return files.ForEach(func(file *object.File) error {
+		committed, err := file.Contents()
+		if err != nil {
+			return err
+		}
+
+		current, err := util.ReadFile(filesystem, file.Name)
+		if err != nil {
+			// the file was removed
+			return nil
+		}
+
+		if !sops.IsEncrypted([]byte(committed)) || !sops.IsEncrypted(current) {
+			return nil
+		}
+
+		committedPlain, err := sops.Decrypt([]byte(committed), sopsAgeIdentity)
+		if err != nil {
+			// e.g. encrypted with another key, which is fixed by re-encrypting
+			return nil
+		}
+
+		currentPlain, err := sops.Decrypt(current, sopsAgeIdentity)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(committedPlain, currentPlain) {
+			return nil
+		}
+
+		return util.WriteFile(filesystem, file.Name, []byte(committed), 0600)
+	})
+}
+
+// EditSecret decrypts a secret in the config repo, opens it in $EDITOR and pushes the
+// re-encrypted result. If file is empty, the secret to edit is prompted for.
+func EditSecret(kubeconfig string, file string) error {
+	_, _, kubeClient, err := common.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	sec := corev1.Secret{}
+	err = kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.CONFIG_23KE_GITREPO_KEY,
+	}, &sec)
+	if err != nil {
+		return fmt.Errorf("couldn't read the deploy key of the config repo: %w", err)
+	}
+
+	gitAuth, err := gitAuthFromSecret(&sec)
+	if err != nil {
+		return err
+	}
+
+	ageSec := corev1.Secret{}
+	err = kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.SOPS_AGE_SECRET_NAME,
+	}, &ageSec)
+	if err != nil {
+		return fmt.Errorf("couldn't read the age key, is sops.enabled set? %w", err)
+	}
+
+	restoreHostKeys, err := usePinnedHostKeys()
+	if err != nil {
+		return err
+	}
+	defer restoreHostKeys()
+
+	return editConfigRepoSecret(gitAuth, string(ageSec.Data[sops.AGE_KEY_FILE]), file)
+}
+
+func editConfigRepoSecret(auth transport.AuthMethod, identity string, file string) error {
+	recipient, err := sops.RecipientOf(identity)
+	if err != nil {
+		return err
+	}
+
+	repository, worktree, workTreeFs, err := cloneConfigRepo(auth)
+	if err != nil {
+		return err
+	}
+
+	if file == "" {
+		file, err = queryEncryptedFile(repository)
+		if err != nil {
+			return err
+		}
+	}
+
+	encrypted, err := util.ReadFile(workTreeFs, file)
+	if err != nil {
+		return fmt.Errorf("couldn't read %s from the config repo: %w", file, err)
+	}
+
+	decrypted, err := sops.Decrypt(encrypted, identity)
+	if err != nil {
+		return fmt.Errorf("couldn't decrypt %s: %w", file, err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "23kectl-*-"+path.Base(file))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(decrypted)
+	tmpFile.Close()
+	if err != nil {
+		return err
+	}
+
+	err = Container.OpenEditor(tmpFile.Name())
+	if err != nil {
+		return err
+	}
+
+	edited, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(edited, decrypted) {
+		fmt.Println("No changes, not committing anything.")
+		return nil
+	}
+
+	encrypted, err = sops.Encrypt(edited, recipient)
+	if err != nil {
+		return fmt.Errorf("couldn't encrypt %s: %w", file, err)
+	}
+
+	err = util.WriteFile(workTreeFs, file, encrypted, 0600)
+	if err != nil {
+		return err
+	}
+
+	return commitConfigRepo(repository, worktree, auth, "Edit "+file+" through 23kectl")
+}
+
+// queryEncryptedFile asks which of the encrypted files in the config repo to edit.
+func queryEncryptedFile(repository *git.Repository) (string, error) {
+	head, err := repository.Head()
+	if err != nil {
+		return "", fmt.Errorf("the config repo is empty: %w", err)
+	}
+
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return "", err
+	}
+
+	files, err := commit.Files()
+	if err != nil {
+		return "", err
+	}
+
+	var encryptedFiles []string
+	err = files.ForEach(func(file *object.File) error {
+		content, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		if sops.IsEncrypted([]byte(content)) {
+			encryptedFiles = append(encryptedFiles, file.Name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(encryptedFiles) == 0 {
+		return "", errors.New("there are no encrypted files in the config repo")
+	}
+
+	prompt := &survey.Select{
+		Message: "Which secret do you want to edit?",
+		Options: encryptedFiles,
+	}
+	var queryResult string
+	err = common.AskOne(prompt, &queryResult, "required")
+	common.ExitOnCtrlC(err)
+	if err != nil {
+		return "", err
+	}
+
+	return queryResult, nil
+}
+
+// openEditor opens the given file in $EDITOR, vi if it isn't set.
+func openEditor(file string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], file)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
@@ -0,0 +1,162 @@
+package install_test
+
+import (
+	"os"
+	"path"
+
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/23technologies/23kectl/pkg/sops"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+const plainSecret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: identity-values
+  namespace: flux-system
+type: Opaque
+stringData:
+  values.yaml: |
+    staticPasswords:
+    - email: test@example.org
+      hash: my-password-hash
+`
+
+var _ = Describe("EncryptSecretManifest", func() {
+	var recipient string
+
+	BeforeEach(func() {
+		var err error
+		_, recipient, err = sops.GenerateAgeKey()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("encrypts Secrets if sops is enabled", func() {
+		encrypted, err := install.EncryptSecretManifest([]byte(plainSecret), install.SopsConfig{Enabled: true, AgeRecipient: recipient})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sops.IsEncrypted(encrypted)).To(BeTrue())
+		Expect(string(encrypted)).NotTo(ContainSubstring("my-password-hash"))
+	})
+
+	It("leaves other manifests alone", func() {
+		manifest := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n")
+		result, err := install.EncryptSecretManifest(manifest, install.SopsConfig{Enabled: true, AgeRecipient: recipient})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(manifest))
+	})
+
+	It("leaves Secrets alone if sops is disabled", func() {
+		result, err := install.EncryptSecretManifest([]byte(plainSecret), install.SopsConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(result)).To(Equal(plainSecret))
+	})
+
+	It("fails without a recipient", func() {
+		_, err := install.EncryptSecretManifest([]byte(plainSecret), install.SopsConfig{Enabled: true})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("EditConfigRepoSecret", Ordered, func() {
+	const branch = "sops-test"
+	const file = "config/identity-values.yaml"
+	var repoPath, identity string
+
+	// headOf returns the head commit of the test branch and the decrypted content of file
+	headOf := func() (*object.Commit, []byte) {
+		repo, err := git.PlainOpen(repoPath)
+		Expect(err).NotTo(HaveOccurred())
+		ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		Expect(err).NotTo(HaveOccurred())
+		commit, err := repo.CommitObject(ref.Hash())
+		Expect(err).NotTo(HaveOccurred())
+		f, err := commit.File(file)
+		Expect(err).NotTo(HaveOccurred())
+		content, err := f.Contents()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sops.IsEncrypted([]byte(content))).To(BeTrue())
+		decrypted, err := sops.Decrypt([]byte(content), identity)
+		Expect(err).NotTo(HaveOccurred())
+		return commit, decrypted
+	}
+
+	BeforeAll(func() {
+		var recipient string
+		var err error
+		identity, recipient, err = sops.GenerateAgeKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		root := GinkgoT().TempDir()
+		repoPath = path.Join(root, "config.git")
+		bare, err := git.PlainInit(repoPath, true)
+		Expect(err).NotTo(HaveOccurred())
+		// like on a git hosting provider, HEAD points to an existing branch
+		Expect(bare.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(branch)))).To(Succeed())
+
+		// commit an encrypted secret to the test branch
+		workDir := path.Join(root, "work")
+		work, err := git.PlainInit(workDir, false)
+		Expect(err).NotTo(HaveOccurred())
+		encrypted, err := sops.Encrypt([]byte(plainSecret), recipient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(path.Join(workDir, "config"), 0700)).To(Succeed())
+		Expect(os.WriteFile(path.Join(workDir, file), encrypted, 0600)).To(Succeed())
+		worktree, err := work.Worktree()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = worktree.Add(file)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = worktree.Commit("initial", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.org"}})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = work.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{repoPath}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(work.Push(&git.PushOptions{RefSpecs: []gitconfig.RefSpec{"refs/heads/master:refs/heads/" + branch}})).To(Succeed())
+
+		viper.Set("admin.gitrepourl", "file://"+repoPath)
+		viper.Set("admin.gitrepobranch", branch)
+
+		openEditor := install.Container.OpenEditor
+		DeferCleanup(func() {
+			install.Container.OpenEditor = openEditor
+		})
+	})
+
+	It("doesn't commit if nothing changed", func() {
+		before, _ := headOf()
+		install.Container.OpenEditor = func(_ string) error { return nil }
+
+		Expect(install.EditConfigRepoSecret(nil, identity, file)).To(Succeed())
+
+		after, _ := headOf()
+		Expect(after.Hash).To(Equal(before.Hash))
+	})
+
+	It("pushes the re-encrypted secret", func() {
+		install.Container.OpenEditor = func(f string) error {
+			content, err := os.ReadFile(f)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("my-password-hash"))
+
+			return os.WriteFile(f, []byte(plainSecret+"    - email: other@example.org\n      hash: other-hash\n"), 0600)
+		}
+
+		Expect(install.EditConfigRepoSecret(nil, identity, file)).To(Succeed())
+
+		commit, decrypted := headOf()
+		Expect(commit.Message).To(ContainSubstring(file))
+		Expect(string(decrypted)).To(ContainSubstring("other-hash"))
+	})
+
+	It("fails to decrypt with another age key", func() {
+		otherIdentity, _, err := sops.GenerateAgeKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		err = install.EditConfigRepoSecret(nil, otherIdentity, file)
+		Expect(err).To(MatchError(ContainSubstring("couldn't decrypt")))
+	})
+})
@@ -10,6 +10,7 @@ import (
 	"github.com/23technologies/23kectl/pkg/logger"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -49,6 +50,15 @@ func Test(t *testing.T) {
 	RunSpecs(t, "Suite")
 }
 
+// setConfig sets a config key for the current spec and restores its previous
+// value afterwards. viper.Reset would also drop the config of the Ordered
+// install spec, which depends on the order ginkgo runs the containers in.
+func setConfig(key string, value interface{}) {
+	previous := viper.Get(key)
+	DeferCleanup(func() { viper.Set(key, previous) })
+	viper.Set(key, value)
+}
+
 func createK8sTestenv(configPath string) (*envtest.Environment, client.WithWatch) {
 	testEnv := &envtest.Environment{}
 	cfg, err := testEnv.Start()
@@ -1,6 +1,7 @@
 package install
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
 	"io/fs"
@@ -13,6 +14,7 @@ import (
 	"github.com/23technologies/23kectl/pkg/common"
 	"github.com/Masterminds/sprig/v3"
 	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,6 +29,17 @@ func newDomainConfigAzure(domain string) domainConfiguration {
 	}
 }
 
+// Azure private DNS zones use the same credentials as public ones
+func newDomainConfigAzurePrivate(domain string) domainConfiguration {
+	var dnsCredentials dnsCredentialsAzure
+	dnsCredentials.parseCredentials()
+	return domainConfiguration{
+		Domain:      domain,
+		Provider:    common.DNS_PROVIDER_AZURE_PRIVATE_DNS,
+		Credentials: &dnsCredentials,
+	}
+}
+
 func newDomainOSDesignate(domain string) domainConfiguration {
 	var dnsCredentials dnsCredentialsOSDesignate
 	dnsCredentials.parseCredentials()
@@ -47,6 +60,26 @@ func newDomainAWS53(domain string) domainConfiguration {
 	}
 }
 
+func newDomainGCP(domain string) (domainConfiguration, error) {
+	var dnsCredentials dnsCredentialsGCP
+	err := dnsCredentials.parseCredentials()
+	return domainConfiguration{
+		Domain:      domain,
+		Provider:    common.DNS_PROVIDER_GOOGLE_CLOUDDNS,
+		Credentials: &dnsCredentials,
+	}, err
+}
+
+func newDomainAlicloud(domain string) domainConfiguration {
+	var dnsCredentials dnsCredentialsAlicloud
+	dnsCredentials.parseCredentials()
+	return domainConfiguration{
+		Domain:      domain,
+		Provider:    common.DNS_PROVIDER_ALICLOUD_DNS,
+		Credentials: &dnsCredentials,
+	}
+}
+
 func createDomainConfiguration(domain string, dnsProvider string) (domainConfiguration, error) {
 
 	switch dnsProvider {
@@ -56,6 +89,12 @@ func createDomainConfiguration(domain string, dnsProvider string) (domainConfigu
 		return newDomainOSDesignate(domain), nil
 	case common.DNS_PROVIDER_AWS_ROUTE_53:
 		return newDomainAWS53(domain), nil
+	case common.DNS_PROVIDER_AZURE_PRIVATE_DNS:
+		return newDomainConfigAzurePrivate(domain), nil
+	case common.DNS_PROVIDER_GOOGLE_CLOUDDNS:
+		return newDomainGCP(domain)
+	case common.DNS_PROVIDER_ALICLOUD_DNS:
+		return newDomainAlicloud(domain), nil
 	}
 
 	return domainConfiguration{}, fmt.Errorf("input invalid for domain configuration")
@@ -63,8 +102,16 @@ func createDomainConfiguration(domain string, dnsProvider string) (domainConfigu
 
 func createBackupConfiguration(provider string) (backupConfiguration, error) {
 	switch provider {
-	case "azure":
+	case common.BUCKET_PROVIDER_AZURE:
 		return newBackupConfigAzure(), nil
+	case common.BUCKET_PROVIDER_AWS:
+		return newBackupConfigAWS(), nil
+	case common.BUCKET_PROVIDER_GCP:
+		return newBackupConfigGCP()
+	case common.BUCKET_PROVIDER_OPENSTACK:
+		return newBackupConfigOpenStack(), nil
+	case common.BUCKET_PROVIDER_S3:
+		return newBackupConfigS3(), nil
 	}
 
 	return backupConfiguration{}, fmt.Errorf("input invalid for backup configuration")
@@ -74,7 +121,43 @@ func newBackupConfigAzure() backupConfiguration {
 	var credentials backupCredentialsAzure
 	credentials.parseCredentials()
 	return backupConfiguration{
-		Provider:    "azure",
+		Provider:    common.BUCKET_PROVIDER_AZURE,
+		Credentials: &credentials,
+	}
+}
+
+func newBackupConfigAWS() backupConfiguration {
+	var credentials backupCredentialsAWS
+	credentials.parseCredentials()
+	return backupConfiguration{
+		Provider:    common.BUCKET_PROVIDER_AWS,
+		Credentials: &credentials,
+	}
+}
+
+func newBackupConfigGCP() (backupConfiguration, error) {
+	var credentials backupCredentialsGCP
+	err := credentials.parseCredentials()
+	return backupConfiguration{
+		Provider:    common.BUCKET_PROVIDER_GCP,
+		Credentials: &credentials,
+	}, err
+}
+
+func newBackupConfigOpenStack() backupConfiguration {
+	var credentials backupCredentialsOpenStack
+	credentials.parseCredentials()
+	return backupConfiguration{
+		Provider:    common.BUCKET_PROVIDER_OPENSTACK,
+		Credentials: &credentials,
+	}
+}
+
+func newBackupConfigS3() backupConfiguration {
+	var credentials backupCredentialsS3
+	credentials.parseCredentials()
+	return backupConfiguration{
+		Provider:    common.BUCKET_PROVIDER_S3,
 		Credentials: &credentials,
 	}
 }
@@ -107,52 +190,98 @@ func makeTemplate() *template.Template {
 	return tpl
 }
 
-//go:embed __embed__/config
+//go:embed __embed__/config __embed__/seed
 var embedFS embed.FS
 var configTemplate *template.Template
+var seedTemplate *template.Template
 
 func getConfigTemplate() (*template.Template, error) {
 
 	if configTemplate == nil {
-		templateRoot := "__embed__/config"
-		templatePattern := regexp.MustCompile(`\.yaml$`)
+		tpl, err := parseTemplateDir("__embed__/config")
+		if err != nil {
+			return nil, err
+		}
 
-		tpl := makeTemplate()
+		configTemplate = tpl
+	}
 
-		// We don't use tpl.ParseFS here to keep the folder structure in the template name.
-		err := fs.WalkDir(embedFS, templateRoot, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
+	return configTemplate, nil
+}
 
-			if d.IsDir() {
-				return nil
-			}
+// getSeedTemplate returns the templates rendered for every additional seed.
+func getSeedTemplate() (*template.Template, error) {
 
-			if !templatePattern.MatchString(path) {
-				return nil
-			}
+	if seedTemplate == nil {
+		tpl, err := parseTemplateDir("__embed__/seed")
+		if err != nil {
+			return nil, err
+		}
 
-			name := strings.Replace(path, templateRoot+"/", "", 1)
-			content, err := fs.ReadFile(embedFS, path)
-			if err != nil {
-				return err
-			}
-			_, err = tpl.New(name).Parse(string(content))
-			if err != nil {
-				return err
-			}
+		seedTemplate = tpl
+	}
+
+	return seedTemplate, nil
+}
+
+func parseTemplateDir(templateRoot string) (*template.Template, error) {
+	templatePattern := regexp.MustCompile(`\.yaml$`)
+
+	tpl := makeTemplate()
 
+	// We don't use tpl.ParseFS here to keep the folder structure in the template name.
+	err := fs.WalkDir(embedFS, templateRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		if !templatePattern.MatchString(path) {
 			return nil
-		})
+		}
+
+		name := strings.Replace(path, templateRoot+"/", "", 1)
+		content, err := fs.ReadFile(embedFS, path)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		_, err = tpl.New(name).Parse(string(content))
+		if err != nil {
+			return err
 		}
 
-		configTemplate = tpl
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return configTemplate, nil
+	return tpl, nil
+}
+
+// renderConfigTemplate executes the config template of the given name, e.g.
+// config/gardenlet-values.yaml, with keConfig.
+func renderConfigTemplate(name string, keConfig *KeConfig) ([]byte, error) {
+	configTemplate, err := getConfigTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := configTemplate.Lookup(name)
+	if tpl == nil {
+		return nil, fmt.Errorf("there's no config template %s", name)
+	}
+
+	var manifest bytes.Buffer
+	err = tpl.Execute(&manifest, keConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.Bytes(), nil
 }
 
 func writeConfigDir(filesystem billy.Filesystem, subFolder string) error {
@@ -177,13 +306,125 @@ func writeConfigDir(filesystem billy.Filesystem, subFolder string) error {
 			return err
 		}
 
-		file, err := filesystem.OpenFile(destPath, os.O_CREATE|os.O_WRONLY, 0600)
+		manifest, err := renderConfigTemplate(name, keConfig)
+		if err != nil {
+			return err
+		}
+
+		content, err := encryptSecretManifest(manifest, keConfig.Sops)
+		if err != nil {
+			return fmt.Errorf("couldn't encrypt %s: %w", name, err)
+		}
+
+		err = util.WriteFile(filesystem, destPath, content, 0600)
 		if err != nil {
 			return err
 		}
+	}
+
+	if len(keConfig.Seeds) == 0 {
+		return nil
+	}
+
+	chart, err := getGardenletChart()
+	if err != nil {
+		return err
+	}
+
+	for _, seed := range keConfig.Seeds {
+		err = writeSeedDir(filesystem, subFolder, seed, chart, keConfig.Sops)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gardenletChart is the chart the gardenlets of additional seeds are deployed
+// with, see getGardenletChart.
+type gardenletChart struct {
+	Chart           string
+	Version         string
+	SourceKind      string
+	SourceName      string
+	SourceNamespace string
+}
+
+// seedTemplateData is what the seed templates are executed with.
+type seedTemplateData struct {
+	Seed                       seedConfig
+	KubeconfigSecretName       string
+	GardenConnectionSecretName string
+	GardenletChart             gardenletChart
+	Sops                       sopsConfig
+}
+
+// seedFilePath returns the path of a seed template's file in the config repo.
+// The flux Kustomization is listed in the root kustomization.yaml, everything
+// else is applied by it from seeds/<name>/.
+func seedFilePath(templateName string, seedName string) string {
+	if templateName == "flux.yaml" {
+		return path.Join("flux", "23ke-env-seed-"+seedName+".yaml")
+	}
+
+	return path.Join("seeds", seedName, templateName)
+}
+
+// renderSeedTemplate executes the seed template of the given name for seed.
+func renderSeedTemplate(name string, seed seedConfig, chart gardenletChart, sops sopsConfig) ([]byte, error) {
+	seedTemplate, err := getSeedTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := seedTemplate.Lookup(name)
+	if tpl == nil {
+		return nil, fmt.Errorf("there's no seed template %s", name)
+	}
+
+	var manifest bytes.Buffer
+	err = tpl.Execute(&manifest, seedTemplateData{
+		Seed:                       seed,
+		KubeconfigSecretName:       seedKubeconfigSecretName(seed.Name),
+		GardenConnectionSecretName: seedGardenConnectionSecretName(seed.Name),
+		GardenletChart:             chart,
+		Sops:                       sops,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.Bytes(), nil
+}
+
+func writeSeedDir(filesystem billy.Filesystem, subFolder string, seed seedConfig, chart gardenletChart, sops sopsConfig) error {
+	seedTemplate, err := getSeedTemplate()
+	if err != nil {
+		return err
+	}
+
+	for _, tpl := range seedTemplate.Templates() {
+		name := tpl.Name()
+
+		destPath := path.Join(subFolder, seedFilePath(name, seed.Name))
+
+		err := filesystem.MkdirAll(path.Dir(destPath), os.ModeDir|0700)
+		if err != nil {
+			return err
+		}
+
+		manifest, err := renderSeedTemplate(name, seed, chart, sops)
+		if err != nil {
+			return err
+		}
+
+		content, err := encryptSecretManifest(manifest, sops)
+		if err != nil {
+			return fmt.Errorf("couldn't encrypt %s: %w", destPath, err)
+		}
 
-		err = tpl.Execute(file, keConfig)
-		file.Close()
+		err = util.WriteFile(filesystem, destPath, content, 0600)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,75 @@
+package install_test
+
+import (
+	"os"
+	"path"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/23technologies/23kectl/pkg/install/v4"
+	"github.com/go-git/go-billy/v5/memfs"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("config templates of a base cluster", func() {
+	setBaseClusterConfig := func(provider string, region string, dnsProvider string, backupProvider string) {
+		setConfig("extensionsConfig", map[string]interface{}{})
+		setConfig("seeds", nil)
+		setConfig("baseCluster.provider", provider)
+		setConfig("baseCluster.region", region)
+		setConfig("baseCluster.nodeCidr", "10.250.0.0/16")
+		setConfig("baseCluster.hasVerticalPodAutoscaler", false)
+		setConfig("gardenlet.seedNodeCidr", "10.250.0.0/16")
+		setConfig("gardenlet.seedPodCidr", "100.96.0.0/11")
+		setConfig("gardenlet.seedServiceCidr", "100.64.0.0/13")
+		setConfig("cloudprofiles", []string{provider})
+		setConfig("domainConfig.provider", dnsProvider)
+		setConfig("backupConfig.enabled", backupProvider != "")
+		setConfig("backupConfig.provider", backupProvider)
+	}
+
+	DescribeTable("renders the seed and enables the provider extension",
+		func(provider string, region string, dnsProvider string, backupProvider string) {
+			setBaseClusterConfig(provider, region, dnsProvider, backupProvider)
+			install.EnableProviderExtensions()
+			Expect(viper.GetBool("extensionsConfig.provider-" + provider + ".enabled")).To(BeTrue())
+
+			keConfig := &install.KeConfig{}
+			Expect(install.UnmarshalKeConfig(keConfig)).To(Succeed())
+
+			for _, name := range []string{"gardenlet-values.yaml", "extensions-values.yaml", "cloudprofiles-values.yaml"} {
+				manifest, err := install.RenderConfigTemplate(path.Join("config", name), keConfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				expected, err := os.ReadFile(path.Join(cwd, "__fixture__", "base-clusters", provider, name))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(manifest)).To(Equal(string(expected)), name)
+			}
+		},
+		Entry("gcp", common.BASE_CLUSTER_PROVIDER_GCP, "europe-west3", common.DNS_PROVIDER_GOOGLE_CLOUDDNS, common.BUCKET_PROVIDER_GCP),
+		Entry("alicloud", common.BASE_CLUSTER_PROVIDER_ALICLOUD, "eu-central-1", common.DNS_PROVIDER_ALICLOUD_DNS, ""),
+	)
+
+	It("reports a region of another provider", func() {
+		setBaseClusterConfig(common.BASE_CLUSTER_PROVIDER_GCP, "europe-west3-a", common.DNS_PROVIDER_GOOGLE_CLOUDDNS, "")
+
+		violations, err := install.ValidateConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(ContainElement(common.ValidationError{
+			Path:  "baseCluster.region",
+			Tag:   "region=gcp",
+			Value: "europe-west3-a",
+		}))
+	})
+
+	It("fails to write the config dir with malformed DNS credentials", func() {
+		setBaseClusterConfig(common.BASE_CLUSTER_PROVIDER_HCLOUD, "hel1", common.DNS_PROVIDER_AWS_ROUTE_53, "")
+		setConfig("domainConfig.credentials", map[string]interface{}{
+			"AWS_ACCESS_KEY_ID": []string{"not", "a", "string"},
+		})
+
+		err := install.WriteConfigDir(memfs.New(), "config")
+		Expect(err).To(HaveOccurred())
+	})
+})
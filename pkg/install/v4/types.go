@@ -1,41 +1,86 @@
 package install
 
+import utils "github.com/23technologies/23kectl/pkg/fluxutils"
+
 type KeConfig struct {
-	Version          string              `yaml:"version"`
+	Version          string              `yaml:"version" validate:"required"`
 	BaseCluster      baseClusterConfig   `yaml:"baseCluster"`
 	Admin            admin               `yaml:"admin"`
-	ClusterIdentity  string              `yaml:"clusterIdentity"`
+	ClusterIdentity  string              `yaml:"clusterIdentity" validate:"required"`
 	Gardener         gardenerConfig      `yaml:"gardener"`
 	Gardenlet        gardenletConfig     `yaml:"gardenlet"`
 	Issuer           issuerConfig        `yaml:"issuer"`
 	DomainConfig     domainConfiguration `yaml:"domainConfig,omitempty"`
 	BackupConfig     backupConfiguration `yaml:"backupConfig,omitempty"`
 	ExtensionsConfig extensionsConfig    `yaml:"extensions"`
-	CloudProfiles    []string            `yaml:"cloudprofiles"`
+	CloudProfiles    []string            `yaml:"cloudprofiles" validate:"dive,required"`
+	Sops             sopsConfig          `yaml:"sops,omitempty"`
+	Seeds            []seedConfig        `yaml:"seeds,omitempty" validate:"dive"`
+}
+
+// SeedProviderTypes lists the providers of the initial seed and the
+// additional ones, without duplicates.
+func (c *KeConfig) SeedProviderTypes() []string {
+	providers := []string{c.BaseCluster.Provider}
+	for _, seed := range c.Seeds {
+		if !utils.ContainsItemString(providers, seed.Provider) {
+			providers = append(providers, seed.Provider)
+		}
+	}
+
+	return providers
 }
 
 type admin struct {
-	Email         string `yaml:"email"`
-	Password      string `yaml:"password"`
-	GitRepoURL    string `yaml:"gitrepourl"`
-	GitRepoBranch string `yaml:"gitrepobranch"`
+	Email         string `yaml:"email" validate:"required,email"`
+	Password      string `yaml:"password" validate:"required"`
+	GitRepoURL    string `yaml:"gitrepourl" validate:"required,url,gitremote"`
+	GitRepoBranch string `yaml:"gitrepobranch" validate:"required"`
+	// host keys of the ssh remote in known_hosts format, the host key is trusted on first use otherwise
+	KnownHosts string `yaml:"knownHosts,omitempty"`
+	// only used for https remotes
+	GitUsername string `yaml:"gitUsername,omitempty"`
+	GitToken    string `yaml:"gitToken,omitempty"`
+	// optional, used for registering the deploy key of the config repo automatically
+	GitProvider      string `yaml:"gitProvider,omitempty" validate:"omitempty,oneof=github gitlab gitea bitbucket"`
+	GitProviderURL   string `yaml:"gitProviderURL,omitempty" validate:"omitempty,url"`
+	GitProviderToken string `yaml:"gitProviderToken,omitempty"`
+}
+
+type sopsConfig struct {
+	// encrypt the secrets in the config repo
+	Enabled bool `yaml:"enabled"`
+	// public key of the sops-age secret, set by install and update
+	AgeRecipient string `yaml:"ageRecipient,omitempty"`
 }
 
 type baseClusterConfig struct {
-	HasVerticalPodAutoscaler *bool  `yaml:"hasVerticalPodAutoscaler"`
-	Provider                 string `yaml:"provider"`
-	Region                   string `yaml:"region"`
-	NodeCidr                 string `yaml:"nodeCidr"`
+	HasVerticalPodAutoscaler *bool  `yaml:"hasVerticalPodAutoscaler" validate:"required"`
+	Provider                 string `yaml:"provider" validate:"required,oneof=hcloud azure aws openstack gcp alicloud"`
+	Region                   string `yaml:"region" validate:"required"`
+	NodeCidr                 string `yaml:"nodeCidr" validate:"required,cidr"`
+}
+
+// seedConfig is an additional seed, registered with `23kectl seed add`.
+// Its gardenlet is deployed by flux with the kubeconfig in seedKubeconfigSecretName.
+type seedConfig struct {
+	Name                     string `yaml:"name" validate:"required,dns_rfc1035_label,ne=initial-seed"`
+	Provider                 string `yaml:"provider" validate:"required,oneof=hcloud azure aws openstack gcp alicloud"`
+	Region                   string `yaml:"region" validate:"required"`
+	HasVerticalPodAutoscaler *bool  `yaml:"hasVerticalPodAutoscaler" validate:"required"`
+	NodeCidr                 string `yaml:"nodeCidr" validate:"required,cidr"`
+	PodCidr                  string `yaml:"podCidr" validate:"required,cidr"`
+	ServiceCidr              string `yaml:"serviceCidr" validate:"required,cidr"`
 }
 
 type gardenerConfig struct {
-	ClusterIP string `yaml:"clusterIP"`
+	ClusterIP string `yaml:"clusterIP" validate:"required,ip"`
 }
 
 type gardenletConfig struct {
-	SeedNodeCidr    string `yaml:"seedNodeCidr"`
-	SeedPodCidr     string `yaml:"seedPodCidr"`
-	SeedServiceCidr string `yaml:"seedServiceCidr"`
+	SeedNodeCidr    string `yaml:"seedNodeCidr" validate:"required,cidr"`
+	SeedPodCidr     string `yaml:"seedPodCidr" validate:"required,cidr"`
+	SeedServiceCidr string `yaml:"seedServiceCidr" validate:"required,cidr"`
 }
 
 type issuerConfig struct {
@@ -44,51 +89,94 @@ type issuerConfig struct {
 }
 
 type acmeConfig struct {
-	Email  string `yaml:"email"`
-	Server string `yaml:"server,omitempty"`
+	Email  string `yaml:"email" validate:"required,email"`
+	Server string `yaml:"server,omitempty" validate:"omitempty,url"`
 }
 
 type domainConfiguration struct {
-	Domain      string      `yaml:"domain"`
-	Provider    string      `yaml:"provider"`
-	Credentials interface{} `yaml:"credentials"`
+	Domain      string      `yaml:"domain" validate:"required,fqdn"`
+	Provider    string      `yaml:"provider" validate:"required,oneof=azure-dns azure-private-dns openstack-designate aws-route53 google-clouddns alicloud-dns"`
+	Credentials interface{} `yaml:"credentials" validate:"required"`
 }
 
 type dnsCredentialsAzure struct {
-	TenantId       string `yaml:"tenantID"`
-	SubscriptionId string `yaml:"subscriptionID"`
-	ClientId       string `yaml:"clientID"`
-	ClientSecret   string `yaml:"clientSecret"`
+	TenantId       string `yaml:"tenantID" validate:"required"`
+	SubscriptionId string `yaml:"subscriptionID" validate:"required"`
+	ClientId       string `yaml:"clientID" validate:"required"`
+	ClientSecret   string `yaml:"clientSecret" validate:"required"`
 }
 
 // https://github.com/gardener/external-dns-management/blob/master/examples/20-secret-openstack-credentials.yaml
 type dnsCredentialsOSDesignate struct {
-	ApplicationCredentialID     string `yaml:"OS_APPLICATION_CREDENTIAL_ID"`
-	ApplicationCredentialSecret string `yaml:"OS_APPLICATION_CREDENTIAL_SECRET"`
-	AuthURL                     string `yaml:"OS_AUTH_URL"`
+	ApplicationCredentialID     string `yaml:"OS_APPLICATION_CREDENTIAL_ID" validate:"required"`
+	ApplicationCredentialSecret string `yaml:"OS_APPLICATION_CREDENTIAL_SECRET" validate:"required"`
+	AuthURL                     string `yaml:"OS_AUTH_URL" validate:"required,encodedurl"`
 }
 
 // https://github.com/gardener/external-dns-management/blob/master/examples/20-secret-aws-credentials.yaml
 type dnsCredentialsAWS53 struct {
-	AccessKeyID     string `yaml:"AWS_ACCESS_KEY_ID"`
-	SecretAccessKey string `yaml:"AWS_SECRET_ACCESS_KEY"`
+	AccessKeyID     string `yaml:"AWS_ACCESS_KEY_ID" validate:"required"`
+	SecretAccessKey string `yaml:"AWS_SECRET_ACCESS_KEY" validate:"required"`
+}
+
+// https://github.com/gardener/external-dns-management/blob/master/examples/20-secret-google-credentials.yaml
+// viper splits config keys at dots, so the config file can't use the secret's key serviceaccount.json.
+type dnsCredentialsGCP struct {
+	ServiceAccountJSON string `yaml:"serviceAccountJSON" validate:"required"`
+}
+
+func (d dnsCredentialsGCP) secretData() map[string]string {
+	return map[string]string{"serviceaccount.json": d.ServiceAccountJSON}
+}
+
+// dnsSecretData is implemented by DNS credentials which are stored under other keys in the
+// provider secret than in the config file.
+type dnsSecretData interface {
+	secretData() map[string]string
+}
+
+// https://github.com/gardener/external-dns-management/blob/master/examples/20-secret-alicloud-credentials.yaml
+type dnsCredentialsAlicloud struct {
+	AccessKeyID     string `yaml:"ACCESS_KEY_ID" validate:"required"`
+	AccessKeySecret string `yaml:"ACCESS_KEY_SECRET" validate:"required"`
 }
 
 type backupConfiguration struct {
 	Enabled     bool        `yaml:"enabled"`
-	Provider    string      `yaml:"provider,omitempty"`
-	Region      string      `yaml:"region,omitempty"`
-	BucketName  string      `yaml:"bucketName,omitempty"`
-	Credentials interface{} `yaml:"credentials,omitempty"`
+	Provider    string      `yaml:"provider,omitempty" validate:"required_if=Enabled true,omitempty,oneof=azure aws gcp openstack s3"`
+	Region      string      `yaml:"region,omitempty" validate:"required_if=Enabled true"`
+	BucketName  string      `yaml:"bucketName,omitempty" validate:"required_if=Enabled true"`
+	Credentials interface{} `yaml:"credentials,omitempty" validate:"required_if=Enabled true"`
 }
 
 type backupCredentialsAzure struct {
-	TenantId                string `yaml:"tenantID"`
-	SubscriptionId          string `yaml:"subscriptionID"`
-	ClientId                string `yaml:"clientID"`
-	ClientSecret            string `yaml:"clientSecret"`
-	StorageAccount          string `yaml:"storageAccount"`
-	StorageAccountAccessKey string `yaml:"storageAccountAccessKey"`
+	TenantId                string `yaml:"tenantID" validate:"required"`
+	SubscriptionId          string `yaml:"subscriptionID" validate:"required"`
+	ClientId                string `yaml:"clientID" validate:"required"`
+	ClientSecret            string `yaml:"clientSecret" validate:"required"`
+	StorageAccount          string `yaml:"storageAccount" validate:"required"`
+	StorageAccountAccessKey string `yaml:"storageAccountAccessKey" validate:"required"`
+}
+
+type backupCredentialsAWS struct {
+	AccessKeyID     string `yaml:"accessKeyID" validate:"required"`
+	SecretAccessKey string `yaml:"secretAccessKey" validate:"required"`
+}
+
+type backupCredentialsGCP struct {
+	ServiceAccountJSON string `yaml:"serviceAccountJSON" validate:"required"`
+}
+
+type backupCredentialsOpenStack struct {
+	ApplicationCredentialID     string `yaml:"applicationCredentialID" validate:"required"`
+	ApplicationCredentialSecret string `yaml:"applicationCredentialSecret" validate:"required"`
+	AuthURL                     string `yaml:"authURL" validate:"required,encodedurl"`
+}
+
+type backupCredentialsS3 struct {
+	AccessKeyID     string `yaml:"accessKeyID" validate:"required"`
+	SecretAccessKey string `yaml:"secretAccessKey" validate:"required"`
+	Endpoint        string `yaml:"endpoint" validate:"required,encodedurl"`
 }
 
 type extensionsConfig map[string]map[string]bool
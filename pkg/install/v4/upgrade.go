@@ -0,0 +1,56 @@
+package install
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reconfigure renders the current configuration into the '23ke-config' secret
+// and the config repo of an existing installation.
+func Reconfigure(kubeconfig string) error {
+	_, _, kubeClient, err := common.CreateKubeClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+	Container.Create = kubeClient.Create
+	Container.List = kubeClient.List
+
+	sec := corev1.Secret{}
+	err = kubeClient.Get(context.Background(), client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.CONFIG_23KE_GITREPO_KEY,
+	}, &sec)
+	if err != nil {
+		return fmt.Errorf("couldn't read the deploy key of the config repo: %w", err)
+	}
+
+	gitAuth, err := gitAuthFromSecret(&sec)
+	if err != nil {
+		return err
+	}
+
+	restoreHostKeys, err := usePinnedHostKeys()
+	if err != nil {
+		return err
+	}
+	defer restoreHostKeys()
+
+	if viper.GetBool("sops.enabled") {
+		err = ensureSopsAgeKey(kubeClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = create23keConfigSecret(kubeClient)
+	if err != nil {
+		return err
+	}
+
+	return updateConfigRepo(gitAuth)
+}
@@ -0,0 +1,146 @@
+package install
+
+import (
+	"fmt"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	utils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// ValidateConfig checks the current configuration against the KeConfig schema
+// of this install package and returns all violations.
+func ValidateConfig() ([]common.ValidationError, error) {
+	keConfig := &KeConfig{}
+	err := viper.Unmarshal(keConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	violations, err := common.ValidateStruct(keConfig, "")
+	if err != nil {
+		return nil, err
+	}
+
+	credViolations, err := validateCredentials(keConfig.DomainConfig.Credentials, newDNSCredentials(keConfig.DomainConfig.Provider), "domainConfig.credentials")
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, credViolations...)
+
+	// the region can only be checked once the provider is known
+	if keConfig.BaseCluster.Region != "" && !common.IsValidRegion(keConfig.BaseCluster.Provider, keConfig.BaseCluster.Region) {
+		violations = append(violations, common.ValidationError{
+			Path:  "baseCluster.region",
+			Tag:   "region=" + keConfig.BaseCluster.Provider,
+			Value: keConfig.BaseCluster.Region,
+		})
+	}
+
+	seedNames := []string{}
+	for i, seed := range keConfig.Seeds {
+		path := fmt.Sprintf("seeds[%d]", i)
+		if seed.Region != "" && !common.IsValidRegion(seed.Provider, seed.Region) {
+			violations = append(violations, common.ValidationError{
+				Path:  path + ".region",
+				Tag:   "region=" + seed.Provider,
+				Value: seed.Region,
+			})
+		}
+		if utils.ContainsItemString(seedNames, seed.Name) {
+			violations = append(violations, common.ValidationError{
+				Path:  path + ".name",
+				Tag:   "unique",
+				Value: seed.Name,
+			})
+		}
+		seedNames = append(seedNames, seed.Name)
+	}
+
+	if isHTTPSRemote(keConfig.Admin.GitRepoURL) && keConfig.Admin.GitToken == "" {
+		violations = append(violations, common.ValidationError{
+			Path:  "admin.gitToken",
+			Tag:   "required_with_https_remote",
+			Value: keConfig.Admin.GitToken,
+		})
+	}
+
+	if keConfig.BackupConfig.Enabled {
+		credViolations, err = validateCredentials(keConfig.BackupConfig.Credentials, newBackupCredentials(keConfig.BackupConfig.Provider), "backupConfig.credentials")
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, credViolations...)
+	}
+
+	return violations, nil
+}
+
+// validateCredentials decodes raw into the credentials struct of the chosen provider and validates it.
+// Nothing is validated for unknown providers, as the provider itself is already reported.
+func validateCredentials(raw interface{}, creds interface{}, path string) ([]common.ValidationError, error) {
+	if raw == nil || creds == nil {
+		return nil, nil
+	}
+
+	creds, err := decodeCredentials(raw, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.ValidateStruct(creds, path)
+}
+
+// decodeCredentials decodes raw into the credentials struct of the chosen provider.
+// Keys are matched against the yaml tags, so keys written by the wizard can be read back.
+func decodeCredentials(raw interface{}, creds interface{}) (interface{}, error) {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName: "yaml",
+		Result:  &creds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = decoder.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
+
+func newDNSCredentials(provider string) interface{} {
+	switch provider {
+	case common.DNS_PROVIDER_AZURE_DNS, common.DNS_PROVIDER_AZURE_PRIVATE_DNS:
+		return dnsCredentialsAzure{}
+	case common.DNS_PROVIDER_OPENSTACK_DESIGNATE:
+		return dnsCredentialsOSDesignate{}
+	case common.DNS_PROVIDER_AWS_ROUTE_53:
+		return dnsCredentialsAWS53{}
+	case common.DNS_PROVIDER_GOOGLE_CLOUDDNS:
+		return dnsCredentialsGCP{}
+	case common.DNS_PROVIDER_ALICLOUD_DNS:
+		return dnsCredentialsAlicloud{}
+	}
+
+	return nil
+}
+
+func newBackupCredentials(provider string) interface{} {
+	switch provider {
+	case common.BUCKET_PROVIDER_AZURE:
+		return backupCredentialsAzure{}
+	case common.BUCKET_PROVIDER_AWS:
+		return backupCredentialsAWS{}
+	case common.BUCKET_PROVIDER_GCP:
+		return backupCredentialsGCP{}
+	case common.BUCKET_PROVIDER_OPENSTACK:
+		return backupCredentialsOpenStack{}
+	case common.BUCKET_PROVIDER_S3:
+		return backupCredentialsS3{}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package install
+
+import (
+	"os"
+	"path"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("ValidateConfig", func() {
+	BeforeEach(func() {
+		tmpFolder, err := os.MkdirTemp("", "23kectl-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, tmpFolder)
+
+		// the credentials are base64 encoded, the way the install wizard stores them
+		configFile := path.Join(tmpFolder, "config.yaml")
+		err = os.WriteFile(configFile, []byte(`
+version: v4.0.0
+admin:
+  email: test@example.org
+  gitrepourl: ssh://git@github.com/my-org/my-config.git
+  gitrepobranch: main
+  password: $2a$10$eWNJshWJxf24FVm4u7W1XOYiPzdSscmFgs3GVF.PYaC42DjuX1piu
+baseCluster:
+  provider: openstack
+  region: RegionOne
+  nodeCidr: 10.250.0.0/16
+  hasVerticalPodAutoscaler: false
+bucket:
+  endpoint: localhost:9000
+  accesskey: minioadmin
+  secretkey: minioadmin
+cloudprofiles:
+  - regiocloud
+clusterIdentity: garden-cluster-my-identity
+domainConfig:
+  domain: my-domain.example.org
+  provider: openstack-designate
+  credentials:
+    OS_APPLICATION_CREDENTIAL_ID: bXktaWQ=
+    OS_APPLICATION_CREDENTIAL_SECRET: bXktc2VjcmV0
+    OS_AUTH_URL: aHR0cHM6Ly9rZXlzdG9uZS5leGFtcGxlLm9yZzo1MDAwL3Yz
+backupConfig:
+  enabled: false
+emailAddress: test@example.org
+gardener:
+  clusterIP: 10.0.0.100
+gardenlet:
+  seedNodeCidr: 10.250.0.0/16
+  seedPodCidr: 100.73.0.0/16
+  seedServiceCidr: 10.0.0.0/24
+issuer:
+  acme:
+    email: test@example.org
+    server: https://acme-v02.api.letsencrypt.org/directory
+  ca: my-great-ca
+dashboard:
+  clientSecret: my-client-secret
+  sessionSecret: my-session-secret
+kubeApiServer:
+  basicAuthPassword: my-basic-auth-password
+`), 0600)
+		Expect(err).NotTo(HaveOccurred())
+
+		viper.Reset()
+		viper.SetConfigFile(configFile)
+		Expect(viper.ReadInConfig()).To(Succeed())
+	})
+
+	DescribeTable("should accept the config written by the install wizard",
+		func(installPkgVersion string) {
+			violations, err := ValidateConfig(installPkgVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+		},
+		Entry("v1", "v1"),
+		Entry("v2", "v2"),
+		Entry("v3", "v3"),
+		Entry("v4", "v4"),
+	)
+
+	DescribeTable("should report an invalid seed pod CIDR",
+		func(installPkgVersion string) {
+			viper.Set("gardenlet.seedPodCidr", "100.73.0.0")
+
+			violations, err := ValidateConfig(installPkgVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(ConsistOf(common.ValidationError{
+				Path:  "gardenlet.seedPodCidr",
+				Tag:   "cidr",
+				Value: "100.73.0.0",
+			}))
+		},
+		Entry("v1", "v1"),
+		Entry("v2", "v2"),
+		Entry("v3", "v3"),
+		Entry("v4", "v4"),
+	)
+
+	DescribeTable("should report an unknown DNS provider without checking its credentials",
+		func(installPkgVersion string) {
+			viper.Set("domainConfig.provider", "cloudflare-dns")
+
+			violations, err := ValidateConfig(installPkgVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(ConsistOf(And(
+				HaveField("Path", "domainConfig.provider"),
+				HaveField("Tag", HavePrefix("oneof=")),
+				HaveField("Value", "cloudflare-dns"),
+			)))
+		},
+		Entry("v1", "v1"),
+		Entry("v2", "v2"),
+		Entry("v3", "v3"),
+		Entry("v4", "v4"),
+	)
+
+	DescribeTable("should report an auth URL which isn't a base64 encoded URL",
+		func(installPkgVersion string, authURL string) {
+			viper.Set("domainConfig.credentials.OS_AUTH_URL", authURL)
+
+			violations, err := ValidateConfig(installPkgVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(ConsistOf(common.ValidationError{
+				Path:  "domainConfig.credentials.OS_AUTH_URL",
+				Tag:   "encodedurl",
+				Value: authURL,
+			}))
+		},
+		Entry("plain URL in v1", "v1", "https://keystone.example.org:5000/v3"),
+		Entry("plain URL in v4", "v4", "https://keystone.example.org:5000/v3"),
+		// keystone.example.org
+		Entry("encoded host name in v4", "v4", "a2V5c3RvbmUuZXhhbXBsZS5vcmc="),
+	)
+
+	It("should report missing credentials", func() {
+		viper.Set("domainConfig.credentials.OS_AUTH_URL", "")
+
+		violations, err := ValidateConfig("v4")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(ConsistOf(common.ValidationError{
+			Path:  "domainConfig.credentials.OS_AUTH_URL",
+			Tag:   "required",
+			Value: "",
+		}))
+	})
+})
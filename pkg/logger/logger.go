@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"github.com/23technologies/23kectl/pkg/redact"
 	"github.com/bombsimon/logrusr/v4"
 	"github.com/go-logr/logr"
 	"github.com/sirupsen/logrus"
@@ -18,7 +19,7 @@ func Init() func() {
 	logrusLog.SetOutput(io.Discard)
 
 	logrusLog.AddHook(&writer.Hook{
-		Writer: os.Stderr,
+		Writer: redact.Writer(os.Stderr),
 		LogLevels: []logrus.Level{
 			logrus.ErrorLevel,
 		},
@@ -28,7 +29,7 @@ func Init() func() {
 	_, _ = file.WriteString("============================================================\n")
 
 	logrusLog.AddHook(&writer.Hook{
-		Writer: file,
+		Writer: redact.Writer(file),
 		LogLevels: []logrus.Level{
 			logrus.ErrorLevel,
 			logrus.InfoLevel,
@@ -0,0 +1,97 @@
+package netdetect
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// FirstIPv4Cidr returns the first IPv4 CIDR in a comma or space separated list,
+// e.g. the dual-stack "10.244.0.0/16,fd00:10:244::/56", as network address.
+func FirstIPv4Cidr(value string) (string, bool) {
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(field))
+		if err != nil || network.IP.To4() == nil {
+			continue
+		}
+
+		return network.String(), true
+	}
+
+	return "", false
+}
+
+// matches "The range of valid IPs is 10.96.0.0/12", possibly followed by more
+// ranges in dual-stack clusters
+var validRangeRegex = regexp.MustCompile(`(?i)range of valid IPs is\s+([0-9a-f.:/,\s]+)`)
+
+// ParseServiceCidrFromError extracts the service CIDR from the error the API
+// server returns for a Service with a ClusterIP outside of it.
+func ParseServiceCidrFromError(message string) (string, error) {
+	match := validRangeRegex.FindStringSubmatch(message)
+	if match == nil {
+		return "", fmt.Errorf("the error doesn't name the range of valid IPs: %s", message)
+	}
+
+	cidr, ok := FirstIPv4Cidr(match[1])
+	if !ok {
+		return "", fmt.Errorf("the range of valid IPs '%s' has no IPv4 CIDR", strings.TrimSpace(match[1]))
+	}
+
+	return cidr, nil
+}
+
+// AggregateCidrs returns the smallest IPv4 CIDR containing all given ones,
+// e.g. the pod CIDR of the cluster for the pod CIDRs of its nodes.
+func AggregateCidrs(cidrs []string) (string, error) {
+	var first, last uint32
+	prefixLength := 32
+
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return "", err
+		}
+		ip := network.IP.To4()
+		if ip == nil {
+			return "", fmt.Errorf("%s is not an IPv4 CIDR", cidr)
+		}
+
+		ones, _ := network.Mask.Size()
+		start := binary.BigEndian.Uint32(ip)
+		end := start | ^binary.BigEndian.Uint32(net.IP(network.Mask).To4())
+
+		if i == 0 || start < first {
+			first = start
+		}
+		if i == 0 || end > last {
+			last = end
+		}
+		if ones < prefixLength {
+			prefixLength = ones
+		}
+	}
+
+	if len(cidrs) == 0 {
+		return "", errors.New("there are no CIDRs to aggregate")
+	}
+
+	// shorten the prefix until first and last share it
+	for prefixLength > 0 && first>>(32-prefixLength) != last>>(32-prefixLength) {
+		prefixLength--
+	}
+
+	network := net.IPNet{
+		IP:   make(net.IP, 4),
+		Mask: net.CIDRMask(prefixLength, 32),
+	}
+	binary.BigEndian.PutUint32(network.IP, first)
+	network.IP = network.IP.Mask(network.Mask)
+
+	return network.String(), nil
+}
@@ -0,0 +1,109 @@
+package netdetect_test
+
+import (
+	"github.com/23technologies/23kectl/pkg/netdetect"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseServiceCidrFromError", func() {
+	DescribeTable("extracts the service CIDR",
+		func(message string, expected string) {
+			cidr, err := netdetect.ParseServiceCidrFromError(message)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cidr).To(Equal(expected))
+		},
+		Entry("kubernetes 1.20+",
+			`Service "dummy" is invalid: spec.clusterIPs: Invalid value: []string{"1.1.1.1"}: failed to allocate IP 1.1.1.1: provided IP is not in the valid range. The range of valid IPs is 10.96.0.0/12`,
+			"10.96.0.0/12"),
+		Entry("older kubernetes",
+			`Service "dummy" is invalid: spec.clusterIP: Invalid value: "1.1.1.1": provided IP is not in the valid range. The range of valid IPs is 100.64.0.0/13`,
+			"100.64.0.0/13"),
+		Entry("dual-stack, IPv6 first",
+			`failed to allocate IP 1.1.1.1: the provided IP (1.1.1.1) is not in the valid range. The range of valid IPs is fd00:10:96::/112, 10.0.0.0/16`,
+			"10.0.0.0/16"),
+		Entry("a host address",
+			`The range of valid IPs is 10.96.0.1/12`,
+			"10.96.0.0/12"),
+	)
+
+	It("fails instead of panicking if the format changes", func() {
+		_, err := netdetect.ParseServiceCidrFromError(`Service "dummy" is invalid: spec.clusterIPs: Invalid value: []string{"1.1.1.1"}: not in any configured range`)
+		Expect(err).To(HaveOccurred())
+
+		_, err = netdetect.ParseServiceCidrFromError(`The range of valid IPs is fd00:10:96::/112`)
+		Expect(err).To(MatchError(ContainSubstring("no IPv4 CIDR")))
+	})
+})
+
+var _ = Describe("FirstIPv4Cidr", func() {
+	It("skips IPv6 CIDRs and garbage", func() {
+		cidr, ok := netdetect.FirstIPv4Cidr("fd00:10:244::/56, nonsense,10.244.0.0/16")
+		Expect(ok).To(BeTrue())
+		Expect(cidr).To(Equal("10.244.0.0/16"))
+
+		_, ok = netdetect.FirstIPv4Cidr("")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("AggregateCidrs", func() {
+	DescribeTable("returns the smallest CIDR containing all of them",
+		func(cidrs []string, expected string) {
+			cidr, err := netdetect.AggregateCidrs(cidrs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cidr).To(Equal(expected))
+		},
+		Entry("a single one", []string{"10.244.1.0/24"}, "10.244.1.0/24"),
+		Entry("adjacent ones", []string{"10.244.0.0/24", "10.244.1.0/24"}, "10.244.0.0/23"),
+		Entry("scattered ones", []string{"10.244.0.0/24", "10.244.3.0/24", "10.244.130.0/24"}, "10.244.0.0/16"),
+		Entry("nested ones", []string{"10.0.0.0/8", "10.244.3.0/24"}, "10.0.0.0/8"),
+		Entry("nothing in common", []string{"10.0.0.0/24", "192.168.0.0/24"}, "0.0.0.0/0"),
+	)
+
+	It("fails for no or invalid CIDRs", func() {
+		_, err := netdetect.AggregateCidrs(nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = netdetect.AggregateCidrs([]string{"fd00::/64"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Parsing configurations", func() {
+	It("reads flannel's net-conf.json", func() {
+		cidr, err := netdetect.ParseFlannelNetConf(`{
+  "Network": "10.244.0.0/16",
+  "Backend": {"Type": "vxlan"}
+}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cidr).To(Equal("10.244.0.0/16"))
+
+		_, err = netdetect.ParseFlannelNetConf(`{`)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads kubeadm's ClusterConfiguration", func() {
+		podSubnet, serviceSubnet, err := netdetect.ParseKubeadmClusterConfiguration(`apiVersion: kubeadm.k8s.io/v1beta3
+kind: ClusterConfiguration
+kubernetesVersion: v1.25.3
+networking:
+  dnsDomain: cluster.local
+  podSubnet: 192.168.0.0/16
+  serviceSubnet: 10.96.0.0/12
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podSubnet).To(Equal("192.168.0.0/16"))
+		Expect(serviceSubnet).To(Equal("10.96.0.0/12"))
+	})
+
+	It("reads kube-proxy's clusterCIDR", func() {
+		cidr, err := netdetect.ParseKubeProxyConfiguration(`apiVersion: kubeproxy.config.k8s.io/v1alpha1
+kind: KubeProxyConfiguration
+clusterCIDR: 10.244.0.0/16,fd00:10:244::/56
+mode: ipvs
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cidr).To(Equal("10.244.0.0/16,fd00:10:244::/56"))
+	})
+})
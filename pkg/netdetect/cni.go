@@ -0,0 +1,139 @@
+package netdetect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CalicoDetector reads the pod CIDR from calico's IPPools, preferring the
+// default-ipv4-ippool created by the operator and the manifests.
+type CalicoDetector struct{}
+
+func (d *CalicoDetector) Name() string {
+	return "calico"
+}
+
+func (d *CalicoDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	list := unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "crd.projectcalico.org",
+		Version: "v1",
+		Kind:    "IPPoolList",
+	})
+	err := kubeClient.List(ctx, &list)
+	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+		return Networks{}, nil
+	}
+	if err != nil {
+		return Networks{}, err
+	}
+
+	var pool *Finding
+	for _, item := range list.Items {
+		cidr, _, _ := unstructured.NestedString(item.Object, "spec", "cidr")
+		disabled, _, _ := unstructured.NestedBool(item.Object, "spec", "disabled")
+		if disabled {
+			continue
+		}
+
+		found := finding(cidr, fmt.Sprintf("calico IPPool %s", item.GetName()))
+		if found == nil {
+			continue
+		}
+		if item.GetName() == "default-ipv4-ippool" {
+			return Networks{PodCidr: found}, nil
+		}
+		if pool == nil {
+			pool = found
+		}
+	}
+
+	return Networks{PodCidr: pool}, nil
+}
+
+// CiliumDetector reads the pod CIDR from the cilium-config ConfigMap. In
+// kubernetes IPAM mode cilium uses the pod CIDRs allocated to the nodes.
+type CiliumDetector struct{}
+
+func (d *CiliumDetector) Name() string {
+	return "cilium"
+}
+
+func (d *CiliumDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	config := corev1.ConfigMap{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: "kube-system", Name: "cilium-config"}, &config)
+	if apierrors.IsNotFound(err) {
+		return Networks{}, nil
+	}
+	if err != nil {
+		return Networks{}, err
+	}
+
+	switch config.Data["ipam"] {
+	case "kubernetes":
+		cidr, nodes, err := nodePodCidr(ctx, kubeClient)
+		if err != nil || cidr == "" {
+			return Networks{}, err
+		}
+		return Networks{PodCidr: &Finding{
+			Cidr:   cidr,
+			Source: fmt.Sprintf("cilium in kubernetes IPAM mode, spec.podCIDRs of %d node(s)", nodes),
+		}}, nil
+	case "", "cluster-pool":
+		return Networks{PodCidr: finding(config.Data["cluster-pool-ipv4-cidr"], "cilium-config cluster-pool-ipv4-cidr")}, nil
+	default:
+		// e.g. eni or azure, pods get addresses of the node network
+		return Networks{}, nil
+	}
+}
+
+// FlannelDetector reads the pod CIDR from flannel's net-conf.json.
+type FlannelDetector struct{}
+
+func (d *FlannelDetector) Name() string {
+	return "flannel"
+}
+
+func (d *FlannelDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	// older manifests install flannel into kube-system
+	for _, namespace := range []string{"kube-flannel", "kube-system"} {
+		config := corev1.ConfigMap{}
+		err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "kube-flannel-cfg"}, &config)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return Networks{}, err
+		}
+
+		cidr, err := ParseFlannelNetConf(config.Data["net-conf.json"])
+		if err != nil {
+			return Networks{}, err
+		}
+
+		return Networks{PodCidr: finding(cidr, fmt.Sprintf("flannel ConfigMap %s/kube-flannel-cfg", namespace))}, nil
+	}
+
+	return Networks{}, nil
+}
+
+// ParseFlannelNetConf returns the IPv4 network of flannel's net-conf.json.
+func ParseFlannelNetConf(netConf string) (string, error) {
+	conf := struct {
+		Network string `json:"Network"`
+	}{}
+	err := json.Unmarshal([]byte(netConf), &conf)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse flannel's net-conf.json: %w", err)
+	}
+
+	return conf.Network, nil
+}
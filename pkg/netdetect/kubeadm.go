@@ -0,0 +1,103 @@
+package netdetect
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KubeadmDetector reads the pod and service CIDR from the ClusterConfiguration
+// kubeadm stores in the kubeadm-config ConfigMap.
+type KubeadmDetector struct{}
+
+func (d *KubeadmDetector) Name() string {
+	return "kubeadm"
+}
+
+func (d *KubeadmDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	config := corev1.ConfigMap{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: "kube-system", Name: "kubeadm-config"}, &config)
+	if apierrors.IsNotFound(err) {
+		return Networks{}, nil
+	}
+	if err != nil {
+		return Networks{}, err
+	}
+
+	podSubnet, serviceSubnet, err := ParseKubeadmClusterConfiguration(config.Data["ClusterConfiguration"])
+	if err != nil {
+		return Networks{}, err
+	}
+
+	return Networks{
+		PodCidr:     finding(podSubnet, "kubeadm ClusterConfiguration networking.podSubnet"),
+		ServiceCidr: finding(serviceSubnet, "kubeadm ClusterConfiguration networking.serviceSubnet"),
+	}, nil
+}
+
+// ParseKubeadmClusterConfiguration returns networking.podSubnet and networking.serviceSubnet.
+func ParseKubeadmClusterConfiguration(clusterConfiguration string) (string, string, error) {
+	conf := struct {
+		Networking struct {
+			PodSubnet     string `yaml:"podSubnet"`
+			ServiceSubnet string `yaml:"serviceSubnet"`
+		} `yaml:"networking"`
+	}{}
+	err := yaml.Unmarshal([]byte(clusterConfiguration), &conf)
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't parse kubeadm's ClusterConfiguration: %w", err)
+	}
+
+	return conf.Networking.PodSubnet, conf.Networking.ServiceSubnet, nil
+}
+
+// KubeProxyDetector reads the pod CIDR from the configuration of kube-proxy,
+// which kubeadm and several distributions store in the kube-proxy ConfigMap.
+type KubeProxyDetector struct{}
+
+func (d *KubeProxyDetector) Name() string {
+	return "kube-proxy"
+}
+
+func (d *KubeProxyDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	config := corev1.ConfigMap{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: "kube-system", Name: "kube-proxy"}, &config)
+	if apierrors.IsNotFound(err) {
+		return Networks{}, nil
+	}
+	if err != nil {
+		return Networks{}, err
+	}
+
+	for _, key := range []string{"config.conf", "config"} {
+		if config.Data[key] == "" {
+			continue
+		}
+
+		clusterCidr, err := ParseKubeProxyConfiguration(config.Data[key])
+		if err != nil {
+			return Networks{}, err
+		}
+
+		return Networks{PodCidr: finding(clusterCidr, "kube-proxy configuration clusterCIDR")}, nil
+	}
+
+	return Networks{}, nil
+}
+
+// ParseKubeProxyConfiguration returns the clusterCIDR of a KubeProxyConfiguration.
+func ParseKubeProxyConfiguration(kubeProxyConfiguration string) (string, error) {
+	conf := struct {
+		ClusterCidr string `yaml:"clusterCIDR"`
+	}{}
+	err := yaml.Unmarshal([]byte(kubeProxyConfiguration), &conf)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse kube-proxy's configuration: %w", err)
+	}
+
+	return conf.ClusterCidr, nil
+}
@@ -0,0 +1,115 @@
+package netdetect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// managed Kubernetes platforms, identified by their nodes
+const (
+	PLATFORM_AKS = "AKS"
+	PLATFORM_EKS = "EKS"
+	PLATFORM_GKE = "GKE"
+)
+
+// Platform returns the managed Kubernetes platform the node belongs to, an
+// empty string if it's none of them.
+func Platform(node *corev1.Node) string {
+	providerID := node.Spec.ProviderID
+
+	switch {
+	case strings.HasPrefix(providerID, "azure://") && hasLabelPrefix(node, "kubernetes.azure.com/"):
+		return PLATFORM_AKS
+	case strings.HasPrefix(providerID, "aws://") && hasLabelPrefix(node, "eks.amazonaws.com/"):
+		return PLATFORM_EKS
+	case strings.HasPrefix(providerID, "gce://") && hasLabelPrefix(node, "cloud.google.com/gke-"):
+		return PLATFORM_GKE
+	default:
+		return ""
+	}
+}
+
+func hasLabelPrefix(node *corev1.Node, prefix string) bool {
+	for label := range node.Labels {
+		if strings.HasPrefix(label, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ManagedClusterDetector derives the pod CIDR from the pod CIDRs allocated to
+// the nodes, as on GKE, AKS with kubenet or overlay networking and any cluster
+// whose controller-manager allocates node CIDRs. On EKS and AKS with Azure CNI
+// pods get addresses of the node network, which isn't visible in the cluster.
+type ManagedClusterDetector struct{}
+
+func (d *ManagedClusterDetector) Name() string {
+	return "managed-cluster"
+}
+
+func (d *ManagedClusterDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	list := corev1.NodeList{}
+	err := kubeClient.List(ctx, &list)
+	if err != nil {
+		return Networks{}, err
+	}
+
+	cidr, nodes, err := aggregateNodePodCidrs(list.Items)
+	if err != nil || cidr == "" {
+		return Networks{}, err
+	}
+
+	source := fmt.Sprintf("spec.podCIDRs of %d node(s)", nodes)
+	if platform := Platform(&list.Items[0]); platform != "" {
+		source = fmt.Sprintf("spec.podCIDRs of %d %s node(s)", nodes, platform)
+	}
+
+	return Networks{PodCidr: &Finding{Cidr: cidr, Source: source}}, nil
+}
+
+// nodePodCidr returns the smallest CIDR containing the pod CIDRs of all nodes
+// and the number of nodes with pod CIDRs.
+func nodePodCidr(ctx context.Context, kubeClient client.Client) (string, int, error) {
+	list := corev1.NodeList{}
+	err := kubeClient.List(ctx, &list)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return aggregateNodePodCidrs(list.Items)
+}
+
+func aggregateNodePodCidrs(nodes []corev1.Node) (string, int, error) {
+	var cidrs []string
+	count := 0
+	for _, node := range nodes {
+		podCidrs := node.Spec.PodCIDRs
+		if len(podCidrs) == 0 && node.Spec.PodCIDR != "" {
+			podCidrs = []string{node.Spec.PodCIDR}
+		}
+
+		found := false
+		for _, podCidr := range podCidrs {
+			if cidr, ok := FirstIPv4Cidr(podCidr); ok {
+				cidrs = append(cidrs, cidr)
+				found = true
+			}
+		}
+		if found {
+			count++
+		}
+	}
+
+	if len(cidrs) == 0 {
+		return "", 0, nil
+	}
+
+	cidr, err := AggregateCidrs(cidrs)
+	return cidr, count, err
+}
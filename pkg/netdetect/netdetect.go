@@ -0,0 +1,79 @@
+// Package netdetect detects the pod and service CIDR of a base cluster from
+// the resources its CNI and its distribution leave behind. The results are
+// guesses with a source attached, meant to be confirmed by the user.
+package netdetect
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Finding is a detected CIDR and where it was found.
+type Finding struct {
+	Cidr   string
+	Source string
+}
+
+// Networks holds the CIDRs a detector found, nil if it found none.
+type Networks struct {
+	PodCidr     *Finding
+	ServiceCidr *Finding
+}
+
+// NetworkDetector detects the networks of a cluster. It returns empty
+// Networks if it doesn't apply to the cluster, e.g. because another CNI is used.
+type NetworkDetector interface {
+	Name() string
+	Detect(ctx context.Context, kubeClient client.Client) (Networks, error)
+}
+
+// Detectors returns all detectors, the most reliable ones first.
+func Detectors() []NetworkDetector {
+	return []NetworkDetector{
+		&CalicoDetector{},
+		&CiliumDetector{},
+		&FlannelDetector{},
+		&ServiceCidrDetector{},
+		&KubeadmDetector{},
+		&KubeProxyDetector{},
+		&ManagedClusterDetector{},
+		&ServiceProbeDetector{},
+	}
+}
+
+// Detect runs the given detectors in order, the first finding of every network
+// wins. Detectors which fail, e.g. for lack of permissions, are skipped.
+func Detect(ctx context.Context, kubeClient client.Client, detectors []NetworkDetector) Networks {
+	result := Networks{}
+
+	for _, detector := range detectors {
+		if result.PodCidr != nil && result.ServiceCidr != nil {
+			break
+		}
+
+		networks, err := detector.Detect(ctx, kubeClient)
+		if err != nil {
+			continue
+		}
+
+		if result.PodCidr == nil {
+			result.PodCidr = networks.PodCidr
+		}
+		if result.ServiceCidr == nil {
+			result.ServiceCidr = networks.ServiceCidr
+		}
+	}
+
+	return result
+}
+
+// finding returns a Finding for the first IPv4 CIDR in value, nil if there is none.
+func finding(value string, source string) *Finding {
+	cidr, ok := FirstIPv4Cidr(value)
+	if !ok {
+		return nil
+	}
+
+	return &Finding{Cidr: cidr, Source: source}
+}
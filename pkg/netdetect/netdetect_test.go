@@ -0,0 +1,211 @@
+package netdetect_test
+
+import (
+	"context"
+
+	fluxutils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/netdetect"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(fluxutils.NewScheme()).
+		WithObjects(objects...).
+		Build()
+}
+
+func configMap(namespace string, name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       data,
+	}
+}
+
+func ipPool(name string, cidr string, disabled bool) *unstructured.Unstructured {
+	pool := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"cidr":     cidr,
+			"disabled": disabled,
+		},
+	}}
+	pool.SetGroupVersionKind(schema.GroupVersionKind{Group: "crd.projectcalico.org", Version: "v1", Kind: "IPPool"})
+	pool.SetName(name)
+
+	return pool
+}
+
+func nodeWithPodCidrs(name string, providerID string, labels map[string]string, podCidrs ...string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{ProviderID: providerID, PodCIDRs: podCidrs},
+	}
+}
+
+// probeClient rejects every Service like an API server with the given service CIDR.
+type probeClient struct {
+	client.Client
+	message string
+}
+
+func (c *probeClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*corev1.Service); ok {
+		return apierrors.NewInvalid(schema.GroupKind{Kind: "Service"}, obj.GetName(), field.ErrorList{
+			field.Invalid(field.NewPath("spec", "clusterIPs"), []string{netdetect.PROBE_CLUSTER_IP}, c.message),
+		})
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("CalicoDetector", func() {
+	It("prefers the default IPPool", func() {
+		kubeClient := newTestClient(ipPool("a-pool", "10.10.0.0/16", false), ipPool("default-ipv4-ippool", "192.168.0.0/16", false))
+
+		networks, err := (&netdetect.CalicoDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr).To(Equal(&netdetect.Finding{Cidr: "192.168.0.0/16", Source: "calico IPPool default-ipv4-ippool"}))
+		Expect(networks.ServiceCidr).To(BeNil())
+	})
+
+	It("skips disabled and IPv6 pools", func() {
+		kubeClient := newTestClient(ipPool("old", "10.10.0.0/16", true), ipPool("v6", "fd00::/64", false), ipPool("new", "10.20.0.0/16", false))
+
+		networks, err := (&netdetect.CalicoDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr.Cidr).To(Equal("10.20.0.0/16"))
+	})
+})
+
+var _ = Describe("CiliumDetector", func() {
+	It("reads the cluster pool", func() {
+		kubeClient := newTestClient(configMap("kube-system", "cilium-config", map[string]string{
+			"ipam":                   "cluster-pool",
+			"cluster-pool-ipv4-cidr": "10.0.0.0/8",
+		}))
+
+		networks, err := (&netdetect.CiliumDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr.Cidr).To(Equal("10.0.0.0/8"))
+	})
+
+	It("aggregates the pod CIDRs of the nodes in kubernetes IPAM mode", func() {
+		kubeClient := newTestClient(
+			configMap("kube-system", "cilium-config", map[string]string{"ipam": "kubernetes"}),
+			nodeWithPodCidrs("a", "", nil, "10.244.0.0/24"),
+			nodeWithPodCidrs("b", "", nil, "10.244.1.0/24", "fd00:10:244:1::/64"),
+		)
+
+		networks, err := (&netdetect.CiliumDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr.Cidr).To(Equal("10.244.0.0/23"))
+		Expect(networks.PodCidr.Source).To(ContainSubstring("kubernetes IPAM mode"))
+	})
+
+	It("doesn't apply without cilium", func() {
+		networks, err := (&netdetect.CiliumDetector{}).Detect(context.Background(), newTestClient())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr).To(BeNil())
+	})
+})
+
+var _ = Describe("FlannelDetector", func() {
+	It("reads the network from kube-system, too", func() {
+		kubeClient := newTestClient(configMap("kube-system", "kube-flannel-cfg", map[string]string{
+			"net-conf.json": `{"Network": "10.42.0.0/16", "Backend": {"Type": "vxlan"}}`,
+		}))
+
+		networks, err := (&netdetect.FlannelDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr).To(Equal(&netdetect.Finding{Cidr: "10.42.0.0/16", Source: "flannel ConfigMap kube-system/kube-flannel-cfg"}))
+	})
+})
+
+var _ = Describe("ManagedClusterDetector", func() {
+	It("names the platform of the nodes", func() {
+		labels := map[string]string{"cloud.google.com/gke-nodepool": "default-pool"}
+		kubeClient := newTestClient(
+			nodeWithPodCidrs("a", "gce://project/europe-west3-a/a", labels, "10.8.0.0/24"),
+			nodeWithPodCidrs("b", "gce://project/europe-west3-a/b", labels, "10.8.2.0/24"),
+		)
+
+		networks, err := (&netdetect.ManagedClusterDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr).To(Equal(&netdetect.Finding{Cidr: "10.8.0.0/22", Source: "spec.podCIDRs of 2 GKE node(s)"}))
+	})
+
+	It("doesn't apply if the nodes have no pod CIDRs", func() {
+		kubeClient := newTestClient(nodeWithPodCidrs("a", "aws:///eu-central-1a/i-0123", map[string]string{"eks.amazonaws.com/nodegroup": "ng"}))
+
+		networks, err := (&netdetect.ManagedClusterDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.PodCidr).To(BeNil())
+	})
+})
+
+var _ = Describe("ServiceProbeDetector", func() {
+	kubernetesSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kubernetes"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.1"},
+	}
+
+	It("reads the range of valid IPs from the error", func() {
+		kubeClient := &probeClient{
+			Client:  newTestClient(kubernetesSvc),
+			message: "failed to allocate IP 1.1.1.1: provided IP is not in the valid range. The range of valid IPs is 10.96.0.0/12",
+		}
+
+		networks, err := (&netdetect.ServiceProbeDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(networks.ServiceCidr.Cidr).To(Equal("10.96.0.0/12"))
+	})
+
+	It("rejects a range which doesn't contain the kubernetes Service", func() {
+		kubeClient := &probeClient{
+			Client:  newTestClient(kubernetesSvc),
+			message: "The range of valid IPs is 100.64.0.0/13",
+		}
+
+		_, err := (&netdetect.ServiceProbeDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).To(MatchError(ContainSubstring("doesn't contain the ClusterIP 10.96.0.1")))
+	})
+
+	It("fails for unknown error messages", func() {
+		kubeClient := &probeClient{Client: newTestClient(), message: "something else"}
+
+		_, err := (&netdetect.ServiceProbeDetector{}).Detect(context.Background(), kubeClient)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Detect", func() {
+	It("takes the first finding of every network", func() {
+		kubeClient := newTestClient(
+			configMap("kube-system", "cilium-config", map[string]string{"cluster-pool-ipv4-cidr": "10.0.0.0/8"}),
+			configMap("kube-system", "kubeadm-config", map[string]string{"ClusterConfiguration": `
+networking:
+  podSubnet: 192.168.0.0/16
+  serviceSubnet: 10.96.0.0/12
+`}),
+		)
+
+		networks := netdetect.Detect(context.Background(), kubeClient, netdetect.Detectors())
+		Expect(networks.PodCidr.Cidr).To(Equal("10.0.0.0/8"))
+		Expect(networks.ServiceCidr).To(Equal(&netdetect.Finding{Cidr: "10.96.0.0/12", Source: "kubeadm ClusterConfiguration networking.serviceSubnet"}))
+	})
+
+	It("detects nothing in an empty cluster", func() {
+		networks := netdetect.Detect(context.Background(), newTestClient(), netdetect.Detectors())
+		Expect(networks.PodCidr).To(BeNil())
+		Expect(networks.ServiceCidr).To(BeNil())
+	})
+})
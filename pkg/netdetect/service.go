@@ -0,0 +1,96 @@
+package netdetect
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ServiceCidrDetector reads the service CIDR from the default ServiceCIDR
+// object, which newer Kubernetes versions create for --service-cluster-ip-range.
+type ServiceCidrDetector struct{}
+
+func (d *ServiceCidrDetector) Name() string {
+	return "servicecidr"
+}
+
+func (d *ServiceCidrDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	for _, version := range []string{"v1", "v1beta1"} {
+		serviceCidr := unstructured.Unstructured{}
+		serviceCidr.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   "networking.k8s.io",
+			Version: version,
+			Kind:    "ServiceCIDR",
+		})
+		err := kubeClient.Get(ctx, client.ObjectKey{Name: "kubernetes"}, &serviceCidr)
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return Networks{}, err
+		}
+
+		cidrs, _, _ := unstructured.NestedStringSlice(serviceCidr.Object, "spec", "cidrs")
+		for _, cidr := range cidrs {
+			if found := finding(cidr, "ServiceCIDR kubernetes"); found != nil {
+				return Networks{ServiceCidr: found}, nil
+			}
+		}
+	}
+
+	return Networks{}, nil
+}
+
+// PROBE_CLUSTER_IP lies outside of any sane service CIDR.
+const PROBE_CLUSTER_IP = "1.1.1.1"
+
+// ServiceProbeDetector asks the API server to create a Service with a
+// ClusterIP outside of the service CIDR in dry-run mode. The error names the
+// range of valid IPs. Nothing is created, even if the ClusterIP is valid.
+type ServiceProbeDetector struct{}
+
+func (d *ServiceProbeDetector) Name() string {
+	return "service-probe"
+}
+
+func (d *ServiceProbeDetector) Detect(ctx context.Context, kubeClient client.Client) (Networks, error) {
+	probe := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "23kectl-service-cidr-probe",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports:     []corev1.ServicePort{{Name: "port", Port: 443}},
+			ClusterIP: PROBE_CLUSTER_IP,
+		},
+	}
+	err := kubeClient.Create(ctx, &probe, client.DryRunAll)
+	if err == nil {
+		return Networks{}, fmt.Errorf("the API server accepted ClusterIP %s", PROBE_CLUSTER_IP)
+	}
+
+	cidr, parseErr := ParseServiceCidrFromError(err.Error())
+	if parseErr != nil {
+		return Networks{}, parseErr
+	}
+
+	// the ClusterIP of the API server's Service is the first one of the range
+	kubernetesSvc := corev1.Service{}
+	err = kubeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "kubernetes"}, &kubernetesSvc)
+	if err == nil {
+		_, network, _ := net.ParseCIDR(cidr)
+		if ip := net.ParseIP(kubernetesSvc.Spec.ClusterIP); ip != nil && ip.To4() != nil && !network.Contains(ip) {
+			return Networks{}, fmt.Errorf("the probed service CIDR %s doesn't contain the ClusterIP %s of the kubernetes Service", cidr, ip)
+		}
+	}
+
+	return Networks{ServiceCidr: &Finding{Cidr: cidr, Source: "the range of valid ClusterIPs reported by the API server"}}, nil
+}
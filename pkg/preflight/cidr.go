@@ -0,0 +1,94 @@
+package preflight
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// the shootDefaults of the initial seed, hard-coded in gardenlet-values.yaml
+const (
+	SHOOT_DEFAULT_POD_CIDR     = "100.100.0.0/16"
+	SHOOT_DEFAULT_SERVICE_CIDR = "100.101.0.0/16"
+)
+
+// SeedCidrCheck fails if the node, pod or service CIDR of the seed overlap
+// with each other or with the shootDefaults. The VPN between a shoot's control
+// plane in the seed and its nodes can't route overlapping networks.
+type SeedCidrCheck struct{}
+
+func (c *SeedCidrCheck) Name() string {
+	return "seed-cidrs"
+}
+
+func (c *SeedCidrCheck) ConfigKeys() []string {
+	return []string{"gardenlet.seedNodeCidr", "gardenlet.seedPodCidr", "gardenlet.seedServiceCidr"}
+}
+
+type namedCidr struct {
+	name    string
+	network *net.IPNet
+}
+
+func (c *SeedCidrCheck) Run(env *Env) *Result {
+	var seedCidrs []namedCidr
+	for _, cidr := range []struct{ name, value string }{
+		{"gardenlet.seedNodeCidr", env.Config.SeedNodeCidr},
+		{"gardenlet.seedPodCidr", env.Config.SeedPodCidr},
+		{"gardenlet.seedServiceCidr", env.Config.SeedServiceCidr},
+	} {
+		if cidr.value == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr.value)
+		if err != nil {
+			return fail(c.Name(), fmt.Sprintf("%s '%s' is not a CIDR", cidr.name, cidr.value), "")
+		}
+		seedCidrs = append(seedCidrs, namedCidr{cidr.name, network})
+	}
+
+	if len(seedCidrs) == 0 {
+		return pass(c.Name(), "the seed CIDRs aren't configured yet, skipped")
+	}
+
+	var overlaps []string
+	for i, a := range seedCidrs {
+		for _, b := range seedCidrs[i+1:] {
+			if overlap(a.network, b.network) {
+				overlaps = append(overlaps, fmt.Sprintf("%s %s overlaps %s %s", a.name, a.network, b.name, b.network))
+			}
+		}
+	}
+
+	for _, shootDefault := range []namedCidr{
+		{"the shoots' default pod CIDR", mustParseCIDR(SHOOT_DEFAULT_POD_CIDR)},
+		{"the shoots' default service CIDR", mustParseCIDR(SHOOT_DEFAULT_SERVICE_CIDR)},
+	} {
+		for _, seedCidr := range seedCidrs {
+			if overlap(seedCidr.network, shootDefault.network) {
+				overlaps = append(overlaps, fmt.Sprintf("%s %s overlaps %s %s", seedCidr.name, seedCidr.network, shootDefault.name, shootDefault.network))
+			}
+		}
+	}
+
+	if len(overlaps) > 0 {
+		return fail(c.Name(), strings.Join(overlaps, "; "),
+			fmt.Sprintf("Shoots use %s for pods and %s for services by default, pick seed CIDRs outside of them and of each other.", SHOOT_DEFAULT_POD_CIDR, SHOOT_DEFAULT_SERVICE_CIDR))
+	}
+
+	return pass(c.Name(), "the seed CIDRs don't overlap with each other or the shootDefaults")
+}
+
+func overlap(a *net.IPNet, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+
+	return network
+}
@@ -0,0 +1,33 @@
+package preflight
+
+import (
+	"context"
+
+	"github.com/23technologies/23kectl/pkg/check"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// Env is the check.Env plus the config the preflight checks depend on.
+type Env struct {
+	check.Env
+	Config Config
+}
+
+// Config holds the parts of the 23kectl config the checks depend on. Checks of
+// empty values are skipped, e.g. before the wizard asked for them.
+type Config struct {
+	SeedNodeCidr    string
+	SeedPodCidr     string
+	SeedServiceCidr string
+}
+
+// NewEnv connects to the cluster selected by the given kubeconfig flags,
+// e.g. --kubeconfig and --context.
+func NewEnv(ctx context.Context, rcg genericclioptions.RESTClientGetter, config Config) (*Env, error) {
+	env, err := check.NewEnv(ctx, rcg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Env{Env: *env, Config: config}, nil
+}
@@ -0,0 +1,66 @@
+package preflight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/23technologies/23kectl/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FluxCheck fails if flux is installed into another namespace than the one
+// 23kectl uses, and warns if flux was installed into it by someone else.
+type FluxCheck struct{}
+
+func (c *FluxCheck) Name() string {
+	return "flux"
+}
+
+func (c *FluxCheck) Run(env *Env) *Result {
+	list := appsv1.DeploymentList{}
+	err := env.Client.List(env.Context, &list, client.MatchingLabels{"app.kubernetes.io/part-of": "flux"})
+	if err != nil {
+		return fail(c.Name(), fmt.Sprintf("couldn't list the flux controllers: %s", err), "")
+	}
+
+	namespaces := map[string]bool{}
+	for _, deployment := range list.Items {
+		namespaces[deployment.Namespace] = true
+	}
+
+	var foreign []string
+	for namespace := range namespaces {
+		if namespace != common.FLUX_NAMESPACE {
+			foreign = append(foreign, namespace)
+		}
+	}
+	sort.Strings(foreign)
+
+	if len(foreign) > 0 {
+		return fail(c.Name(), fmt.Sprintf("flux is installed in namespace %s", strings.Join(foreign, ", ")),
+			fmt.Sprintf("23kectl installs flux into %s, two installations fight over the same CRDs. Uninstall the other one first.", common.FLUX_NAMESPACE))
+	}
+
+	if !namespaces[common.FLUX_NAMESPACE] {
+		return pass(c.Name(), "flux is not installed yet")
+	}
+
+	sec := corev1.Secret{}
+	err = env.Client.Get(env.Context, client.ObjectKey{
+		Namespace: common.FLUX_NAMESPACE,
+		Name:      common.CONFIG_23KE_SECRET_NAME,
+	}, &sec)
+	if err == nil {
+		return pass(c.Name(), "flux was installed by 23kectl")
+	}
+	if !apierrors.IsNotFound(err) {
+		return fail(c.Name(), fmt.Sprintf("couldn't read secret %s: %s", common.CONFIG_23KE_SECRET_NAME, err), "")
+	}
+
+	return warn(c.Name(), fmt.Sprintf("flux is already installed in %s, but not by 23kectl", common.FLUX_NAMESPACE),
+		"23kectl will replace its controllers with the version 23KE is released with. Resources reconciled by this flux installation may be affected.")
+}
@@ -0,0 +1,72 @@
+package preflight
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Allocatable resources the schedulable nodes of the base cluster need in
+// total, roughly what the garden, the initial seed and their monitoring request.
+const (
+	MIN_NODE_CPU    = "8"
+	MIN_NODE_MEMORY = "16Gi"
+)
+
+// NodeCapacityCheck fails if the ready, schedulable nodes don't provide MinCPU
+// and MinMemory in total.
+type NodeCapacityCheck struct {
+	MinCPU    string
+	MinMemory string
+}
+
+func (c *NodeCapacityCheck) Name() string {
+	return "node-capacity"
+}
+
+func (c *NodeCapacityCheck) Run(env *Env) *Result {
+	list := corev1.NodeList{}
+	err := env.Client.List(env.Context, &list)
+	if err != nil {
+		return fail(c.Name(), fmt.Sprintf("couldn't list the nodes: %s", err), "")
+	}
+
+	cpu := resource.Quantity{}
+	memory := resource.Quantity{}
+	nodes := 0
+	for _, node := range list.Items {
+		if node.Spec.Unschedulable || !isNodeReady(&node) {
+			continue
+		}
+
+		cpu.Add(*node.Status.Allocatable.Cpu())
+		memory.Add(*node.Status.Allocatable.Memory())
+		nodes++
+	}
+
+	if nodes == 0 {
+		return fail(c.Name(), "there are no ready, schedulable nodes", "Wait for the nodes of the base cluster to become ready.")
+	}
+
+	minCPU := resource.MustParse(c.MinCPU)
+	minMemory := resource.MustParse(c.MinMemory)
+	capacity := fmt.Sprintf("%d nodes provide %s CPU and %s memory", nodes, cpu.String(), memory.String())
+
+	if cpu.Cmp(minCPU) < 0 || memory.Cmp(minMemory) < 0 {
+		return fail(c.Name(), capacity,
+			fmt.Sprintf("23KE needs at least %s CPU and %s memory, add nodes or use bigger ones.", c.MinCPU, c.MinMemory))
+	}
+
+	return pass(c.Name(), capacity)
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
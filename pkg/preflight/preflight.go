@@ -0,0 +1,210 @@
+// Package preflight checks whether a base cluster is able to run 23KE before
+// anything is installed. Without it, problems like a missing StorageClass only
+// show up as failed HelmReleases once flux reconciles.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+)
+
+// Status is the outcome of a check, ordered by severity.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+func (s Status) severity() int {
+	switch s {
+	case StatusFail:
+		return 2
+	case StatusWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Check is a single preflight check.
+type Check interface {
+	Name() string
+	Run(env *Env) *Result
+}
+
+// ConfigCheck is a check of config values instead of the cluster. ConfigKeys
+// names the values it checks.
+type ConfigCheck interface {
+	Check
+	ConfigKeys() []string
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+func pass(name string, message string) *Result {
+	return &Result{Name: name, Status: StatusPass, Message: message}
+}
+
+func warn(name string, message string, hint string) *Result {
+	return &Result{Name: name, Status: StatusWarn, Message: message, Hint: hint}
+}
+
+func fail(name string, message string, hint string) *Result {
+	return &Result{Name: name, Status: StatusFail, Message: message, Hint: hint}
+}
+
+// checks are run by Install and 23kectl preflight, in this order.
+var checks = []Check{
+	&KubernetesVersionCheck{Min: MIN_KUBERNETES_VERSION, MaxTested: MAX_TESTED_KUBERNETES_VERSION},
+	&StorageClassCheck{},
+	&NodeCapacityCheck{MinCPU: MIN_NODE_CPU, MinMemory: MIN_NODE_MEMORY},
+	&FluxCheck{},
+	&SeedCidrCheck{},
+}
+
+// Register adds a check to the ones returned by Checks.
+func Register(check Check) {
+	checks = append(checks, check)
+}
+
+// Checks returns all registered checks.
+func Checks() []Check {
+	return append([]Check{}, checks...)
+}
+
+// ClusterChecks returns the registered checks which only look at the cluster,
+// so they can run before the wizard asked for the config.
+func ClusterChecks() []Check {
+	var clusterChecks []Check
+	for _, check := range checks {
+		if _, ok := check.(ConfigCheck); !ok {
+			clusterChecks = append(clusterChecks, check)
+		}
+	}
+
+	return clusterChecks
+}
+
+// ConfigChecks returns the registered checks of config values.
+func ConfigChecks() []Check {
+	var configChecks []Check
+	for _, check := range checks {
+		if _, ok := check.(ConfigCheck); ok {
+			configChecks = append(configChecks, check)
+		}
+	}
+
+	return configChecks
+}
+
+// Report holds the results of a preflight run.
+type Report struct {
+	Status  Status    `json:"status"`
+	Results []*Result `json:"results"`
+}
+
+// Run runs the given checks one after another. A check which can't talk to the
+// cluster fails, the others are run anyway.
+func Run(env *Env, checks []Check) *Report {
+	report := &Report{Status: StatusPass}
+
+	for _, check := range checks {
+		result := check.Run(env)
+		result.Name = check.Name()
+
+		if result.Status.severity() > report.Status.severity() {
+			report.Status = result.Status
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report
+}
+
+// Execute connects to the cluster selected by the given kubeconfig flags, runs
+// the checks and writes the report to w in the given output format.
+func Execute(ctx context.Context, rcg genericclioptions.RESTClientGetter, config Config, checks []Check, w io.Writer, output string) (*Report, error) {
+	if output != "" && output != "json" && output != "yaml" {
+		return nil, fmt.Errorf("unknown output format %q, use json or yaml", output)
+	}
+
+	env, err := NewEnv(ctx, rcg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	report := Run(env, checks)
+
+	return report, report.Write(w, output)
+}
+
+// Err returns an error naming the failed checks, nil if none failed.
+func (r *Report) Err() error {
+	var failed []string
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("the base cluster failed the preflight checks: %s", strings.Join(failed, ", "))
+}
+
+// Write writes the report as json or yaml, in a human readable form if output is empty.
+func (r *Report) Write(w io.Writer, output string) error {
+	switch output {
+	case "json":
+		out, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case "yaml":
+		out, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		r.Print(w)
+		return nil
+	}
+}
+
+// Print writes the report in a human readable form.
+func (r *Report) Print(w io.Writer) {
+	for _, result := range r.Results {
+		emoji := "✔️"
+		switch result.Status {
+		case StatusWarn:
+			emoji = "⚠️"
+		case StatusFail:
+			emoji = "❌"
+		}
+
+		fmt.Fprintf(w, "%s %s: %s\n", emoji, result.Name, result.Message)
+		if result.Hint != "" && result.Status != StatusPass {
+			fmt.Fprintf(w, "   hint: %s\n", result.Hint)
+		}
+	}
+}
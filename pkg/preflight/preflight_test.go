@@ -0,0 +1,232 @@
+package preflight_test
+
+import (
+	"bytes"
+
+	"github.com/23technologies/23kectl/pkg/preflight"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name string, cpu string, memory string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func storageClass(name string, isDefault bool) *storagev1.StorageClass {
+	sc := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: name},
+		Provisioner: "csi.example.com",
+	}
+	if isDefault {
+		sc.Annotations = map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}
+	}
+
+	return sc
+}
+
+func fluxController(namespace string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-controller",
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/part-of": "flux"},
+		},
+	}
+}
+
+var _ = Describe("KubernetesVersionCheck", func() {
+	check := &preflight.KubernetesVersionCheck{Min: "1.22", MaxTested: "1.26"}
+
+	It("passes for a supported version", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}))
+		Expect(result.Status).To(Equal(preflight.StatusPass))
+	})
+
+	It("fails for a version which is too old", func() {
+		result := check.Run(newTestEnv("v1.21.14-gke.700", preflight.Config{}))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+		Expect(result.Message).To(ContainSubstring("v1.21.14-gke.700"))
+	})
+
+	It("warns about an untested version", func() {
+		result := check.Run(newTestEnv("v1.27.1", preflight.Config{}))
+		Expect(result.Status).To(Equal(preflight.StatusWarn))
+	})
+})
+
+var _ = Describe("StorageClassCheck", func() {
+	check := &preflight.StorageClassCheck{}
+
+	It("passes if there is a default StorageClass", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}, storageClass("standard", false), storageClass("ssd", true)))
+		Expect(result.Status).To(Equal(preflight.StatusPass))
+		Expect(result.Message).To(ContainSubstring("ssd"))
+	})
+
+	It("warns if none of them is the default", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}, storageClass("standard", false)))
+		Expect(result.Status).To(Equal(preflight.StatusWarn))
+	})
+
+	It("fails if there is none", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+	})
+})
+
+var _ = Describe("NodeCapacityCheck", func() {
+	check := &preflight.NodeCapacityCheck{MinCPU: "8", MinMemory: "16Gi"}
+
+	It("passes if the nodes provide enough resources in total", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{},
+			node("a", "4", "8Gi", corev1.ConditionTrue),
+			node("b", "4", "8Gi", corev1.ConditionTrue)))
+		Expect(result.Status).To(Equal(preflight.StatusPass))
+		Expect(result.Message).To(ContainSubstring("2 nodes"))
+	})
+
+	It("ignores nodes which aren't ready or schedulable", func() {
+		cordoned := node("c", "8", "16Gi", corev1.ConditionTrue)
+		cordoned.Spec.Unschedulable = true
+
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{},
+			node("a", "4", "8Gi", corev1.ConditionTrue),
+			node("b", "4", "8Gi", corev1.ConditionFalse),
+			cordoned))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+		Expect(result.Message).To(ContainSubstring("1 nodes"))
+	})
+
+	It("fails without nodes", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+	})
+})
+
+var _ = Describe("FluxCheck", func() {
+	check := &preflight.FluxCheck{}
+
+	It("passes if flux isn't installed", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}))
+		Expect(result.Status).To(Equal(preflight.StatusPass))
+	})
+
+	It("passes if flux was installed by 23kectl", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{},
+			fluxController("flux-system"),
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "23ke-config", Namespace: "flux-system"}}))
+		Expect(result.Status).To(Equal(preflight.StatusPass))
+	})
+
+	It("warns about a foreign flux installation in flux-system", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}, fluxController("flux-system")))
+		Expect(result.Status).To(Equal(preflight.StatusWarn))
+	})
+
+	It("fails if flux is installed in another namespace", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}, fluxController("gotk")))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+		Expect(result.Message).To(ContainSubstring("gotk"))
+	})
+})
+
+var _ = Describe("SeedCidrCheck", func() {
+	check := &preflight.SeedCidrCheck{}
+
+	It("passes for distinct CIDRs", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{
+			SeedNodeCidr:    "10.250.0.0/16",
+			SeedPodCidr:     "10.244.0.0/16",
+			SeedServiceCidr: "10.96.0.0/12",
+		}))
+		Expect(result.Status).To(Equal(preflight.StatusPass))
+	})
+
+	It("skips CIDRs which aren't configured", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{}))
+		Expect(result.Status).To(Equal(preflight.StatusPass))
+	})
+
+	It("fails if the seed service CIDR overlaps the shootDefaults", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{
+			SeedNodeCidr:    "10.250.0.0/16",
+			SeedPodCidr:     "10.244.0.0/16",
+			SeedServiceCidr: "100.64.0.0/10",
+		}))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+		Expect(result.Message).To(ContainSubstring("gardenlet.seedServiceCidr 100.64.0.0/10 overlaps the shoots' default pod CIDR"))
+		Expect(result.Message).To(ContainSubstring("the shoots' default service CIDR"))
+	})
+
+	It("fails if the seed CIDRs overlap each other", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{
+			SeedPodCidr:     "10.0.0.0/8",
+			SeedServiceCidr: "10.96.0.0/12",
+		}))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+		Expect(result.Message).To(ContainSubstring("gardenlet.seedPodCidr 10.0.0.0/8 overlaps gardenlet.seedServiceCidr"))
+	})
+
+	It("fails for an invalid CIDR", func() {
+		result := check.Run(newTestEnv("v1.25.3", preflight.Config{SeedPodCidr: "10.244.0.0"}))
+		Expect(result.Status).To(Equal(preflight.StatusFail))
+	})
+})
+
+var _ = Describe("Run", func() {
+	It("reports the worst status and names the failed checks", func() {
+		env := newTestEnv("v1.27.1", preflight.Config{}, storageClass("ssd", true))
+
+		report := preflight.Run(env, preflight.Checks())
+
+		Expect(report.Status).To(Equal(preflight.StatusFail))
+		Expect(report.Results).To(HaveLen(5))
+		Expect(report.Results[0].Name).To(Equal("kubernetes-version"))
+		Expect(report.Results[0].Status).To(Equal(preflight.StatusWarn))
+		Expect(report.Err()).To(MatchError(ContainSubstring("node-capacity")))
+
+		out := bytes.Buffer{}
+		report.Print(&out)
+		Expect(out.String()).To(ContainSubstring("❌ node-capacity: there are no ready, schedulable nodes"))
+	})
+
+	It("separates the checks of the cluster from the checks of the config", func() {
+		Expect(preflight.ClusterChecks()).To(HaveLen(4))
+		Expect(preflight.ConfigChecks()).To(ConsistOf(&preflight.SeedCidrCheck{}))
+	})
+
+	It("writes the report as json or yaml", func() {
+		report := preflight.Run(newTestEnv("v1.25.3", preflight.Config{}), []preflight.Check{&preflight.FluxCheck{}})
+
+		out := bytes.Buffer{}
+		Expect(report.Write(&out, "json")).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(`"name": "flux"`))
+
+		out.Reset()
+		Expect(report.Write(&out, "yaml")).To(Succeed())
+		Expect(out.String()).To(HavePrefix("results:\n- message:"))
+	})
+
+	It("passes if all checks pass", func() {
+		env := newTestEnv("v1.25.3", preflight.Config{}, storageClass("ssd", true), node("a", "8", "16Gi", corev1.ConditionTrue))
+
+		report := preflight.Run(env, preflight.Checks())
+
+		Expect(report.Status).To(Equal(preflight.StatusPass))
+		Expect(report.Err()).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,48 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// annotations marking the default StorageClass
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// StorageClassCheck fails if there is no StorageClass and warns if none of them
+// is the default. etcd, prometheus and loki of 23KE need persistent volumes.
+type StorageClassCheck struct{}
+
+func (c *StorageClassCheck) Name() string {
+	return "storage-class"
+}
+
+func (c *StorageClassCheck) Run(env *Env) *Result {
+	list := storagev1.StorageClassList{}
+	err := env.Client.List(env.Context, &list)
+	if err != nil {
+		return fail(c.Name(), fmt.Sprintf("couldn't list the StorageClasses: %s", err), "")
+	}
+
+	if len(list.Items) == 0 {
+		return fail(c.Name(), "there is no StorageClass",
+			"23KE needs persistent volumes, install the CSI driver of your cloud provider.")
+	}
+
+	var names []string
+	for _, storageClass := range list.Items {
+		for _, annotation := range defaultStorageClassAnnotations {
+			if storageClass.Annotations[annotation] == "true" {
+				return pass(c.Name(), fmt.Sprintf("the default StorageClass is %s", storageClass.Name))
+			}
+		}
+		names = append(names, storageClass.Name)
+	}
+
+	return warn(c.Name(), fmt.Sprintf("none of the StorageClasses %s is the default", strings.Join(names, ", ")),
+		fmt.Sprintf("Persistent volumes without a storageClassName stay pending, annotate one of them with %s=true.", defaultStorageClassAnnotations[0]))
+}
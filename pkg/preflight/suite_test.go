@@ -0,0 +1,41 @@
+package preflight_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/23technologies/23kectl/pkg/check"
+	fluxutils "github.com/23technologies/23kectl/pkg/fluxutils"
+	"github.com/23technologies/23kectl/pkg/preflight"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Suite")
+}
+
+// newTestEnv returns an Env backed by fake clients holding the given objects,
+// the server reports the given Kubernetes version.
+func newTestEnv(gitVersion string, config preflight.Config, objects ...client.Object) *preflight.Env {
+	clientGo := kubefake.NewSimpleClientset()
+	clientGo.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: gitVersion}
+
+	return &preflight.Env{
+		Env: check.Env{
+			Context: context.Background(),
+			Client: fake.NewClientBuilder().
+				WithScheme(fluxutils.NewScheme()).
+				WithObjects(objects...).
+				Build(),
+			ClientGo: clientGo,
+		},
+		Config: config,
+	}
+}
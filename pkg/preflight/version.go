@@ -0,0 +1,50 @@
+package preflight
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Kubernetes versions of the base cluster 23KE is released for.
+const (
+	MIN_KUBERNETES_VERSION        = "1.22"
+	MAX_TESTED_KUBERNETES_VERSION = "1.26"
+)
+
+// KubernetesVersionCheck fails if the base cluster is older than Min and warns
+// if its minor version is newer than MaxTested.
+type KubernetesVersionCheck struct {
+	Min       string
+	MaxTested string
+}
+
+func (c *KubernetesVersionCheck) Name() string {
+	return "kubernetes-version"
+}
+
+func (c *KubernetesVersionCheck) Run(env *Env) *Result {
+	info, err := env.ClientGo.Discovery().ServerVersion()
+	if err != nil {
+		return fail(c.Name(), fmt.Sprintf("couldn't read the Kubernetes version: %s", err), "Check that the kubeconfig points to the base cluster.")
+	}
+
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return fail(c.Name(), fmt.Sprintf("couldn't parse the Kubernetes version '%s': %s", info.GitVersion, err), "")
+	}
+
+	min := version.MustParseGeneric(c.Min)
+	if serverVersion.LessThan(min) {
+		return fail(c.Name(), fmt.Sprintf("Kubernetes %s is not supported", info.GitVersion),
+			fmt.Sprintf("Upgrade the base cluster to Kubernetes %s or newer.", c.Min))
+	}
+
+	maxTested := version.MustParseGeneric(c.MaxTested)
+	if serverVersion.Major() > maxTested.Major() || serverVersion.Major() == maxTested.Major() && serverVersion.Minor() > maxTested.Minor() {
+		return warn(c.Name(), fmt.Sprintf("Kubernetes %s has not been tested with 23KE", info.GitVersion),
+			fmt.Sprintf("23KE is tested with Kubernetes %s to %s, newer versions may remove APIs it relies on.", c.Min, c.MaxTested))
+	}
+
+	return pass(c.Name(), fmt.Sprintf("Kubernetes %s is supported", info.GitVersion))
+}
@@ -0,0 +1,78 @@
+// Package redact removes sensitive values, e.g. credentials from the config
+// file, from everything 23kectl prints or logs.
+package redact
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// REDACTED replaces sensitive values.
+const REDACTED = "[redacted]"
+
+// values shorter than this are too likely to appear by chance to be replaced
+const minLength = 4
+
+var mutex sync.RWMutex
+var secrets []string
+
+// Add registers values to be redacted from now on.
+func Add(values ...string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, value := range values {
+		if len(value) < minLength || contains(secrets, value) {
+			continue
+		}
+		secrets = append(secrets, value)
+	}
+
+	// replace the longest values first, in case one contains another
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+}
+
+// String returns s with all registered values replaced.
+func String(s string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, REDACTED)
+	}
+
+	return s
+}
+
+type writer struct {
+	w io.Writer
+}
+
+// Writer returns a writer redacting everything before writing it to w.
+// Values split across two writes aren't caught, which is fine for log lines.
+func Writer(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+func (r *writer) Write(p []byte) (int, error) {
+	_, err := io.WriteString(r.w, String(string(p)))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package redact_test
+
+import (
+	"bytes"
+
+	"github.com/23technologies/23kectl/pkg/redact"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("redact", func() {
+	It("replaces registered values", func() {
+		redact.Add("my-client-secret")
+
+		Expect(redact.String("auth failed with secret my-client-secret")).To(Equal("auth failed with secret [redacted]"))
+	})
+
+	It("replaces longer values first", func() {
+		redact.Add("abcd", "abcdefgh")
+
+		Expect(redact.String("abcdefgh abcd")).To(Equal("[redacted] [redacted]"))
+	})
+
+	It("ignores very short values", func() {
+		redact.Add("", "a", "ab")
+
+		Expect(redact.String("a b ab")).To(Equal("a b ab"))
+	})
+
+	It("redacts what is written", func() {
+		redact.Add("my-secret-key")
+		var buf bytes.Buffer
+
+		n, err := redact.Writer(&buf).Write([]byte("secretkey: my-secret-key\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(len("secretkey: my-secret-key\n")))
+		Expect(buf.String()).To(Equal("secretkey: [redacted]\n"))
+	})
+})
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"sort"
+
+	"github.com/23technologies/23kectl/pkg/check"
+)
+
+// setRootCauses points every result that isn't okay to the failing result at
+// the bottom of its dependency chain, e.g. a Kustomization whose GitRepository
+// can't be fetched.
+func setRootCauses(results []*check.Result) {
+	byKey := make(map[string]*check.Result, len(results))
+	for _, result := range results {
+		byKey[result.Key()] = result
+	}
+
+	for _, result := range results {
+		if result.IsOkay {
+			continue
+		}
+
+		root := findRootCause(result, byKey, map[string]bool{})
+		if root != result {
+			result.RootCause = root.Key()
+		}
+	}
+}
+
+func findRootCause(result *check.Result, byKey map[string]*check.Result, visited map[string]bool) *check.Result {
+	visited[result.Key()] = true
+
+	for _, key := range result.DependsOn {
+		dep, ok := byKey[key]
+		if !ok || dep.IsOkay || visited[key] {
+			continue
+		}
+
+		return findRootCause(dep, byKey, visited)
+	}
+
+	return result
+}
+
+// sortByDependencies orders checks and their results so that every object comes
+// after the objects it depends on.
+func sortByDependencies(checks []check.Check, results []*check.Result) {
+	byKey := make(map[string]*check.Result, len(results))
+	for _, result := range results {
+		byKey[result.Key()] = result
+	}
+
+	depths := make(map[string]int, len(results))
+	for _, result := range results {
+		depth(result, byKey, depths, map[string]bool{})
+	}
+
+	indices := make([]int, len(results))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		return depths[results[indices[i]].Key()] < depths[results[indices[j]].Key()]
+	})
+
+	sortedChecks := make([]check.Check, len(checks))
+	sortedResults := make([]*check.Result, len(results))
+	for i, index := range indices {
+		sortedChecks[i] = checks[index]
+		sortedResults[i] = results[index]
+	}
+
+	copy(checks, sortedChecks)
+	copy(results, sortedResults)
+}
+
+// depth is the length of the longest dependency chain below the result.
+func depth(result *check.Result, byKey map[string]*check.Result, depths map[string]int, visiting map[string]bool) int {
+	key := result.Key()
+	if d, ok := depths[key]; ok {
+		return d
+	}
+
+	// dependency cycles are flux' problem, don't loop forever
+	if visiting[key] {
+		return 0
+	}
+	visiting[key] = true
+
+	d := 0
+	for _, depKey := range result.DependsOn {
+		if dep, ok := byKey[depKey]; ok {
+			if depDepth := depth(dep, byKey, depths, visiting) + 1; depDepth > d {
+				d = depDepth
+			}
+		}
+	}
+
+	depths[key] = d
+
+	return d
+}
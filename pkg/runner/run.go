@@ -1,12 +1,15 @@
 package runner
 
 import (
-	"github.com/23technologies/23kectl/pkg/check"
 	"math/rand"
+	"sync"
 	"time"
+
+	"github.com/23technologies/23kectl/pkg/check"
 )
 
 type Runner struct {
+	env    *check.Env
 	checks []check.Check
 }
 
@@ -14,40 +17,63 @@ func (runner *Runner) AddCheck(checks ...check.Check) {
 	runner.checks = append(runner.checks, checks...)
 }
 
-func (runner *Runner) RunAllOnce() {
-	for _, c := range runner.checks {
-		runner.RunOnce(c)
+// RunAllOnce runs all checks. The checks and their results are ordered by
+// dependencies, failed results point to their root cause.
+func (runner *Runner) RunAllOnce() []*check.Result {
+	results := make([]*check.Result, len(runner.checks))
+
+	for i, c := range runner.checks {
+		results[i] = runner.RunOnce(c)
 	}
+
+	setRootCauses(results)
+	sortByDependencies(runner.checks, results)
+
+	return results
 }
 
 func (runner *Runner) RunAllOnceAsync() chan []*check.Result {
 	ch := make(chan []*check.Result)
 
 	results := make([]*check.Result, len(runner.checks))
-	done := make([]bool, len(runner.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 
 	for i, c := range runner.checks {
-		go func(i int) {
+		wg.Add(1)
+		go func(i int, c check.Check) {
+			defer wg.Done()
+
 			time.Sleep(time.Duration(rand.Intn(5)) * time.Second)
-			results[i] = runner.RunOnce(c)
-			ch <- results
-			done[i] = true
-
-			for _, d := range done {
-				if !d {
-					return
-				}
-			}
-
-			close(ch)
-		}(i)
+			result := runner.RunOnce(c)
+
+			mu.Lock()
+			results[i] = result
+			snapshot := append([]*check.Result(nil), results...)
+			mu.Unlock()
+
+			ch <- snapshot
+		}(i, c)
 	}
 
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+
 	return ch
 }
 
+func (runner *Runner) Checks() []check.Check {
+	return runner.checks
+}
+
 func (runner *Runner) RunOnce(c check.Check) *check.Result {
-	result := c.Run()
+	result := c.Run(runner.env)
+
+	if withHint, ok := c.(check.WithHint); ok && !result.IsOkay {
+		result.Hint = withHint.Hint(result)
+	}
 
 	if result.IsError {
 		if withErrCallback, ok := c.(check.WithOnError); ok {
@@ -58,6 +84,6 @@ func (runner *Runner) RunOnce(c check.Check) *check.Result {
 	return result
 }
 
-func New() *Runner {
-	return &Runner{}
+func New(env *check.Env) *Runner {
+	return &Runner{env: env}
 }
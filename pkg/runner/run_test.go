@@ -0,0 +1,71 @@
+package runner_test
+
+import (
+	"github.com/23technologies/23kectl/pkg/check"
+	"github.com/23technologies/23kectl/pkg/runner"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// staticCheck returns a fixed result
+type staticCheck struct {
+	result check.Result
+}
+
+func (c *staticCheck) GetName() string {
+	return c.result.Name
+}
+
+func (c *staticCheck) Run(env *check.Env) *check.Result {
+	result := c.result
+	return &result
+}
+
+var _ = Describe("Runner", func() {
+	var r *runner.Runner
+
+	gitRepoKey := check.ObjectKey("GitRepository", "flux-system", "23ke-config")
+	configKsKey := check.ObjectKey("Kustomization", "flux-system", "23ke-config")
+
+	BeforeEach(func() {
+		r = runner.New(&check.Env{})
+		r.AddCheck(
+			&staticCheck{check.Result{Kind: "Kustomization", Namespace: "flux-system", Name: "23ke-addons", DependsOn: []string{configKsKey}}},
+			&staticCheck{check.Result{Kind: "Kustomization", Namespace: "flux-system", Name: "23ke-config", DependsOn: []string{gitRepoKey}}},
+			&staticCheck{check.Result{Kind: "GitRepository", Namespace: "flux-system", Name: "23ke-config", IsError: true}},
+			&staticCheck{check.Result{Kind: "Bucket", Namespace: "flux-system", Name: "23ke", IsOkay: true}},
+		)
+	})
+
+	It("orders results by dependencies", func() {
+		results := r.RunAllOnce()
+
+		keys := []string{}
+		for _, result := range results {
+			keys = append(keys, result.Key())
+		}
+
+		Expect(keys).To(Equal([]string{
+			gitRepoKey,
+			check.ObjectKey("Bucket", "flux-system", "23ke"),
+			configKsKey,
+			check.ObjectKey("Kustomization", "flux-system", "23ke-addons"),
+		}))
+	})
+
+	It("keeps checks and results in the same order", func() {
+		results := r.RunAllOnce()
+
+		for i, c := range r.Checks() {
+			Expect(c.GetName()).To(Equal(results[i].Name))
+		}
+	})
+
+	It("points failed results to the failing source", func() {
+		results := r.RunAllOnce()
+
+		Expect(results[0].RootCause).To(BeEmpty())
+		Expect(results[2].RootCause).To(Equal(gitRepoKey))
+		Expect(results[3].RootCause).To(Equal(gitRepoKey))
+	})
+})
@@ -0,0 +1,184 @@
+// Package sops encrypts Kubernetes Secret manifests with SOPS and age, the
+// way flux's kustomize-controller expects them to be encrypted.
+package sops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	gosops "go.mozilla.org/sops/v3"
+	"go.mozilla.org/sops/v3/aes"
+	sopsage "go.mozilla.org/sops/v3/age"
+	"go.mozilla.org/sops/v3/stores/yaml"
+	"go.mozilla.org/sops/v3/version"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// ENCRYPTED_REGEX limits encryption to the payload of Secrets. Everything else,
+// e.g. the name and namespace, stays readable.
+const ENCRYPTED_REGEX = "^(data|stringData)$"
+
+// AGE_KEY_FILE is the key of the age identity in the decryption secret
+// referenced by a flux Kustomization.
+const AGE_KEY_FILE = "age.agekey"
+
+// GenerateAgeKey returns a new age identity and its recipient (public key).
+func GenerateAgeKey() (identity string, recipient string, err error) {
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", "", err
+	}
+
+	return key.String(), key.Recipient().String(), nil
+}
+
+// RecipientOf returns the recipient of the given age identity.
+func RecipientOf(identity string) (string, error) {
+	key, err := age.ParseX25519Identity(strings.TrimSpace(identity))
+	if err != nil {
+		return "", err
+	}
+
+	return key.Recipient().String(), nil
+}
+
+// IsSecret reports whether any document of the given yaml manifest is a Secret.
+func IsSecret(manifest []byte) bool {
+	decoder := goyaml.NewDecoder(bytes.NewReader(manifest))
+
+	for {
+		var doc struct {
+			Kind string `yaml:"kind"`
+		}
+		err := decoder.Decode(&doc)
+		if err != nil {
+			return false
+		}
+		if doc.Kind == "Secret" {
+			return true
+		}
+	}
+}
+
+// IsEncrypted reports whether the given yaml manifest carries sops metadata.
+func IsEncrypted(manifest []byte) bool {
+	store := yaml.Store{}
+	_, err := store.LoadEncryptedFile(manifest)
+	return err == nil
+}
+
+// Encrypt encrypts the data and stringData of the given yaml manifest for the
+// given age recipient.
+func Encrypt(manifest []byte, recipient string) ([]byte, error) {
+	store := yaml.Store{}
+
+	branches, err := store.LoadPlainFile(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	masterKeys, err := sopsage.MasterKeysFromRecipients(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyGroup gosops.KeyGroup
+	for _, masterKey := range masterKeys {
+		keyGroup = append(keyGroup, masterKey)
+	}
+
+	tree := gosops.Tree{
+		Branches: branches,
+		Metadata: gosops.Metadata{
+			KeyGroups:      []gosops.KeyGroup{keyGroup},
+			EncryptedRegex: ENCRYPTED_REGEX,
+			Version:        version.Version,
+		},
+	}
+
+	dataKey, errs := tree.GenerateDataKey()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("couldn't encrypt the data key: %v", errs)
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Encrypt(dataKey, cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	tree.Metadata.LastModified = time.Now().UTC()
+
+	tree.Metadata.MessageAuthenticationCode, err = cipher.Encrypt(mac, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	return store.EmitEncryptedFile(tree)
+}
+
+// Decrypt decrypts the given sops encrypted yaml manifest with the given age
+// identity and verifies its MAC.
+func Decrypt(manifest []byte, identity string) ([]byte, error) {
+	store := yaml.Store{}
+
+	tree, err := store.LoadEncryptedFile(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := decryptDataKey(tree.Metadata, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(dataKey, cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMac, err := cipher.Decrypt(tree.Metadata.MessageAuthenticationCode, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	if fileMac != mac {
+		return nil, errors.New("MAC mismatch, the file has been tampered with")
+	}
+
+	return store.EmitPlainFile(tree.Branches)
+}
+
+// decryptDataKey decrypts the data key with the given identity directly rather
+// than through sops' age key source, which only reads identities from the
+// environment or the user's config directory.
+func decryptDataKey(metadata gosops.Metadata, identity string) ([]byte, error) {
+	identities, err := age.ParseIdentities(strings.NewReader(identity))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range metadata.KeyGroups {
+		for _, key := range group {
+			ageKey, ok := key.(*sopsage.MasterKey)
+			if !ok {
+				continue
+			}
+
+			reader, err := age.Decrypt(armor.NewReader(strings.NewReader(ageKey.EncryptedKey)), identities...)
+			if err != nil {
+				continue
+			}
+
+			return io.ReadAll(reader)
+		}
+	}
+
+	return nil, errors.New("none of the age recipients of the file matches the given identity")
+}
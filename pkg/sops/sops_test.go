@@ -0,0 +1,87 @@
+package sops_test
+
+import (
+	"github.com/23technologies/23kectl/pkg/sops"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
+)
+
+const secretManifest = `apiVersion: v1
+kind: Secret
+metadata:
+  name: identity-values
+  namespace: flux-system
+type: Opaque
+stringData:
+  values.yaml: |
+    staticPasswords:
+    - email: test@example.org
+      hash: my-password-hash
+`
+
+const configMapManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-config
+data:
+  foo: bar
+`
+
+var _ = Describe("sops", func() {
+	var identity, recipient string
+
+	BeforeEach(func() {
+		var err error
+		identity, recipient, err = sops.GenerateAgeKey()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("derives the recipient from the identity", func() {
+		Expect(sops.RecipientOf(identity + "\n")).To(Equal(recipient))
+	})
+
+	It("tells Secrets from other manifests", func() {
+		Expect(sops.IsSecret([]byte(secretManifest))).To(BeTrue())
+		Expect(sops.IsSecret([]byte(configMapManifest))).To(BeFalse())
+		Expect(sops.IsSecret([]byte(configMapManifest + "---\n" + secretManifest))).To(BeTrue())
+	})
+
+	It("encrypts only the payload of Secrets", func() {
+		encrypted, err := sops.Encrypt([]byte(secretManifest), recipient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sops.IsEncrypted(encrypted)).To(BeTrue())
+		Expect(sops.IsEncrypted([]byte(secretManifest))).To(BeFalse())
+
+		Expect(string(encrypted)).NotTo(ContainSubstring("my-password-hash"))
+		Expect(string(encrypted)).To(ContainSubstring(recipient))
+
+		var secret struct {
+			Metadata   map[string]string `json:"metadata"`
+			StringData map[string]string `json:"stringData"`
+		}
+		Expect(yaml.Unmarshal(encrypted, &secret)).To(Succeed())
+		Expect(secret.Metadata).To(HaveKeyWithValue("name", "identity-values"))
+		Expect(secret.StringData["values.yaml"]).To(HavePrefix("ENC[AES256_GCM,"))
+	})
+
+	It("decrypts what it encrypted", func() {
+		encrypted, err := sops.Encrypt([]byte(secretManifest), recipient)
+		Expect(err).NotTo(HaveOccurred())
+
+		decrypted, err := sops.Decrypt(encrypted, identity)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decrypted).To(MatchYAML(secretManifest))
+	})
+
+	It("refuses to decrypt with a different identity", func() {
+		encrypted, err := sops.Encrypt([]byte(secretManifest), recipient)
+		Expect(err).NotTo(HaveOccurred())
+
+		otherIdentity, _, err := sops.GenerateAgeKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = sops.Decrypt(encrypted, otherIdentity)
+		Expect(err).To(HaveOccurred())
+	})
+})